@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"department-eduvault-backend/internal/config"
+	"department-eduvault-backend/internal/db"
+	"department-eduvault-backend/repositories"
+)
+
+// Rebuilds student_statistics and section_statistics from the certificates table.
+//
+//	go run ./cmd/reconcile_stats -dry-run   # report differences only
+//	go run ./cmd/reconcile_stats            # apply
+func main() {
+	dryRun := flag.Bool("dry-run", false, "report differences without writing")
+	flag.Parse()
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+	database, err := db.Connect(cfg.DatabaseURL)
+	if err != nil {
+		log.Fatalf("failed to connect: %v", err)
+	}
+
+	result, err := repositories.NewStatsRepository(database).Reconcile(context.Background(), *dryRun)
+	if err != nil {
+		log.Fatalf("reconcile failed: %v", err)
+	}
+
+	for _, d := range result.Drift {
+		stored := "missing"
+		if d.Stored != nil {
+			stored = formatCounts(*d.Stored)
+		}
+		fmt.Printf("%-20s %-16s stored: %-70s actual: %s\n", d.Table, d.Key, stored, formatCounts(d.Actual))
+	}
+
+	if *dryRun {
+		fmt.Printf("dry run: %d row(s) differ, nothing written\n", len(result.Drift))
+		return
+	}
+	fmt.Printf("%d row(s) differed: %d updated, %d inserted\n", len(result.Drift), result.Updated, result.Inserted)
+}
+
+func formatCounts(c repositories.StatsCounts) string {
+	return fmt.Sprintf("total=%d pending=%d legit=%d not_legit=%d ml_verified=%d",
+		c.TotalUploaded, c.PendingCount, c.LegitCount, c.NotLegitCount, c.MLVerifiedCount)
+}
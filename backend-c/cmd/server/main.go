@@ -45,15 +45,35 @@ func main() {
 
 	logger.Info("database connection established and ping successful")
 
+	// Import jobs run in-process; any left QUEUED or RUNNING were cut off by the last shutdown.
+	if failed, err := repositories.NewImportJobRepository(database).FailInterrupted(ctx); err != nil {
+		logger.Error("failed to mark interrupted import jobs", zap.Error(err))
+	} else if failed > 0 {
+		logger.Warn("marked interrupted import jobs as failed", zap.Int64("count", failed))
+	}
+
 	healthRepo := internalRepository.NewHealthRepository(database)
 	healthService := internalService.NewHealthService(healthRepo)
 
 	dashboardRepo := repositories.NewDashboardRepository(database)
-	dashboardService := services.NewDashboardService(dashboardRepo)
+	dashboardService := services.NewDashboardService(dashboardRepo, repositories.NewLeaderboardRepository(database), cfg.DashboardCacheTTL)
 
 	adminRepo := repositories.NewAdminRepository(database)
 
-	engine := router.New(healthService, dashboardService, adminRepo, database, logger)
+	slaRepo := repositories.NewReviewSLARepository(database)
+	slaService := services.NewReviewSLAService(slaRepo, cfg.DefaultReviewSLAHours)
+	go slaService.RunEscalationSweep(context.Background(), cfg.SLASweepInterval, logger)
+
+	statsRepo := repositories.NewStatsRepository(database)
+	statsService := services.NewStatsService(statsRepo)
+	go statsService.RunDeltaFold(context.Background(), cfg.StatsFoldInterval, logger)
+	go statsService.RunDriftCheck(context.Background(), cfg.StatsDriftCheckInterval, logger)
+
+	idempotencyRepo := repositories.NewIdempotencyRepository(database, cfg.IdempotencyStaleAfter)
+	idempotencyService := services.NewIdempotencyService(idempotencyRepo, cfg.IdempotencyKeyTTL)
+	go idempotencyService.RunExpirySweep(context.Background(), cfg.IdempotencySweepInterval, logger)
+
+	engine := router.New(cfg, healthService, dashboardService, slaService, statsService, adminRepo, database, logger)
 
 	srv := server.New(engine, cfg)
 	if err := srv.Start(); err != nil {
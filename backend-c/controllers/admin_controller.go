@@ -2,18 +2,21 @@ package controllers
 
 import (
 	"net/http"
+	"strconv"
 
 	"department-eduvault-backend/repositories"
+	"department-eduvault-backend/services"
 	"department-eduvault-backend/utils"
 	"github.com/gin-gonic/gin"
 )
 
 type AdminController struct {
-	adminRepo repositories.AdminRepository
+	adminRepo    repositories.AdminRepository
+	statsService services.StatsService
 }
 
-func NewAdminController(adminRepo repositories.AdminRepository) *AdminController {
-	return &AdminController{adminRepo: adminRepo}
+func NewAdminController(adminRepo repositories.AdminRepository, statsService services.StatsService) *AdminController {
+	return &AdminController{adminRepo: adminRepo, statsService: statsService}
 }
 
 // Seed inserts sample data into the certificates table if it is empty.
@@ -27,3 +30,29 @@ func (ac *AdminController) Seed(c *gin.Context) {
 		"message": "seed completed (no-op if data already present)",
 	})
 }
+
+// ReconcileStats handles:
+// POST /admin/stats/reconcile?dry_run=true
+// Rebuilds student_statistics and section_statistics from certificates and returns the rows
+// that differed. With dry_run the differences are reported without writing.
+func (ac *AdminController) ReconcileStats(c *gin.Context) {
+	dryRun := false
+	if raw := c.Query("dry_run"); raw != "" {
+		parsed, err := strconv.ParseBool(raw)
+		if err != nil {
+			_ = c.Error(utils.NewValidationError("dry_run must be a boolean", err))
+			return
+		}
+		dryRun = parsed
+	}
+
+	result, err := ac.statsService.Reconcile(c.Request.Context(), dryRun)
+	if err != nil {
+		_ = c.Error(utils.NewDatabaseError("failed to reconcile statistics", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
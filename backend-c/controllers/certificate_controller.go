@@ -57,42 +57,104 @@ func (cc *CertificateController) UploadCertificates(c *gin.Context) {
 			RegisterNumber: item.RegisterNumber,
 			Section:        item.Section,
 			StudentName:    item.StudentName,
+			Title:          item.Title,
+			Issuer:         item.Issuer,
+			Category:       item.Category,
+			Department:     item.Department,
 			UploadedBy:     item.UploadedBy,
 			UploadedAt:     item.UploadedAt,
 		})
 	}
 
-	if err := cc.service.UploadCertificates(c.Request.Context(), inputs); err != nil {
+	var (
+		result services.UploadResult
+		err    error
+	)
+	if key := strings.TrimSpace(c.GetHeader("Idempotency-Key")); key != "" {
+		if len(key) > 255 {
+			_ = c.Error(utils.NewValidationError("Idempotency-Key must be at most 255 characters", nil))
+			return
+		}
+		idem := services.UploadIdempotency{Key: key, Actor: c.GetString("email")}
+		result, err = cc.service.UploadCertificatesIdempotent(c.Request.Context(), idem, inputs)
+	} else {
+		result, err = cc.service.UploadCertificates(c.Request.Context(), inputs)
+	}
+	if err != nil {
 		_ = c.Error(mapServiceError(err))
 		return
 	}
 
-	c.JSON(http.StatusAccepted, gin.H{"message": "certificates accepted for processing"})
+	if result.Replayed {
+		c.Header("Idempotent-Replayed", "true")
+	}
+	// Items are validated against the student roster one by one; when none is accepted the
+	// whole upload is refused, still listing why each item was rejected.
+	if len(result.CertificateIDs) == 0 {
+		_ = c.Error(utils.NewUnprocessableError("no certificate matched the student roster", nil).WithItems(result.Items))
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":         "certificates accepted for processing",
+		"certificate_ids": result.CertificateIDs,
+		"items":           result.Items,
+	})
 }
 
 // GetPendingReview handles GET /certificates/pending-review
+// Optional query: section, uploaded_by, uploaded_from, uploaded_to, min_score, max_score,
+// sort (oldest|newest), cursor, limit.
 func (cc *CertificateController) GetPendingReview(c *gin.Context) {
 	if !cc.requireRole(c, "faculty") {
 		return
 	}
 
-	limit := 50
+	query := services.PendingReviewQuery{
+		Reviewer:   c.GetString("email"),
+		Section:    c.Query("section"),
+		UploadedBy: c.Query("uploaded_by"),
+		Sort:       c.Query("sort"),
+		Cursor:     c.Query("cursor"),
+		Limit:      50,
+	}
 	if v := c.Query("limit"); v != "" {
 		parsed, err := parsePositiveInt(v)
 		if err != nil {
 			_ = c.Error(utils.NewValidationError("limit must be a positive integer", err))
 			return
 		}
-		limit = parsed
+		query.Limit = parsed
+	}
+
+	var err error
+	if query.UploadedFrom, err = parseTimeQuery(c.Query("uploaded_from"), false); err != nil {
+		_ = c.Error(utils.NewValidationError("uploaded_from must be a date (YYYY-MM-DD) or RFC3339 timestamp", err))
+		return
+	}
+	if query.UploadedTo, err = parseTimeQuery(c.Query("uploaded_to"), true); err != nil {
+		_ = c.Error(utils.NewValidationError("uploaded_to must be a date (YYYY-MM-DD) or RFC3339 timestamp", err))
+		return
+	}
+	if query.MinMLScore, err = parseFloatQuery(c.Query("min_score")); err != nil {
+		_ = c.Error(utils.NewValidationError("min_score must be a number", err))
+		return
+	}
+	if query.MaxMLScore, err = parseFloatQuery(c.Query("max_score")); err != nil {
+		_ = c.Error(utils.NewValidationError("max_score must be a number", err))
+		return
 	}
 
-	certs, err := cc.service.GetPendingFacultyReview(c.Request.Context(), limit)
+	page, err := cc.service.GetPendingFacultyReview(c.Request.Context(), query)
 	if err != nil {
 		_ = c.Error(mapServiceError(err))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"data": certs})
+	resp := gin.H{"data": page.Certificates, "next_cursor": nil}
+	if page.NextCursor != "" {
+		resp["next_cursor"] = page.NextCursor
+	}
+	c.JSON(http.StatusOK, resp)
 }
 
 // SubmitReview handles POST /certificates/review
@@ -111,7 +173,7 @@ func (cc *CertificateController) SubmitReview(c *gin.Context) {
 		return
 	}
 
-	if err := cc.service.SubmitFacultyDecision(c.Request.Context(), req.CertificateID, req.Status, req.IsLegit); err != nil {
+	if err := cc.service.SubmitFacultyDecision(c.Request.Context(), req.CertificateID, reviewCaller(c), req.Status, req.IsLegit); err != nil {
 		_ = c.Error(mapServiceError(err))
 		return
 	}
@@ -119,6 +181,42 @@ func (cc *CertificateController) SubmitReview(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "review recorded"})
 }
 
+// ClaimCertificate handles POST /certificates/:id/claim
+// It grants (or renews) a time-limited review lease for the caller.
+func (cc *CertificateController) ClaimCertificate(c *gin.Context) {
+	if !cc.requireRole(c, "faculty", "hod") {
+		return
+	}
+
+	claim, err := cc.service.ClaimForReview(c.Request.Context(), c.Param("id"), reviewCaller(c))
+	if err != nil {
+		_ = c.Error(mapServiceError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    claim,
+	})
+}
+
+// ReleaseClaim handles DELETE /certificates/:id/claim
+func (cc *CertificateController) ReleaseClaim(c *gin.Context) {
+	if !cc.requireRole(c, "faculty", "hod") {
+		return
+	}
+
+	if err := cc.service.ReleaseReviewClaim(c.Request.Context(), c.Param("id"), c.GetString("email")); err != nil {
+		_ = c.Error(mapServiceError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "claim released",
+	})
+}
+
 // TriggerMockVerification handles:
 // POST /faculty/certificate/verify
 // This endpoint only triggers the existing mock ML verification and returns
@@ -170,6 +268,10 @@ type uploadItem struct {
 	RegisterNumber string    `json:"register_number" binding:"required"`
 	Section        string    `json:"section" binding:"required"`
 	StudentName    string    `json:"student_name" binding:"required"`
+	Title          string    `json:"title"`
+	Issuer         string    `json:"issuer"`
+	Category       string    `json:"category"`
+	Department     string    `json:"department"`
 	UploadedBy     string    `json:"uploaded_by" binding:"required"`
 	UploadedAt     time.Time `json:"uploaded_at"`
 }
@@ -197,6 +299,13 @@ func (r reviewRequest) validate() error {
 	return nil
 }
 
+func reviewCaller(c *gin.Context) services.ReviewCaller {
+	return services.ReviewCaller{
+		Email: c.GetString("email"),
+		Role:  c.GetString("role"),
+	}
+}
+
 func mapServiceError(err error) *utils.AppError {
 	switch {
 	case errors.Is(err, services.ErrInvalidDriveLink):
@@ -207,13 +316,24 @@ func mapServiceError(err error) *utils.AppError {
 		return utils.NewValidationError(err.Error(), err)
 	case errors.Is(err, services.ErrInvalidFacultyState):
 		return utils.NewValidationError(err.Error(), err)
+	case errors.Is(err, services.ErrInvalidCursor):
+		return utils.NewValidationError(err.Error(), err)
+	case errors.Is(err, services.ErrInvalidReviewFilter):
+		return utils.NewValidationError(err.Error(), err)
+	case errors.Is(err, services.ErrIdempotencyKeyReused):
+		return utils.NewUnprocessableError(err.Error(), err)
+	case errors.Is(err, services.ErrIdempotencyInProgress):
+		return utils.NewConflictError(err.Error(), err)
 	case errors.Is(err, services.ErrCertificateArchived):
 		return utils.NewAuthorizationError(err.Error(), err)
+	case errors.Is(err, services.ErrHODReviewRequired):
+		return utils.NewAuthorizationError(err.Error(), err)
 	case errors.Is(err, repositories.ErrCertificateNotFound):
 		return utils.NewNotFoundError(err.Error(), err)
-	case errors.Is(err, repositories.ErrStatsNotFound):
-		// Use 404 when stats not found, implying student/section not found for update
-		return utils.NewNotFoundError("related statistics record not found", err)
+	case errors.Is(err, repositories.ErrCertificateClaimed):
+		return utils.NewConflictError(err.Error(), err)
+	case errors.Is(err, repositories.ErrClaimNotHeld):
+		return utils.NewConflictError(err.Error(), err)
 	default:
 		return utils.NewInternalError("internal server error", err)
 	}
@@ -229,3 +349,33 @@ func parsePositiveInt(val string) (int, error) {
 	}
 	return n, nil
 }
+
+// parseTimeQuery accepts RFC3339 timestamps or plain dates. A plain date used as an
+// exclusive upper bound is moved to the following midnight so the whole day is included.
+func parseTimeQuery(val string, endOfDay bool) (*time.Time, error) {
+	if val == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, val); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse("2006-01-02", val)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
+
+func parseFloatQuery(val string) (*float64, error) {
+	if val == "" {
+		return nil, nil
+	}
+	f, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &f, nil
+}
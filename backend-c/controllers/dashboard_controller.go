@@ -1,6 +1,11 @@
 package controllers
 
 import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"department-eduvault-backend/repositories"
 	"department-eduvault-backend/services"
 	"department-eduvault-backend/utils"
 	"github.com/gin-gonic/gin"
@@ -16,27 +21,152 @@ func NewDashboardController(service services.DashboardService) *DashboardControl
 }
 
 // GetOverview handles GET /dashboard/overview
+// Optional query: term, uploaded_from, uploaded_to, category, uploaded_by, department.
+// Responses carry ETag and Last-Modified; a matching If-None-Match yields 304.
 func (dc *DashboardController) GetOverview(c *gin.Context) {
-	overview, err := dc.service.GetOverview(c.Request.Context())
+	filter, ok := dashboardFilter(c)
+	if !ok {
+		return
+	}
+	overview, validators, err := dc.service.GetOverview(c.Request.Context(), filter)
+	if err != nil {
+		_ = c.Error(mapDashboardError(err, "failed to load dashboard overview"))
+		return
+	}
+	respondCached(c, validators, overview)
+}
+
+// GetSections handles GET /dashboard/sections with the same filters as GetOverview.
+// Responses carry ETag and Last-Modified; a matching If-None-Match yields 304.
+func (dc *DashboardController) GetSections(c *gin.Context) {
+	filter, ok := dashboardFilter(c)
+	if !ok {
+		return
+	}
+	sections, validators, err := dc.service.GetSectionStats(c.Request.Context(), filter)
+	if err != nil {
+		_ = c.Error(mapDashboardError(err, "failed to load section statistics"))
+		return
+	}
+	respondCached(c, validators, sections)
+}
+
+// GetTrends handles GET /dashboard/trends?bucket=week&from=2026-01-01&to=2026-03-31&section=A
+// from is inclusive and to is inclusive of the whole day when given as a plain date. The
+// filters of GetOverview select which certificates' events are counted.
+func (dc *DashboardController) GetTrends(c *gin.Context) {
+	filter, ok := dashboardFilter(c)
+	if !ok {
+		return
+	}
+	from, err := parseTimeQuery(c.Query("from"), false)
+	if err != nil {
+		_ = c.Error(utils.NewValidationError("from must be a date (YYYY-MM-DD) or RFC3339 timestamp", err))
+		return
+	}
+	to, err := parseTimeQuery(c.Query("to"), true)
+	if err != nil {
+		_ = c.Error(utils.NewValidationError("to must be a date (YYYY-MM-DD) or RFC3339 timestamp", err))
+		return
+	}
+
+	trends, err := dc.service.GetTrends(c.Request.Context(), services.TrendQuery{
+		Bucket:  c.Query("bucket"),
+		From:    from,
+		To:      to,
+		Section: c.Query("section"),
+		Filter:  filter,
+	})
 	if err != nil {
-		_ = c.Error(utils.NewDatabaseError("failed to load dashboard overview", err))
+		_ = c.Error(mapDashboardError(err, "failed to load dashboard trends"))
 		return
 	}
 	c.JSON(200, gin.H{
 		"success": true,
-		"data":    overview,
+		"data":    trends,
 	})
 }
 
-// GetSections handles GET /dashboard/sections
-func (dc *DashboardController) GetSections(c *gin.Context) {
-	sections, err := dc.service.GetSectionStats(c.Request.Context())
+// GetFunnel handles GET /dashboard/funnel with the same filters as GetOverview.
+func (dc *DashboardController) GetFunnel(c *gin.Context) {
+	filter, ok := dashboardFilter(c)
+	if !ok {
+		return
+	}
+	funnel, err := dc.service.GetFunnel(c.Request.Context(), filter)
 	if err != nil {
-		_ = c.Error(utils.NewDatabaseError("failed to load section statistics", err))
+		_ = c.Error(mapDashboardError(err, "failed to load pipeline funnel"))
 		return
 	}
 	c.JSON(200, gin.H{
 		"success": true,
-		"data":    sections,
+		"data":    funnel,
+	})
+}
+
+func dashboardFilter(c *gin.Context) (services.DashboardFilter, bool) {
+	filter := services.DashboardFilter{
+		Term:       c.Query("term"),
+		Category:   c.Query("category"),
+		UploadedBy: c.Query("uploaded_by"),
+		Department: c.Query("department"),
+	}
+	var err error
+	if filter.UploadedFrom, err = parseTimeQuery(c.Query("uploaded_from"), false); err != nil {
+		_ = c.Error(utils.NewValidationError("uploaded_from must be a date (YYYY-MM-DD) or RFC3339 timestamp", err))
+		return services.DashboardFilter{}, false
+	}
+	if filter.UploadedTo, err = parseTimeQuery(c.Query("uploaded_to"), true); err != nil {
+		_ = c.Error(utils.NewValidationError("uploaded_to must be a date (YYYY-MM-DD) or RFC3339 timestamp", err))
+		return services.DashboardFilter{}, false
+	}
+	return filter, true
+}
+
+func mapDashboardError(err error, msg string) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidDashboardRange),
+		errors.Is(err, services.ErrInvalidTrendBucket),
+		errors.Is(err, services.ErrInvalidTrendRange),
+		errors.Is(err, services.ErrTrendRangeTooLarge):
+		return utils.NewValidationError(err.Error(), err)
+	case errors.Is(err, repositories.ErrAcademicTermNotFound):
+		return utils.NewNotFoundError(err.Error(), err)
+	default:
+		return utils.NewDatabaseError(msg, err)
+	}
+}
+
+// respondCached writes data with its validators, or 304 Not Modified when the request's
+// If-None-Match (or, without one, If-Modified-Since) shows the client already has it.
+func respondCached(c *gin.Context, validators services.CacheValidators, data interface{}) {
+	c.Header("ETag", validators.ETag)
+	c.Header("Last-Modified", validators.LastModified.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", "no-cache")
+
+	if notModified(c.Request, validators) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    data,
 	})
 }
+
+func notModified(req *http.Request, validators services.CacheValidators) bool {
+	if header := req.Header.Get("If-None-Match"); header != "" {
+		for _, tag := range strings.Split(header, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == validators.ETag {
+				return true
+			}
+		}
+		return false
+	}
+	if header := req.Header.Get("If-Modified-Since"); header != "" {
+		since, err := http.ParseTime(header)
+		return err == nil && !validators.LastModified.After(since)
+	}
+	return false
+}
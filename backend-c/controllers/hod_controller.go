@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"net/http"
+	"time"
 
 	"department-eduvault-backend/services"
 	"department-eduvault-backend/utils"
@@ -109,3 +110,61 @@ func (hc *HodController) ExportCertificatesByStudent(c *gin.Context) {
 	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", content)
 }
 
+// GetFacultyStats handles:
+// GET /hod/faculty-stats?from=2026-01-01&to=2026-03-31
+func (hc *HodController) GetFacultyStats(c *gin.Context) {
+	from, to, ok := parseDateRangeQuery(c)
+	if !ok {
+		return
+	}
+
+	stats, err := hc.service.GetFacultyStats(c.Request.Context(), from, to)
+	if err != nil {
+		_ = c.Error(utils.NewDatabaseError("failed to load faculty statistics", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    stats,
+	})
+}
+
+// ExportFacultyStats handles:
+// GET /hod/export/faculty-stats?from=2026-01-01&to=2026-03-31
+func (hc *HodController) ExportFacultyStats(c *gin.Context) {
+	from, to, ok := parseDateRangeQuery(c)
+	if !ok {
+		return
+	}
+
+	filename, content, err := hc.service.ExportFacultyStats(c.Request.Context(), from, to)
+	if err != nil {
+		_ = c.Error(utils.NewDatabaseError("failed to export faculty statistics", err))
+		return
+	}
+
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", content)
+}
+
+// parseDateRangeQuery reads optional from/to query parameters; to includes its whole day when
+// given as a plain date. It records a validation error and returns ok=false on bad input.
+func parseDateRangeQuery(c *gin.Context) (from, to *time.Time, ok bool) {
+	from, err := parseTimeQuery(c.Query("from"), false)
+	if err != nil {
+		_ = c.Error(utils.NewValidationError("from must be a date (YYYY-MM-DD) or RFC3339 timestamp", err))
+		return nil, nil, false
+	}
+	to, err = parseTimeQuery(c.Query("to"), true)
+	if err != nil {
+		_ = c.Error(utils.NewValidationError("to must be a date (YYYY-MM-DD) or RFC3339 timestamp", err))
+		return nil, nil, false
+	}
+	if from != nil && to != nil && !from.Before(*to) {
+		_ = c.Error(utils.NewValidationError("from must be before to", nil))
+		return nil, nil, false
+	}
+	return from, to, true
+}
@@ -0,0 +1,161 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"department-eduvault-backend/internal/excel"
+	"department-eduvault-backend/repositories"
+	"department-eduvault-backend/services"
+	"department-eduvault-backend/utils"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxImportFileBytes caps spreadsheet uploads at 10 MiB.
+const maxImportFileBytes = 10 << 20
+
+// ImportController handles spreadsheet bulk imports.
+type ImportController struct {
+	service services.ImportService
+}
+
+// NewImportController constructs an ImportController.
+func NewImportController(service services.ImportService) *ImportController {
+	return &ImportController{service: service}
+}
+
+// ImportCertificates handles:
+// POST /certificates/import (multipart form, field "file": .xlsx or .csv)
+func (ic *ImportController) ImportCertificates(c *gin.Context) {
+	filename, data, ok := readImportFile(c)
+	if !ok {
+		return
+	}
+
+	job, err := ic.service.StartCertificateImport(c.Request.Context(), importCaller(c), filename, data)
+	if err != nil {
+		_ = c.Error(mapImportError(err))
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"success": true,
+		"data":    job,
+	})
+}
+
+// GetImportJob handles:
+// GET /certificates/import/:id
+func (ic *ImportController) GetImportJob(c *gin.Context) {
+	jobID, ok := importJobID(c)
+	if !ok {
+		return
+	}
+	job, err := ic.service.GetJob(c.Request.Context(), importCaller(c), jobID)
+	if err != nil {
+		_ = c.Error(mapImportError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    job,
+	})
+}
+
+// DownloadImportErrors handles:
+// GET /certificates/import/:id/errors
+func (ic *ImportController) DownloadImportErrors(c *gin.Context) {
+	jobID, ok := importJobID(c)
+	if !ok {
+		return
+	}
+	filename, content, err := ic.service.ErrorReport(c.Request.Context(), importCaller(c), jobID)
+	if err != nil {
+		_ = c.Error(mapImportError(err))
+		return
+	}
+
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", content)
+}
+
+// DownloadImportTemplate handles:
+// GET /certificates/import/template
+func (ic *ImportController) DownloadImportTemplate(c *gin.Context) {
+	filename, content, err := ic.service.CertificateTemplate()
+	if err != nil {
+		_ = c.Error(utils.NewInternalError("failed to build import template", err))
+		return
+	}
+
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", content)
+}
+
+// readImportFile reads the multipart "file" field, recording a validation error on the
+// context when it is missing, too large or unreadable.
+func readImportFile(c *gin.Context) (string, []byte, bool) {
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		_ = c.Error(utils.NewValidationError("file is required", err))
+		return "", nil, false
+	}
+	if fileHeader.Size > maxImportFileBytes {
+		_ = c.Error(utils.NewValidationError("file must be at most 10 MB", nil))
+		return "", nil, false
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		_ = c.Error(utils.NewValidationError("could not read uploaded file", err))
+		return "", nil, false
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, maxImportFileBytes))
+	if err != nil {
+		_ = c.Error(utils.NewValidationError("could not read uploaded file", err))
+		return "", nil, false
+	}
+	return fileHeader.Filename, data, true
+}
+
+// importJobID returns the :id parameter, recording a not-found error when it is not a UUID,
+// since no import job can have that id.
+func importJobID(c *gin.Context) (string, bool) {
+	jobID := c.Param("id")
+	if _, err := uuid.Parse(jobID); err != nil {
+		_ = c.Error(utils.NewNotFoundError(repositories.ErrImportJobNotFound.Error(), err))
+		return "", false
+	}
+	return jobID, true
+}
+
+func importCaller(c *gin.Context) services.ImportCaller {
+	return services.ImportCaller{
+		Email: c.GetString("email"),
+		Role:  c.GetString("role"),
+	}
+}
+
+func mapImportError(err error) *utils.AppError {
+	switch {
+	case errors.Is(err, excel.ErrUnsupportedImportFormat),
+		errors.Is(err, excel.ErrMissingImportColumns),
+		errors.Is(err, excel.ErrUnreadableImportFile),
+		errors.Is(err, services.ErrImportEmpty),
+		errors.Is(err, services.ErrImportTooLarge):
+		return utils.NewValidationError(err.Error(), err)
+	case errors.Is(err, services.ErrImportJobForbidden):
+		return utils.NewAuthorizationError(err.Error(), err)
+	case errors.Is(err, repositories.ErrImportJobNotFound):
+		return utils.NewNotFoundError(err.Error(), err)
+	default:
+		return utils.NewDatabaseError("failed to process import", err)
+	}
+}
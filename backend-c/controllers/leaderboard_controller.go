@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"department-eduvault-backend/repositories"
+	"department-eduvault-backend/services"
+	"department-eduvault-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// LeaderboardController exposes student leaderboards and the terms and category weights
+// behind them.
+type LeaderboardController struct {
+	service services.LeaderboardService
+}
+
+// NewLeaderboardController constructs a LeaderboardController.
+func NewLeaderboardController(service services.LeaderboardService) *LeaderboardController {
+	return &LeaderboardController{service: service}
+}
+
+// GetLeaderboard handles:
+// GET /dashboard/leaderboard?scope=section&section=A&metric=points&term=2025-26-ODD&top=10
+func (lc *LeaderboardController) GetLeaderboard(c *gin.Context) {
+	query, ok := leaderboardQuery(c)
+	if !ok {
+		return
+	}
+	board, err := lc.service.GetLeaderboard(c.Request.Context(), query)
+	if err != nil {
+		_ = c.Error(mapLeaderboardError(err, "failed to load leaderboard"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    board,
+	})
+}
+
+// ExportLeaderboard handles:
+// GET /dashboard/leaderboard/export with the same parameters as GetLeaderboard
+func (lc *LeaderboardController) ExportLeaderboard(c *gin.Context) {
+	query, ok := leaderboardQuery(c)
+	if !ok {
+		return
+	}
+	filename, content, err := lc.service.ExportLeaderboard(c.Request.Context(), query)
+	if err != nil {
+		_ = c.Error(mapLeaderboardError(err, "failed to export leaderboard"))
+		return
+	}
+
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", content)
+}
+
+// ListTerms handles:
+// GET /dashboard/terms
+func (lc *LeaderboardController) ListTerms(c *gin.Context) {
+	terms, err := lc.service.ListTerms(c.Request.Context())
+	if err != nil {
+		_ = c.Error(utils.NewDatabaseError("failed to load academic terms", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    terms,
+	})
+}
+
+// CreateTerm handles:
+// POST /hod/academic-terms {"name": "2025-26-ODD", "starts_on": "2025-07-01", "ends_on": "2025-11-30"}
+func (lc *LeaderboardController) CreateTerm(c *gin.Context) {
+	var req struct {
+		Name     string `json:"name" binding:"required"`
+		StartsOn string `json:"starts_on" binding:"required"`
+		EndsOn   string `json:"ends_on" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(utils.NewValidationError("invalid request payload", err))
+		return
+	}
+	startsOn, err := time.Parse("2006-01-02", req.StartsOn)
+	if err != nil {
+		_ = c.Error(utils.NewValidationError("starts_on must be a date (YYYY-MM-DD)", err))
+		return
+	}
+	endsOn, err := time.Parse("2006-01-02", req.EndsOn)
+	if err != nil {
+		_ = c.Error(utils.NewValidationError("ends_on must be a date (YYYY-MM-DD)", err))
+		return
+	}
+
+	term, err := lc.service.CreateTerm(c.Request.Context(), services.AcademicTermInput{
+		Name:      req.Name,
+		StartsOn:  startsOn,
+		EndsOn:    endsOn,
+		CreatedBy: c.GetString("email"),
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidAcademicTerm):
+			_ = c.Error(utils.NewValidationError(err.Error(), err))
+		case errors.Is(err, repositories.ErrAcademicTermExists):
+			_ = c.Error(utils.NewConflictError(err.Error(), err))
+		default:
+			_ = c.Error(utils.NewDatabaseError("failed to save academic term", err))
+		}
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    term,
+	})
+}
+
+// ListCategoryPoints handles:
+// GET /hod/category-points
+func (lc *LeaderboardController) ListCategoryPoints(c *gin.Context) {
+	points, err := lc.service.ListCategoryPoints(c.Request.Context())
+	if err != nil {
+		_ = c.Error(utils.NewDatabaseError("failed to load category points", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    points,
+	})
+}
+
+// SetCategoryPoints handles:
+// PUT /hod/category-points {"category": "HACKATHON", "points": 3}
+func (lc *LeaderboardController) SetCategoryPoints(c *gin.Context) {
+	var req struct {
+		Category string   `json:"category" binding:"required"`
+		Points   *float64 `json:"points" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(utils.NewValidationError("invalid request payload", err))
+		return
+	}
+
+	points, err := lc.service.SetCategoryPoints(c.Request.Context(), req.Category, *req.Points, c.GetString("email"))
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidCategoryPoints) {
+			_ = c.Error(utils.NewValidationError(err.Error(), err))
+			return
+		}
+		_ = c.Error(utils.NewDatabaseError("failed to save category points", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    points,
+	})
+}
+
+func leaderboardQuery(c *gin.Context) (services.LeaderboardQuery, bool) {
+	query := services.LeaderboardQuery{
+		Scope:    c.Query("scope"),
+		Section:  c.Query("section"),
+		Metric:   c.Query("metric"),
+		Category: c.Query("category"),
+		Term:     c.Query("term"),
+	}
+	if raw := c.Query("top"); raw != "" {
+		top, err := strconv.Atoi(raw)
+		if err != nil {
+			_ = c.Error(utils.NewValidationError("top must be an integer", err))
+			return services.LeaderboardQuery{}, false
+		}
+		query.Top = top
+	}
+	return query, true
+}
+
+func mapLeaderboardError(err error, msg string) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidLeaderboardScope),
+		errors.Is(err, services.ErrInvalidLeaderboardMetric),
+		errors.Is(err, services.ErrLeaderboardCategory),
+		errors.Is(err, services.ErrInvalidLeaderboardLimit):
+		return utils.NewValidationError(err.Error(), err)
+	case errors.Is(err, repositories.ErrAcademicTermNotFound):
+		return utils.NewNotFoundError(err.Error(), err)
+	default:
+		return utils.NewDatabaseError(msg, err)
+	}
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"department-eduvault-backend/repositories"
+	"department-eduvault-backend/services"
+	"department-eduvault-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// MLPolicyController lets the HOD view and version the ML auto-decision policy.
+type MLPolicyController struct {
+	service services.MLPolicyService
+}
+
+// NewMLPolicyController constructs an MLPolicyController.
+func NewMLPolicyController(service services.MLPolicyService) *MLPolicyController {
+	return &MLPolicyController{service: service}
+}
+
+// GetActivePolicy handles:
+// GET /hod/ml-policy
+func (mc *MLPolicyController) GetActivePolicy(c *gin.Context) {
+	policy, err := mc.service.GetActive(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, repositories.ErrMLPolicyNotFound) {
+			_ = c.Error(utils.NewNotFoundError(err.Error(), err))
+			return
+		}
+		_ = c.Error(utils.NewDatabaseError("failed to load ml decision policy", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    policy,
+	})
+}
+
+// ListPolicyVersions handles:
+// GET /hod/ml-policy/versions
+func (mc *MLPolicyController) ListPolicyVersions(c *gin.Context) {
+	policies, err := mc.service.ListVersions(c.Request.Context())
+	if err != nil {
+		_ = c.Error(utils.NewDatabaseError("failed to load ml decision policies", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    policies,
+	})
+}
+
+// CreatePolicyVersion handles:
+// POST /hod/ml-policy {"auto_approve_threshold": 98, "hod_review_threshold": 40, "enabled": true}
+func (mc *MLPolicyController) CreatePolicyVersion(c *gin.Context) {
+	var req struct {
+		AutoApproveThreshold *float64 `json:"auto_approve_threshold" binding:"required"`
+		HodReviewThreshold   *float64 `json:"hod_review_threshold" binding:"required"`
+		Enabled              *bool    `json:"enabled"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(utils.NewValidationError("invalid request payload", err))
+		return
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	policy, err := mc.service.CreateVersion(c.Request.Context(), services.MLPolicyInput{
+		AutoApproveThreshold: *req.AutoApproveThreshold,
+		HodReviewThreshold:   *req.HodReviewThreshold,
+		Enabled:              enabled,
+		CreatedBy:            c.GetString("email"),
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidMLPolicy) {
+			_ = c.Error(utils.NewValidationError(err.Error(), err))
+			return
+		}
+		_ = c.Error(utils.NewDatabaseError("failed to save ml decision policy", err))
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    policy,
+	})
+}
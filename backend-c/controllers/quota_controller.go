@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"department-eduvault-backend/repositories"
+	"department-eduvault-backend/services"
+	"department-eduvault-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// QuotaController exposes certificate quota rules and the compliance report to the HOD.
+type QuotaController struct {
+	service services.QuotaService
+}
+
+// NewQuotaController constructs a QuotaController.
+func NewQuotaController(service services.QuotaService) *QuotaController {
+	return &QuotaController{service: service}
+}
+
+// ListRules handles:
+// GET /hod/quota-rules?term=2025-26-ODD
+func (qc *QuotaController) ListRules(c *gin.Context) {
+	rules, err := qc.service.ListRules(c.Request.Context(), c.Query("term"))
+	if err != nil {
+		_ = c.Error(mapQuotaError(err, "failed to load quota rules"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rules,
+	})
+}
+
+// SetRule handles:
+// PUT /hod/quota-rules {"term": "2025-26-ODD", "batch": "2022", "section": "", "category": "", "min_legit": 3}
+func (qc *QuotaController) SetRule(c *gin.Context) {
+	var req struct {
+		Term     string `json:"term" binding:"required"`
+		Batch    string `json:"batch"`
+		Section  string `json:"section"`
+		Category string `json:"category"`
+		MinLegit int    `json:"min_legit" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(utils.NewValidationError("invalid request payload", err))
+		return
+	}
+
+	rule, err := qc.service.SetRule(c.Request.Context(), services.QuotaRuleInput{
+		Term:      req.Term,
+		Batch:     req.Batch,
+		Section:   req.Section,
+		Category:  req.Category,
+		MinLegit:  req.MinLegit,
+		UpdatedBy: c.GetString("email"),
+	})
+	if err != nil {
+		_ = c.Error(mapQuotaError(err, "failed to save quota rule"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rule,
+	})
+}
+
+// DeleteRule handles:
+// DELETE /hod/quota-rules/:id
+func (qc *QuotaController) DeleteRule(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(utils.NewValidationError("id must be an integer", err))
+		return
+	}
+	if err := qc.service.DeleteRule(c.Request.Context(), id); err != nil {
+		_ = c.Error(mapQuotaError(err, "failed to delete quota rule"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "quota rule deleted",
+	})
+}
+
+// GetCompliance handles:
+// GET /hod/compliance?term=2025-26-ODD&section=A&batch=2022&behind=true
+func (qc *QuotaController) GetCompliance(c *gin.Context) {
+	query, ok := complianceQuery(c)
+	if !ok {
+		return
+	}
+	report, err := qc.service.GetCompliance(c.Request.Context(), query)
+	if err != nil {
+		_ = c.Error(mapQuotaError(err, "failed to load compliance report"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// ExportCompliance handles:
+// GET /hod/export/compliance with the same parameters as GetCompliance
+func (qc *QuotaController) ExportCompliance(c *gin.Context) {
+	query, ok := complianceQuery(c)
+	if !ok {
+		return
+	}
+	filename, content, err := qc.service.ExportCompliance(c.Request.Context(), query)
+	if err != nil {
+		_ = c.Error(mapQuotaError(err, "failed to export compliance report"))
+		return
+	}
+
+	c.Header("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+	c.Header("Content-Disposition", "attachment; filename=\""+filename+"\"")
+	c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet", content)
+}
+
+func complianceQuery(c *gin.Context) (services.ComplianceQuery, bool) {
+	query := services.ComplianceQuery{
+		Term:    c.Query("term"),
+		Section: c.Query("section"),
+		Batch:   c.Query("batch"),
+	}
+	if raw := c.Query("behind"); raw != "" {
+		behind, err := strconv.ParseBool(raw)
+		if err != nil {
+			_ = c.Error(utils.NewValidationError("behind must be true or false", err))
+			return services.ComplianceQuery{}, false
+		}
+		query.BehindOnly = behind
+	}
+	return query, true
+}
+
+func mapQuotaError(err error, msg string) error {
+	switch {
+	case errors.Is(err, services.ErrComplianceTermRequired),
+		errors.Is(err, services.ErrInvalidQuotaRule):
+		return utils.NewValidationError(err.Error(), err)
+	case errors.Is(err, repositories.ErrAcademicTermNotFound),
+		errors.Is(err, repositories.ErrQuotaRuleNotFound):
+		return utils.NewNotFoundError(err.Error(), err)
+	default:
+		return utils.NewDatabaseError(msg, err)
+	}
+}
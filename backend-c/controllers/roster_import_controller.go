@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"department-eduvault-backend/internal/excel"
+	"department-eduvault-backend/repositories"
+	"department-eduvault-backend/services"
+	"department-eduvault-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// RosterImportController handles roster spreadsheet imports with a preview step.
+type RosterImportController struct {
+	service services.RosterImportService
+}
+
+// NewRosterImportController constructs a RosterImportController.
+func NewRosterImportController(service services.RosterImportService) *RosterImportController {
+	return &RosterImportController{service: service}
+}
+
+// PreviewImport handles:
+// POST /students/imports (multipart form, field "file": .xlsx or .csv)
+// The diff is stored for review; the roster is unchanged until the import is confirmed.
+func (rc *RosterImportController) PreviewImport(c *gin.Context) {
+	filename, data, ok := readImportFile(c)
+	if !ok {
+		return
+	}
+
+	preview, err := rc.service.Preview(c.Request.Context(), studentCaller(c), filename, data)
+	if err != nil {
+		_ = c.Error(mapRosterImportError(err, "failed to preview roster import"))
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    preview,
+	})
+}
+
+// ListImports handles:
+// GET /students/imports
+func (rc *RosterImportController) ListImports(c *gin.Context) {
+	imports, err := rc.service.List(c.Request.Context())
+	if err != nil {
+		_ = c.Error(mapRosterImportError(err, "failed to load roster imports"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    imports,
+	})
+}
+
+// GetImport handles:
+// GET /students/imports/:id
+func (rc *RosterImportController) GetImport(c *gin.Context) {
+	rosterImport, err := rc.service.Get(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		_ = c.Error(mapRosterImportError(err, "failed to load roster import"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    rosterImport,
+	})
+}
+
+// ConfirmImport handles:
+// POST /students/imports/:id/confirm {"remove_missing": true}
+// The body is optional; missing students are kept unless remove_missing is true.
+func (rc *RosterImportController) ConfirmImport(c *gin.Context) {
+	var req struct {
+		RemoveMissing bool `json:"remove_missing"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		_ = c.Error(utils.NewValidationError("invalid request payload", err))
+		return
+	}
+
+	applied, err := rc.service.Confirm(c.Request.Context(), studentCaller(c), c.Param("id"), req.RemoveMissing)
+	if err != nil {
+		_ = c.Error(mapRosterImportError(err, "failed to apply roster import"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    applied,
+	})
+}
+
+func mapRosterImportError(err error, msg string) error {
+	switch {
+	case errors.Is(err, excel.ErrUnsupportedImportFormat),
+		errors.Is(err, excel.ErrMissingImportColumns),
+		errors.Is(err, excel.ErrUnreadableImportFile),
+		errors.Is(err, services.ErrImportEmpty),
+		errors.Is(err, services.ErrImportTooLarge):
+		return utils.NewValidationError(err.Error(), err)
+	case errors.Is(err, services.ErrRosterImportInvalid):
+		return utils.NewUnprocessableError(err.Error(), err)
+	case errors.Is(err, repositories.ErrRosterImportNotFound):
+		return utils.NewNotFoundError(err.Error(), err)
+	case errors.Is(err, repositories.ErrRosterImportApplied),
+		errors.Is(err, repositories.ErrRosterChanged):
+		return utils.NewConflictError(err.Error(), err)
+	default:
+		return utils.NewDatabaseError(msg, err)
+	}
+}
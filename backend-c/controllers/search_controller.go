@@ -0,0 +1,53 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"department-eduvault-backend/services"
+	"department-eduvault-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// SearchController exposes cross-entity search.
+type SearchController struct {
+	service services.SearchService
+}
+
+// NewSearchController constructs a SearchController.
+func NewSearchController(service services.SearchService) *SearchController {
+	return &SearchController{service: service}
+}
+
+// Search handles:
+// GET /search?q=QUERY&limit=20
+func (sc *SearchController) Search(c *gin.Context) {
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		parsed, err := parsePositiveInt(v)
+		if err != nil {
+			_ = c.Error(utils.NewValidationError("limit must be a positive integer", err))
+			return
+		}
+		limit = parsed
+	}
+
+	caller := services.SearchCaller{
+		Email: c.GetString("email"),
+		Role:  c.GetString("role"),
+	}
+	results, err := sc.service.Search(c.Request.Context(), caller, c.Query("q"), limit)
+	if err != nil {
+		if errors.Is(err, services.ErrSearchQueryTooShort) {
+			_ = c.Error(utils.NewValidationError(err.Error(), err))
+			return
+		}
+		_ = c.Error(utils.NewDatabaseError("failed to run search", err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    results,
+	})
+}
@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"department-eduvault-backend/repositories"
+	"department-eduvault-backend/services"
+	"department-eduvault-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// ReviewSLAController exposes review SLA configuration, ageing and escalations to the HOD.
+type ReviewSLAController struct {
+	service services.ReviewSLAService
+}
+
+// NewReviewSLAController constructs a ReviewSLAController.
+func NewReviewSLAController(service services.ReviewSLAService) *ReviewSLAController {
+	return &ReviewSLAController{service: service}
+}
+
+// ListPolicies handles:
+// GET /hod/sla-policies
+func (sc *ReviewSLAController) ListPolicies(c *gin.Context) {
+	policies, err := sc.service.ListPolicies(c.Request.Context())
+	if err != nil {
+		_ = c.Error(utils.NewDatabaseError("failed to load sla policies", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    policies,
+	})
+}
+
+// SetPolicy handles:
+// PUT /hod/sla-policies {"section": "A", "category": "", "threshold_hours": 48}
+func (sc *ReviewSLAController) SetPolicy(c *gin.Context) {
+	var req struct {
+		Section        string `json:"section"`
+		Category       string `json:"category"`
+		ThresholdHours int    `json:"threshold_hours" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(utils.NewValidationError("invalid request payload", err))
+		return
+	}
+
+	policy, err := sc.service.SetPolicy(c.Request.Context(), services.SLAPolicyInput{
+		Section:        req.Section,
+		Category:       req.Category,
+		ThresholdHours: req.ThresholdHours,
+		UpdatedBy:      c.GetString("email"),
+	})
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidSLAThreshold) {
+			_ = c.Error(utils.NewValidationError(err.Error(), err))
+			return
+		}
+		_ = c.Error(utils.NewDatabaseError("failed to save sla policy", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    policy,
+	})
+}
+
+// DeletePolicy handles:
+// DELETE /hod/sla-policies/:id
+func (sc *ReviewSLAController) DeletePolicy(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		_ = c.Error(utils.NewValidationError("id must be an integer", err))
+		return
+	}
+	if err := sc.service.DeletePolicy(c.Request.Context(), id); err != nil {
+		if errors.Is(err, repositories.ErrSLAPolicyNotFound) {
+			_ = c.Error(utils.NewNotFoundError(err.Error(), err))
+			return
+		}
+		_ = c.Error(utils.NewDatabaseError("failed to delete sla policy", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "sla policy deleted",
+	})
+}
+
+// GetAgeingReport handles:
+// GET /hod/review-ageing
+func (sc *ReviewSLAController) GetAgeingReport(c *gin.Context) {
+	report, err := sc.service.GetAgeingReport(c.Request.Context())
+	if err != nil {
+		_ = c.Error(utils.NewDatabaseError("failed to load review ageing report", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    report,
+	})
+}
+
+// ListEscalations handles:
+// GET /hod/escalations
+func (sc *ReviewSLAController) ListEscalations(c *gin.Context) {
+	certs, err := sc.service.ListEscalated(c.Request.Context())
+	if err != nil {
+		_ = c.Error(utils.NewDatabaseError("failed to load escalated certificates", err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    certs,
+	})
+}
@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"department-eduvault-backend/repositories"
+	"department-eduvault-backend/services"
+	"department-eduvault-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// StudentController exposes the student roster and per-student views.
+type StudentController struct {
+	service services.StudentService
+}
+
+// NewStudentController constructs a StudentController.
+func NewStudentController(service services.StudentService) *StudentController {
+	return &StudentController{service: service}
+}
+
+// ListStudents handles:
+// GET /students?section=A&batch=2022&faculty_email=faculty1@citchennai.net&present=true
+// Faculty only see the students assigned to them.
+func (sc *StudentController) ListStudents(c *gin.Context) {
+	filter := repositories.StudentListFilter{
+		Section:      c.Query("section"),
+		Batch:        c.Query("batch"),
+		FacultyEmail: c.Query("faculty_email"),
+	}
+	if raw := c.Query("present"); raw != "" {
+		present, err := strconv.ParseBool(raw)
+		if err != nil {
+			_ = c.Error(utils.NewValidationError("present must be true or false", err))
+			return
+		}
+		filter.Present = &present
+	}
+
+	students, err := sc.service.List(c.Request.Context(), studentCaller(c), filter)
+	if err != nil {
+		_ = c.Error(mapStudentError(err, "failed to load students"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    students,
+	})
+}
+
+// CreateStudent handles:
+// POST /students {"register_number": "22CSE101", "name": "Arun", "section": "A", "batch": "2022", "faculty_email": "faculty1@citchennai.net"}
+func (sc *StudentController) CreateStudent(c *gin.Context) {
+	var req struct {
+		RegisterNumber string `json:"register_number" binding:"required"`
+		Name           string `json:"name" binding:"required"`
+		Email          string `json:"email"`
+		Section        string `json:"section" binding:"required"`
+		Semester       *int   `json:"semester"`
+		Batch          string `json:"batch"`
+		IsPresent      *bool  `json:"is_present"`
+		FacultyEmail   string `json:"faculty_email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(utils.NewValidationError("invalid request payload", err))
+		return
+	}
+
+	student, err := sc.service.Create(c.Request.Context(), studentCaller(c), services.StudentInput{
+		RegisterNumber: req.RegisterNumber,
+		Name:           req.Name,
+		Email:          req.Email,
+		Section:        req.Section,
+		Semester:       req.Semester,
+		Batch:          req.Batch,
+		IsPresent:      req.IsPresent,
+		FacultyEmail:   req.FacultyEmail,
+	})
+	if err != nil {
+		_ = c.Error(mapStudentError(err, "failed to create student"))
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		"success": true,
+		"data":    student,
+	})
+}
+
+// UpdateStudent handles:
+// PATCH /students/:reg_no {"name": "Arun K", "is_present": false}
+// Omitted fields are left unchanged; sections change through MoveSection.
+func (sc *StudentController) UpdateStudent(c *gin.Context) {
+	var req struct {
+		Name         *string `json:"name"`
+		Email        *string `json:"email"`
+		Semester     *int    `json:"semester"`
+		Batch        *string `json:"batch"`
+		IsPresent    *bool   `json:"is_present"`
+		FacultyEmail *string `json:"faculty_email"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(utils.NewValidationError("invalid request payload", err))
+		return
+	}
+
+	student, err := sc.service.Update(c.Request.Context(), studentCaller(c), c.Param("reg_no"), services.StudentUpdate{
+		Name:         req.Name,
+		Email:        req.Email,
+		Semester:     req.Semester,
+		Batch:        req.Batch,
+		IsPresent:    req.IsPresent,
+		FacultyEmail: req.FacultyEmail,
+	})
+	if err != nil {
+		_ = c.Error(mapStudentError(err, "failed to update student"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    student,
+	})
+}
+
+// DeleteStudent handles:
+// DELETE /students/:reg_no
+// Students are soft-deleted; re-creating the register number restores them.
+func (sc *StudentController) DeleteStudent(c *gin.Context) {
+	if err := sc.service.Delete(c.Request.Context(), studentCaller(c), c.Param("reg_no")); err != nil {
+		_ = c.Error(mapStudentError(err, "failed to delete student"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"message": "student deleted",
+	})
+}
+
+// MoveSection handles:
+// POST /students/:reg_no/move-section {"section": "B"}
+func (sc *StudentController) MoveSection(c *gin.Context) {
+	var req struct {
+		Section string `json:"section" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		_ = c.Error(utils.NewValidationError("invalid request payload", err))
+		return
+	}
+
+	student, err := sc.service.MoveSection(c.Request.Context(), studentCaller(c), c.Param("reg_no"), req.Section)
+	if err != nil {
+		_ = c.Error(mapStudentError(err, "failed to move student"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    student,
+	})
+}
+
+// GetSummary handles:
+// GET /students/:reg_no/summary
+// HODs may read any student, faculty their assigned students and students themselves.
+func (sc *StudentController) GetSummary(c *gin.Context) {
+	summary, err := sc.service.GetSummary(c.Request.Context(), studentCaller(c), c.Param("reg_no"))
+	if err != nil {
+		_ = c.Error(mapStudentError(err, "failed to load student summary"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    summary,
+	})
+}
+
+// ListEnrollments handles:
+// GET /students/:reg_no/enrollments
+// The student's section and semester history, visible to the same callers as the summary.
+func (sc *StudentController) ListEnrollments(c *gin.Context) {
+	enrollments, err := sc.service.ListEnrollments(c.Request.Context(), studentCaller(c), c.Param("reg_no"))
+	if err != nil {
+		_ = c.Error(mapStudentError(err, "failed to load student enrollments"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    enrollments,
+	})
+}
+
+// GetSectionRoster handles:
+// GET /sections/:section/roster
+// HODs see the whole section; faculty see the students assigned to them.
+func (sc *StudentController) GetSectionRoster(c *gin.Context) {
+	roster, err := sc.service.GetSectionRoster(c.Request.Context(), studentCaller(c), c.Param("section"))
+	if err != nil {
+		_ = c.Error(mapStudentError(err, "failed to load section roster"))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    roster,
+	})
+}
+
+func studentCaller(c *gin.Context) services.StudentCaller {
+	return services.StudentCaller{
+		Email: c.GetString("email"),
+		Role:  c.GetString("role"),
+	}
+}
+
+func mapStudentError(err error, msg string) error {
+	switch {
+	case errors.Is(err, services.ErrInvalidStudent):
+		return utils.NewValidationError(err.Error(), err)
+	case errors.Is(err, repositories.ErrStudentNotFound),
+		errors.Is(err, services.ErrSectionRosterNotFound):
+		return utils.NewNotFoundError(err.Error(), err)
+	case errors.Is(err, repositories.ErrStudentExists):
+		return utils.NewConflictError(err.Error(), err)
+	case errors.Is(err, services.ErrStudentAccessDenied),
+		errors.Is(err, services.ErrStudentManageDenied):
+		return utils.NewAuthorizationError(err.Error(), err)
+	default:
+		return utils.NewDatabaseError(msg, err)
+	}
+}
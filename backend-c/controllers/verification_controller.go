@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"department-eduvault-backend/repositories"
+	"department-eduvault-backend/services"
+	"department-eduvault-backend/utils"
+	"github.com/gin-gonic/gin"
+)
+
+// maxVerificationFileBytes caps certificate files uploaded for verification at 20 MiB.
+const maxVerificationFileBytes = 20 << 20
+
+// VerificationController runs automated checks on certificate files.
+type VerificationController struct {
+	service services.VerificationService
+}
+
+// NewVerificationController constructs a VerificationController.
+func NewVerificationController(service services.VerificationService) *VerificationController {
+	return &VerificationController{service: service}
+}
+
+// VerifyText handles:
+// POST /certificates/:id/text-verification (optional multipart field "file": the PDF)
+// Without a file the PDF is downloaded from the certificate's drive link.
+func (vc *VerificationController) VerifyText(c *gin.Context) {
+	file, ok := readOptionalUpload(c, "file", maxVerificationFileBytes)
+	if !ok {
+		return
+	}
+
+	signal, err := vc.service.VerifyText(c.Request.Context(), c.Param("id"), c.GetString("email"), file)
+	if err != nil {
+		_ = c.Error(mapVerificationError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    signal,
+	})
+}
+
+// HashImage handles:
+// POST /certificates/:id/image-hash (optional multipart field "file": image or PDF)
+// Without a file the certificate is downloaded from its drive link.
+func (vc *VerificationController) HashImage(c *gin.Context) {
+	file, ok := readOptionalUpload(c, "file", maxVerificationFileBytes)
+	if !ok {
+		return
+	}
+
+	result, err := vc.service.HashImage(c.Request.Context(), c.Param("id"), c.GetString("email"), file)
+	if err != nil {
+		_ = c.Error(mapVerificationError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// ListNearDuplicates handles:
+// GET /hod/near-duplicates?limit=100
+func (vc *VerificationController) ListNearDuplicates(c *gin.Context) {
+	limit := 100
+	if v := c.Query("limit"); v != "" {
+		parsed, err := parsePositiveInt(v)
+		if err != nil {
+			_ = c.Error(utils.NewValidationError("limit must be a positive integer", err))
+			return
+		}
+		limit = parsed
+	}
+
+	pairs, err := vc.service.ListNearDuplicates(c.Request.Context(), limit)
+	if err != nil {
+		_ = c.Error(mapVerificationError(err))
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"success": true,
+		"data":    pairs,
+	})
+}
+
+// readOptionalUpload returns the named multipart file, or nil when the request has none.
+// It reports false after recording an error on the context.
+func readOptionalUpload(c *gin.Context, field string, maxBytes int64) ([]byte, bool) {
+	fileHeader, err := c.FormFile(field)
+	if err != nil {
+		if errors.Is(err, http.ErrMissingFile) || errors.Is(err, http.ErrNotMultipart) {
+			return nil, true
+		}
+		_ = c.Error(utils.NewValidationError("could not read uploaded file", err))
+		return nil, false
+	}
+	if fileHeader.Size > maxBytes {
+		_ = c.Error(utils.NewValidationError("file is too large", nil))
+		return nil, false
+	}
+
+	f, err := fileHeader.Open()
+	if err != nil {
+		_ = c.Error(utils.NewValidationError("could not read uploaded file", err))
+		return nil, false
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(io.LimitReader(f, maxBytes))
+	if err != nil {
+		_ = c.Error(utils.NewValidationError("could not read uploaded file", err))
+		return nil, false
+	}
+	return data, true
+}
+
+func mapVerificationError(err error) *utils.AppError {
+	switch {
+	case errors.Is(err, services.ErrUnsupportedDocument),
+		errors.Is(err, services.ErrUnreadableDocument),
+		errors.Is(err, services.ErrDocumentUnavailable),
+		errors.Is(err, services.ErrNoCertificateImage),
+		errors.Is(err, services.ErrCertificateImageTooLarge):
+		return utils.NewUnprocessableError(err.Error(), err)
+	case errors.Is(err, services.ErrDocumentFetchFailed):
+		return utils.NewUpstreamError(err.Error(), err)
+	case errors.Is(err, repositories.ErrCertificateNotFound):
+		return utils.NewNotFoundError(err.Error(), err)
+	default:
+		return utils.NewDatabaseError("failed to verify certificate", err)
+	}
+}
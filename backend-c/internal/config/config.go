@@ -3,6 +3,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +16,30 @@ type Config struct {
 	Port               string
 	DatabaseURL        string
 	AllowedEmailDomain string
+	// ReviewLeaseDuration is how long a certificate claim lasts before it must be renewed.
+	ReviewLeaseDuration time.Duration
+	// DefaultReviewSLAHours applies to certificates not covered by a stored SLA policy.
+	DefaultReviewSLAHours int
+	// SLASweepInterval is how often overdue certificates are escalated to the HOD.
+	SLASweepInterval time.Duration
+	// DriveFetchTimeout bounds downloads of certificate files from Google Drive.
+	DriveFetchTimeout time.Duration
+	// StatsDriftCheckInterval is how often the statistics tables are compared with certificates.
+	StatsDriftCheckInterval time.Duration
+	// StatsFoldInterval is how often pending statistics deltas are folded into the tables.
+	StatsFoldInterval time.Duration
+	// DashboardCacheTTL is how long dashboard overview and section responses are cached.
+	DashboardCacheTTL time.Duration
+	// IdempotencyStaleAfter is how long an unfinished Idempotency-Key reservation blocks retries
+	// before it is treated as abandoned and may be claimed again.
+	IdempotencyStaleAfter time.Duration
+	// IdempotencyKeyTTL is how long completed Idempotency-Key responses are kept for replay.
+	IdempotencyKeyTTL time.Duration
+	// IdempotencySweepInterval is how often expired Idempotency-Key records are deleted.
+	IdempotencySweepInterval time.Duration
+	// UploadSectionMismatch is "correct" to store uploads under the student's roster section,
+	// or "reject" to refuse uploads whose section differs from it.
+	UploadSectionMismatch string
 }
 
 // Load reads configuration from environment variables and optional .env file.
@@ -26,6 +53,68 @@ func Load() (*Config, error) {
 		AllowedEmailDomain: getEnv("ALLOWED_EMAIL_DOMAIN", "citchennai.net"),
 	}
 
+	leaseMinutes, err := getEnvInt("REVIEW_LEASE_MINUTES", 15)
+	if err != nil {
+		return nil, err
+	}
+	cfg.ReviewLeaseDuration = time.Duration(leaseMinutes) * time.Minute
+
+	if cfg.DefaultReviewSLAHours, err = getEnvInt("DEFAULT_REVIEW_SLA_HOURS", 72); err != nil {
+		return nil, err
+	}
+	sweepMinutes, err := getEnvInt("SLA_SWEEP_MINUTES", 15)
+	if err != nil {
+		return nil, err
+	}
+	cfg.SLASweepInterval = time.Duration(sweepMinutes) * time.Minute
+
+	fetchSeconds, err := getEnvInt("DRIVE_FETCH_TIMEOUT_SECONDS", 20)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DriveFetchTimeout = time.Duration(fetchSeconds) * time.Second
+
+	driftMinutes, err := getEnvInt("STATS_DRIFT_CHECK_MINUTES", 60)
+	if err != nil {
+		return nil, err
+	}
+	cfg.StatsDriftCheckInterval = time.Duration(driftMinutes) * time.Minute
+
+	foldSeconds, err := getEnvInt("STATS_FOLD_SECONDS", 5)
+	if err != nil {
+		return nil, err
+	}
+	cfg.StatsFoldInterval = time.Duration(foldSeconds) * time.Second
+
+	cacheSeconds, err := getEnvInt("DASHBOARD_CACHE_SECONDS", 30)
+	if err != nil {
+		return nil, err
+	}
+	cfg.DashboardCacheTTL = time.Duration(cacheSeconds) * time.Second
+
+	staleMinutes, err := getEnvInt("IDEMPOTENCY_STALE_MINUTES", 10)
+	if err != nil {
+		return nil, err
+	}
+	cfg.IdempotencyStaleAfter = time.Duration(staleMinutes) * time.Minute
+
+	ttlHours, err := getEnvInt("IDEMPOTENCY_TTL_HOURS", 24)
+	if err != nil {
+		return nil, err
+	}
+	cfg.IdempotencyKeyTTL = time.Duration(ttlHours) * time.Hour
+
+	idempotencySweepMinutes, err := getEnvInt("IDEMPOTENCY_SWEEP_MINUTES", 60)
+	if err != nil {
+		return nil, err
+	}
+	cfg.IdempotencySweepInterval = time.Duration(idempotencySweepMinutes) * time.Minute
+
+	cfg.UploadSectionMismatch = strings.ToLower(getEnv("UPLOAD_SECTION_MISMATCH", "correct"))
+	if cfg.UploadSectionMismatch != "correct" && cfg.UploadSectionMismatch != "reject" {
+		return nil, fmt.Errorf("UPLOAD_SECTION_MISMATCH must be correct or reject")
+	}
+
 	if cfg.DatabaseURL == "" {
 		return nil, fmt.Errorf("DATABASE_URL is required")
 	}
@@ -39,3 +128,15 @@ func getEnv(key, fallback string) string {
 	}
 	return fallback
 }
+
+func getEnvInt(key string, fallback int) (int, error) {
+	value, ok := os.LookupEnv(key)
+	if !ok || value == "" {
+		return fallback, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer", key)
+	}
+	return n, nil
+}
@@ -0,0 +1,98 @@
+// Package drive downloads publicly shared Google Drive files referenced by certificate links.
+package drive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidLink is returned when no file ID can be found in a Drive link.
+	ErrInvalidLink = errors.New("drive link does not reference a file")
+	// ErrNotAccessible is returned when Drive serves a web page instead of the file, which
+	// happens for files that are not shared publicly or that Drive refuses to scan.
+	ErrNotAccessible = errors.New("drive file is not publicly downloadable")
+	// ErrFileTooLarge is returned when the file exceeds the client's size limit.
+	ErrFileTooLarge = errors.New("drive file is too large")
+)
+
+// Same patterns as the generated certificates.drive_file_id column.
+var (
+	filePathPattern = regexp.MustCompile(`/d/([A-Za-z0-9_-]+)`)
+	fileIDPattern   = regexp.MustCompile(`[?&]id=([A-Za-z0-9_-]+)`)
+)
+
+const downloadURL = "https://drive.google.com/uc?export=download&id="
+
+// DefaultMaxFileBytes is the download limit used for certificate files (20 MiB).
+const DefaultMaxFileBytes = 20 << 20
+
+// Client fetches Drive files over HTTP.
+type Client struct {
+	http     *http.Client
+	maxBytes int64
+}
+
+// NewClient constructs a Client that gives up after timeout and refuses files over maxBytes.
+func NewClient(timeout time.Duration, maxBytes int64) *Client {
+	return &Client{http: &http.Client{Timeout: timeout}, maxBytes: maxBytes}
+}
+
+// FileID extracts the file ID from /file/d/<id>/ and ?id=<id> style links.
+func FileID(link string) (string, error) {
+	if m := filePathPattern.FindStringSubmatch(link); m != nil {
+		return m[1], nil
+	}
+	if m := fileIDPattern.FindStringSubmatch(link); m != nil {
+		return m[1], nil
+	}
+	return "", ErrInvalidLink
+}
+
+// Download returns the content of the file a Drive link points to.
+func (c *Client) Download(ctx context.Context, link string) ([]byte, error) {
+	id, err := FileID(link)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, downloadURL+url.QueryEscape(id), nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download drive file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusForbidden ||
+		resp.StatusCode == http.StatusUnauthorized {
+		return nil, ErrNotAccessible
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("download drive file: unexpected status %d", resp.StatusCode)
+	}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		return nil, ErrNotAccessible
+	}
+	if resp.ContentLength > c.maxBytes {
+		return nil, ErrFileTooLarge
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, c.maxBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("read drive file: %w", err)
+	}
+	if int64(len(data)) > c.maxBytes {
+		return nil, ErrFileTooLarge
+	}
+	return data, nil
+}
@@ -0,0 +1,227 @@
+package excel
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"department-eduvault-backend/models"
+	"github.com/xuri/excelize/v2"
+)
+
+// ImportTemplateSheet is the sheet read from XLSX imports (falls back to the first sheet).
+const ImportTemplateSheet = "Certificates"
+
+var (
+	// ErrUnsupportedImportFormat is returned for files that are neither XLSX nor CSV.
+	ErrUnsupportedImportFormat = errors.New("import file must be .xlsx or .csv")
+	// ErrMissingImportColumns is returned when the header row lacks required columns.
+	ErrMissingImportColumns = errors.New("import file is missing required columns")
+	// ErrUnreadableImportFile is returned when the file cannot be parsed as its extension claims.
+	ErrUnreadableImportFile = errors.New("import file could not be read")
+)
+
+// Template columns in order; the first four are required.
+var certificateImportColumns = []string{
+	"Register Number",
+	"Student Name",
+	"Section",
+	"Drive Link",
+	"Uploaded At",
+	"Title",
+	"Issuer",
+	"Category",
+}
+
+var requiredCertificateImportColumns = certificateImportColumns[:4]
+
+// CertificateImportRow is one data row as read from the spreadsheet. RowNumber is the
+// 1-based spreadsheet row so errors can point users at the right line.
+type CertificateImportRow struct {
+	RowNumber      int
+	RegisterNumber string
+	StudentName    string
+	Section        string
+	DriveLink      string
+	UploadedAt     string
+	Title          string
+	Issuer         string
+	Category       string
+}
+
+// ParseCertificateImport reads certificate rows from an XLSX or CSV file. Blank rows are skipped.
+func ParseCertificateImport(filename string, data []byte) ([]CertificateImportRow, error) {
+	records, err := readRecords(filename, data, ImportTemplateSheet)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, ErrMissingImportColumns
+	}
+
+	index := headerIndex(records[0])
+	var missing []string
+	for _, col := range requiredCertificateImportColumns {
+		if _, ok := index[normalizeHeader(col)]; !ok {
+			missing = append(missing, col)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingImportColumns, strings.Join(missing, ", "))
+	}
+
+	rows := make([]CertificateImportRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		get := func(col string) string {
+			idx, ok := index[normalizeHeader(col)]
+			if !ok || idx >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[idx])
+		}
+		row := CertificateImportRow{
+			RowNumber:      i + 2,
+			RegisterNumber: get("Register Number"),
+			StudentName:    get("Student Name"),
+			Section:        get("Section"),
+			DriveLink:      get("Drive Link"),
+			UploadedAt:     get("Uploaded At"),
+			Title:          get("Title"),
+			Issuer:         get("Issuer"),
+			Category:       get("Category"),
+		}
+		if row == (CertificateImportRow{RowNumber: row.RowNumber}) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// BuildCertificateImportTemplate renders an empty import sheet plus an instructions sheet.
+func BuildCertificateImportTemplate() ([]byte, error) {
+	f := excelize.NewFile()
+	f.SetSheetName(f.GetSheetName(0), ImportTemplateSheet)
+	for idx, header := range certificateImportColumns {
+		cell, _ := excelize.CoordinatesToCellName(idx+1, 1)
+		_ = f.SetCellValue(ImportTemplateSheet, cell, header)
+	}
+	autoSizeColumns(f, ImportTemplateSheet, len(certificateImportColumns))
+	// Keep Uploaded At as typed text; Excel would otherwise turn dates into locale-formatted
+	// serial numbers whose day/month order cannot be told apart.
+	if textStyle, err := f.NewStyle(&excelize.Style{NumFmt: 49}); err == nil {
+		_ = f.SetColStyle(ImportTemplateSheet, "E", textStyle)
+	}
+
+	const help = "Instructions"
+	_, _ = f.NewSheet(help)
+	lines := []string{
+		"Fill one certificate per row on the Certificates sheet; keep the header row.",
+		"Register Number, Student Name, Section and Drive Link are required.",
+		"Drive Link must start with https://drive.google.com/.",
+		"Uploaded At is optional: YYYY-MM-DD, DD/MM/YYYY (day first) or an RFC3339 timestamp; the import time is used when empty.",
+		"Title, Issuer and Category are optional; Category defaults to GENERAL.",
+	}
+	for i, line := range lines {
+		_ = f.SetCellValue(help, fmt.Sprintf("A%d", i+1), line)
+	}
+	_ = f.SetColWidth(help, "A", "A", 90)
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, fmt.Errorf("write workbook: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// BuildImportErrorReport renders row validation errors and warnings into a single-sheet XLSX
+// file.
+func BuildImportErrorReport(rowErrors []models.ImportJobError) ([]byte, error) {
+	f := excelize.NewFile()
+	sheet := "Errors"
+	f.SetSheetName(f.GetSheetName(0), sheet)
+
+	headers := []string{"Row", "Field", "Message", "Type"}
+	for idx, header := range headers {
+		cell, _ := excelize.CoordinatesToCellName(idx+1, 1)
+		_ = f.SetCellValue(sheet, cell, header)
+	}
+	for i, rowErr := range rowErrors {
+		row := i + 2
+		_ = f.SetCellValue(sheet, fmt.Sprintf("A%d", row), rowErr.RowNumber)
+		_ = f.SetCellValue(sheet, fmt.Sprintf("B%d", row), rowErr.Field)
+		_ = f.SetCellValue(sheet, fmt.Sprintf("C%d", row), rowErr.Message)
+		kind := "Error"
+		if rowErr.Warning {
+			kind = "Warning"
+		}
+		_ = f.SetCellValue(sheet, fmt.Sprintf("D%d", row), kind)
+	}
+	_ = f.SetColWidth(sheet, "A", "B", 18)
+	_ = f.SetColWidth(sheet, "C", "C", 70)
+	_ = f.SetColWidth(sheet, "D", "D", 12)
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, fmt.Errorf("write workbook: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// readRecords returns all rows of a CSV file, or of the named (else first) sheet of an XLSX file.
+func readRecords(filename string, data []byte, sheet string) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".csv":
+		reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+		reader.FieldsPerRecord = -1
+		var records [][]string
+		for {
+			record, err := reader.Read()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrUnreadableImportFile, err)
+			}
+			records = append(records, record)
+		}
+		return records, nil
+	case ".xlsx":
+		f, err := excelize.OpenReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrUnreadableImportFile, err)
+		}
+		defer f.Close()
+
+		name := sheet
+		if idx, err := f.GetSheetIndex(name); err != nil || idx < 0 {
+			name = f.GetSheetName(0)
+		}
+		rows, err := f.GetRows(name)
+		if err != nil {
+			return nil, fmt.Errorf("%w: sheet %q: %v", ErrUnreadableImportFile, name, err)
+		}
+		return rows, nil
+	default:
+		return nil, ErrUnsupportedImportFormat
+	}
+}
+
+func headerIndex(header []string) map[string]int {
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[normalizeHeader(h)] = i
+	}
+	return index
+}
+
+// normalizeHeader lets "register_number", "Register Number" and "REGISTER NUMBER" match.
+func normalizeHeader(h string) string {
+	h = strings.ToLower(strings.TrimSpace(h))
+	h = strings.NewReplacer("_", " ", "-", " ").Replace(h)
+	return strings.Join(strings.Fields(h), " ")
+}
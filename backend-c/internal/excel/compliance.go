@@ -0,0 +1,88 @@
+package excel
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// ComplianceRow is one student's progress against one quota in the compliance export.
+type ComplianceRow struct {
+	RegisterNumber string
+	StudentName    string
+	Section        string
+	Batch          string
+	FacultyEmail   string
+	Category       string
+	Required       int64
+	Earned         int64
+	Deficit        int64
+	Compliant      bool
+}
+
+// BuildComplianceWorkbook renders a quota compliance report into a single-sheet XLSX file,
+// one row per student and quota. description names the term and scope and is written above
+// the table.
+func BuildComplianceWorkbook(rows []ComplianceRow, description string) ([]byte, error) {
+	f := excelize.NewFile()
+	sheet := "Compliance"
+	f.SetSheetName(f.GetSheetName(0), sheet)
+
+	_ = f.SetCellValue(sheet, "A1", description)
+
+	headers := []string{
+		"Register Number",
+		"Student Name",
+		"Section",
+		"Batch",
+		"Faculty",
+		"Category",
+		"Required",
+		"Earned",
+		"Deficit",
+		"Student Status",
+	}
+	const headerRow = 3
+	for idx, header := range headers {
+		cell, _ := excelize.CoordinatesToCellName(idx+1, headerRow)
+		_ = f.SetCellValue(sheet, cell, header)
+	}
+
+	for i, r := range rows {
+		row := headerRow + 1 + i
+		status := "BEHIND"
+		if r.Compliant {
+			status = "COMPLIANT"
+		}
+		values := []interface{}{
+			r.RegisterNumber,
+			r.StudentName,
+			r.Section,
+			r.Batch,
+			r.FacultyEmail,
+			r.Category,
+			r.Required,
+			r.Earned,
+			r.Deficit,
+			status,
+		}
+		for col, val := range values {
+			cell, _ := excelize.CoordinatesToCellName(col+1, row)
+			_ = f.SetCellValue(sheet, cell, val)
+		}
+	}
+
+	_ = f.SetColWidth(sheet, "A", "A", 18)
+	_ = f.SetColWidth(sheet, "B", "B", 30)
+	_ = f.SetColWidth(sheet, "C", "D", 10)
+	_ = f.SetColWidth(sheet, "E", "E", 30)
+	_ = f.SetColWidth(sheet, "F", "F", 16)
+	_ = f.SetColWidth(sheet, "G", "J", 14)
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, fmt.Errorf("write workbook: %w", err)
+	}
+	return buf.Bytes(), nil
+}
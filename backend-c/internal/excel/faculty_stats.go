@@ -0,0 +1,86 @@
+package excel
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// FacultyStatsRow is one faculty member's line in the performance export.
+type FacultyStatsRow struct {
+	Faculty             string
+	Uploaded            int64
+	Reviewed            int64
+	Legit               int64
+	NotLegit            int64
+	LegitRatio          float64
+	MedianDecisionHours *float64
+	P90DecisionHours    *float64
+	MLDisagreements     int64
+	MLDisagreementRate  float64
+}
+
+// BuildFacultyStatsWorkbook renders faculty performance metrics into a single-sheet XLSX
+// file. period describes the covered date range and is written above the table.
+func BuildFacultyStatsWorkbook(rows []FacultyStatsRow, period string) ([]byte, error) {
+	f := excelize.NewFile()
+	sheet := "Faculty Performance"
+	f.SetSheetName(f.GetSheetName(0), sheet)
+
+	_ = f.SetCellValue(sheet, "A1", "Period")
+	_ = f.SetCellValue(sheet, "B1", period)
+
+	headers := []string{
+		"Faculty",
+		"Uploaded",
+		"Reviewed",
+		"LEGIT",
+		"NOT_LEGIT",
+		"LEGIT Ratio",
+		"Median Hours to Decision",
+		"P90 Hours to Decision",
+		"ML Disagreements",
+		"ML Disagreement Rate",
+	}
+	const headerRow = 3
+	for idx, header := range headers {
+		cell, _ := excelize.CoordinatesToCellName(idx+1, headerRow)
+		_ = f.SetCellValue(sheet, cell, header)
+	}
+
+	optional := func(v *float64) interface{} {
+		if v == nil {
+			return ""
+		}
+		return *v
+	}
+	for i, r := range rows {
+		row := headerRow + 1 + i
+		values := []interface{}{
+			r.Faculty,
+			r.Uploaded,
+			r.Reviewed,
+			r.Legit,
+			r.NotLegit,
+			r.LegitRatio,
+			optional(r.MedianDecisionHours),
+			optional(r.P90DecisionHours),
+			r.MLDisagreements,
+			r.MLDisagreementRate,
+		}
+		for col, val := range values {
+			cell, _ := excelize.CoordinatesToCellName(col+1, row)
+			_ = f.SetCellValue(sheet, cell, val)
+		}
+	}
+
+	_ = f.SetColWidth(sheet, "A", "A", 32)
+	_ = f.SetColWidth(sheet, "B", "J", 16)
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, fmt.Errorf("write workbook: %w", err)
+	}
+	return buf.Bytes(), nil
+}
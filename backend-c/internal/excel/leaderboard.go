@@ -0,0 +1,75 @@
+package excel
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/xuri/excelize/v2"
+)
+
+// LeaderboardRow is one ranked student in the leaderboard export.
+type LeaderboardRow struct {
+	Rank           int64
+	Percentile     float64
+	RegisterNumber string
+	StudentName    string
+	Section        string
+	LegitCount     int64
+	Points         float64
+	Score          float64
+}
+
+// BuildLeaderboardWorkbook renders a leaderboard into a single-sheet XLSX file. description
+// names the scope, metric and term and is written above the table.
+func BuildLeaderboardWorkbook(rows []LeaderboardRow, description string) ([]byte, error) {
+	f := excelize.NewFile()
+	sheet := "Leaderboard"
+	f.SetSheetName(f.GetSheetName(0), sheet)
+
+	_ = f.SetCellValue(sheet, "A1", description)
+
+	headers := []string{
+		"Rank",
+		"Percentile",
+		"Register Number",
+		"Student Name",
+		"Section",
+		"LEGIT Certificates",
+		"Points",
+		"Score",
+	}
+	const headerRow = 3
+	for idx, header := range headers {
+		cell, _ := excelize.CoordinatesToCellName(idx+1, headerRow)
+		_ = f.SetCellValue(sheet, cell, header)
+	}
+
+	for i, r := range rows {
+		row := headerRow + 1 + i
+		values := []interface{}{
+			r.Rank,
+			r.Percentile,
+			r.RegisterNumber,
+			r.StudentName,
+			r.Section,
+			r.LegitCount,
+			r.Points,
+			r.Score,
+		}
+		for col, val := range values {
+			cell, _ := excelize.CoordinatesToCellName(col+1, row)
+			_ = f.SetCellValue(sheet, cell, val)
+		}
+	}
+
+	_ = f.SetColWidth(sheet, "A", "B", 12)
+	_ = f.SetColWidth(sheet, "C", "C", 18)
+	_ = f.SetColWidth(sheet, "D", "D", 30)
+	_ = f.SetColWidth(sheet, "E", "H", 14)
+
+	var buf bytes.Buffer
+	if err := f.Write(&buf); err != nil {
+		return nil, fmt.Errorf("write workbook: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,84 @@
+package excel
+
+import (
+	"fmt"
+	"strings"
+)
+
+// RosterImportSheet is the sheet read from XLSX rosters (falls back to the first sheet).
+const RosterImportSheet = "Roster"
+
+// Roster columns; the first four are required. Blank optional cells keep a student's
+// current value.
+var rosterImportColumns = []string{
+	"Register Number",
+	"Student Name",
+	"Section",
+	"Faculty Email",
+	"Email",
+	"Semester",
+	"Batch",
+}
+
+var requiredRosterImportColumns = rosterImportColumns[:4]
+
+// RosterImportRow is one data row of a roster spreadsheet. RowNumber is the 1-based
+// spreadsheet row.
+type RosterImportRow struct {
+	RowNumber      int
+	RegisterNumber string
+	StudentName    string
+	Section        string
+	FacultyEmail   string
+	Email          string
+	Semester       string
+	Batch          string
+}
+
+// ParseRosterImport reads student rows from an XLSX or CSV roster. Blank rows are skipped.
+func ParseRosterImport(filename string, data []byte) ([]RosterImportRow, error) {
+	records, err := readRecords(filename, data, RosterImportSheet)
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, ErrMissingImportColumns
+	}
+
+	index := headerIndex(records[0])
+	var missing []string
+	for _, col := range requiredRosterImportColumns {
+		if _, ok := index[normalizeHeader(col)]; !ok {
+			missing = append(missing, col)
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrMissingImportColumns, strings.Join(missing, ", "))
+	}
+
+	rows := make([]RosterImportRow, 0, len(records)-1)
+	for i, record := range records[1:] {
+		get := func(col string) string {
+			idx, ok := index[normalizeHeader(col)]
+			if !ok || idx >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[idx])
+		}
+		row := RosterImportRow{
+			RowNumber:      i + 2,
+			RegisterNumber: get("Register Number"),
+			StudentName:    get("Student Name"),
+			Section:        get("Section"),
+			FacultyEmail:   get("Faculty Email"),
+			Email:          get("Email"),
+			Semester:       get("Semester"),
+			Batch:          get("Batch"),
+		}
+		if row == (RosterImportRow{RowNumber: row.RowNumber}) {
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
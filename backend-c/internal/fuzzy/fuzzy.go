@@ -0,0 +1,175 @@
+// Package fuzzy provides the tolerant string comparison used to match names and issuers
+// against extracted certificate text and student rosters.
+package fuzzy
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Match is the best-scoring region of a text for a searched phrase.
+type Match struct {
+	// Score is in [0, 1]; 1 means an exact match after normalization.
+	Score float64
+	// Snippet is the matched region as it appears in the original text.
+	Snippet string
+}
+
+// Normalize lower-cases s, strips accents and punctuation and collapses whitespace, so that
+// "  Rahul.KUMAR " and "rahul kumar" compare equal.
+func Normalize(s string) string {
+	return strings.Join(tokens(s), " ")
+}
+
+func tokens(s string) []string {
+	stripped, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), s)
+	if err != nil {
+		stripped = s
+	}
+	return strings.FieldsFunc(strings.ToLower(stripped), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Similarity compares two strings after normalization. Besides the plain edit distance it
+// tolerates reordered words ("KUMAR RAHUL" vs "Rahul Kumar") and lost spacing
+// ("R A H U L" vs "Rahul"), returning the best of the three views.
+func Similarity(a, b string) float64 {
+	ta, tb := tokens(a), tokens(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		if len(ta) == len(tb) {
+			return 1
+		}
+		return 0
+	}
+	return tokenSimilarity(ta, tb)
+}
+
+func tokenSimilarity(ta, tb []string) float64 {
+	best := ratio(strings.Join(ta, " "), strings.Join(tb, " "))
+	if best == 1 {
+		return best
+	}
+	if s := ratio(strings.Join(sorted(ta), " "), strings.Join(sorted(tb), " ")); s > best {
+		best = s
+	}
+	if s := ratio(strings.Join(ta, ""), strings.Join(tb, "")); s > best {
+		best = s
+	}
+	return best
+}
+
+// BestMatch slides a window over text and returns the region most similar to phrase.
+// Windows are sized around the phrase's word count so that extra or missing initials are
+// still found.
+func BestMatch(text, phrase string) Match {
+	want := tokens(phrase)
+	if len(want) == 0 {
+		return Match{}
+	}
+
+	words := strings.Fields(text)
+	normalized := make([][]string, len(words))
+	for i, w := range words {
+		normalized[i] = tokens(w)
+	}
+
+	var best Match
+	for size := len(want) - 1; size <= len(want)+1; size++ {
+		if size < 1 {
+			continue
+		}
+		for start := 0; start+size <= len(words); start++ {
+			var window []string
+			for _, w := range normalized[start : start+size] {
+				window = append(window, w...)
+			}
+			if len(window) == 0 {
+				continue
+			}
+			score := tokenSimilarity(window, want)
+			if score > best.Score {
+				best = Match{Score: score, Snippet: strings.Join(words[start:start+size], " ")}
+				if score == 1 {
+					return best
+				}
+			}
+		}
+	}
+
+	// Letter-spaced text ("R A H U L K U M A R") splits into far more words than the phrase;
+	// look for the phrase inside runs of single letters.
+	if best.Score < 1 {
+		target := strings.Join(want, "")
+		for _, run := range letterRuns(tokens(text)) {
+			if strings.Contains(strings.Join(run, ""), target) {
+				return Match{Score: 1, Snippet: strings.Join(run, " ")}
+			}
+		}
+	}
+	return best
+}
+
+// letterRuns returns the maximal runs of single-character tokens.
+func letterRuns(words []string) [][]string {
+	var runs [][]string
+	var run []string
+	for _, w := range words {
+		if len([]rune(w)) == 1 {
+			run = append(run, w)
+			continue
+		}
+		if len(run) > 1 {
+			runs = append(runs, run)
+		}
+		run = nil
+	}
+	if len(run) > 1 {
+		runs = append(runs, run)
+	}
+	return runs
+}
+
+// ratio is 1 - levenshtein(a, b) / max(len(a), len(b)) over runes.
+func ratio(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func sorted(words []string) []string {
+	out := append([]string(nil), words...)
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,58 @@
+package fuzzy
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want float64
+	}{
+		{name: "identical", a: "Rahul Kumar", b: "Rahul Kumar", want: 1},
+		{name: "case, spacing and punctuation", a: "  Rahul.KUMAR ", b: "rahul kumar", want: 1},
+		{name: "accents", a: "Aarón Dévi", b: "aaron devi", want: 1},
+		{name: "reordered words", a: "KUMAR RAHUL", b: "Rahul Kumar", want: 1},
+		{name: "letter-spaced", a: "R A H U L", b: "Rahul", want: 1},
+		{name: "one typo", a: "Rahul Kumar", b: "Rahul Kumr", want: 1 - 1.0/11},
+		{name: "missing initial", a: "Priya S", b: "Priya", want: 1 - 1.0/6},
+		{name: "unrelated", a: "abc", b: "xyz", want: 0},
+		{name: "both empty", a: "", b: " .. ", want: 1},
+		{name: "one empty", a: "Rahul", b: "", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Similarity(tt.a, tt.b); math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("Similarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+			if got := Similarity(tt.b, tt.a); math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("Similarity(%q, %q) = %v, want %v", tt.b, tt.a, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSimilarityRosterThreshold(t *testing.T) {
+	// Upload validation warns below 0.85; close spellings must stay above it and different
+	// students must fall below it.
+	const threshold = 0.85
+
+	tests := []struct {
+		a, b  string
+		above bool
+	}{
+		{a: "Sowmya Ramesh", b: "Soumya Ramesh", above: true},
+		{a: "Mohammed Irfan", b: "Mohamed Irfan", above: true},
+		{a: "Sowmya Ramesh", b: "Karthik Ramesh", above: false},
+		{a: "Arun Prakash", b: "Varun Prasad", above: false},
+	}
+
+	for _, tt := range tests {
+		if got := Similarity(tt.a, tt.b); (got >= threshold) != tt.above {
+			t.Errorf("Similarity(%q, %q) = %.3f, above %.2f = %v, want %v", tt.a, tt.b, got, threshold, got >= threshold, tt.above)
+		}
+	}
+}
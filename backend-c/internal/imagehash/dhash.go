@@ -0,0 +1,116 @@
+// Package imagehash computes perceptual hashes of certificate images so that re-saved,
+// re-encoded or lightly edited copies of the same picture can be found.
+package imagehash
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"math/bits"
+
+	// Decoders for the formats students upload screenshots and scans in.
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/webp"
+)
+
+// Bands is the number of 8-bit bands a hash is split into for indexing. Two hashes within
+// Hamming distance Bands-1 share at least one identical band (pigeonhole), so an exact band
+// lookup finds every candidate up to that distance.
+const Bands = 8
+
+// MaxPixels is the largest image Decode accepts, 8192x8192. The header is checked before any
+// pixels are decoded, so a small file claiming huge dimensions is never expanded in memory.
+const MaxPixels = 8192 * 8192
+
+const maxSamples = 512
+
+var (
+	// ErrUndecodableImage is returned for data no registered decoder understands.
+	ErrUndecodableImage = errors.New("unsupported or corrupt image")
+	// ErrImageTooLarge is returned for images with more than MaxPixels pixels.
+	ErrImageTooLarge = errors.New("image dimensions are too large")
+)
+
+// Decode reads a PNG, JPEG, GIF, BMP or WebP image of at most MaxPixels pixels.
+func Decode(data []byte) (image.Image, error) {
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUndecodableImage
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, ErrUndecodableImage
+	}
+	if cfg.Width > MaxPixels/cfg.Height {
+		return nil, ErrImageTooLarge
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, ErrUndecodableImage
+	}
+	return img, nil
+}
+
+// DHash computes a 64-bit difference hash: the image is averaged down to a 9x8 grayscale
+// grid and each bit records whether a cell is brighter than its right-hand neighbour. It is
+// stable under rescaling, recompression and small colour or brightness changes.
+func DHash(img image.Image) uint64 {
+	const cols, rows = 9, 8
+	var sum [rows][cols]float64
+	var count [rows][cols]float64
+
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+	if w == 0 || h == 0 {
+		return 0
+	}
+	// Large photos are sampled on a grid of at most maxSamples x maxSamples pixels; that is
+	// far finer than the 9x8 cells and keeps hashing fast.
+	stepX, stepY := max(1, w/maxSamples), max(1, h/maxSamples)
+	for y := b.Min.Y; y < b.Max.Y; y += stepY {
+		cy := (y - b.Min.Y) * rows / h
+		for x := b.Min.X; x < b.Max.X; x += stepX {
+			cx := (x - b.Min.X) * cols / w
+			r, g, bl, _ := img.At(x, y).RGBA()
+			// ITU-R BT.601 luma on 16-bit channels.
+			sum[cy][cx] += 0.299*float64(r) + 0.587*float64(g) + 0.114*float64(bl)
+			count[cy][cx]++
+		}
+	}
+
+	var hash uint64
+	for y := 0; y < rows; y++ {
+		for x := 0; x < cols-1; x++ {
+			hash <<= 1
+			left, right := cellMean(sum[y][x], count[y][x]), cellMean(sum[y][x+1], count[y][x+1])
+			if left > right {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
+
+func cellMean(sum, count float64) float64 {
+	if count == 0 {
+		return 0
+	}
+	return sum / count
+}
+
+// Distance is the Hamming distance between two hashes.
+func Distance(a, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// SplitBands returns the hash's bytes, most significant first, for band indexing.
+func SplitBands(hash uint64) [Bands]uint8 {
+	var bands [Bands]uint8
+	for i := range bands {
+		bands[i] = uint8(hash >> (8 * (Bands - 1 - i)))
+	}
+	return bands
+}
@@ -0,0 +1,206 @@
+package imagehash
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"math"
+	"testing"
+
+	"golang.org/x/image/draw"
+)
+
+// nearDuplicateMaxDistance mirrors the threshold the verification service flags pairs at.
+const nearDuplicateMaxDistance = Bands - 1
+
+// render draws a smooth, certificate-sized test pattern shifted by seed.
+func render(w, h int, seed float64) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			fx, fy := float64(x)/float64(w), float64(y)/float64(h)
+			v := 128 + 100*math.Sin(fx*7+seed)*math.Cos(fy*5-seed)
+			img.Set(x, y, color.RGBA{uint8(v), uint8(255 - v), uint8(fx * 255), 255})
+		}
+	}
+	return img
+}
+
+// rings draws concentric rings, a layout unlike any render output.
+func rings(w, h int) image.Image {
+	img := image.NewGray(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			r := math.Hypot(float64(x-w/3), float64(y-h/2))
+			img.SetGray(x, y, color.Gray{uint8(128 + 120*math.Sin(r/45))})
+		}
+	}
+	return img
+}
+
+func resize(img image.Image, w, h int) image.Image {
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	draw.ApproxBiLinear.Scale(dst, dst.Bounds(), img, img.Bounds(), draw.Src, nil)
+	return dst
+}
+
+func encodeJPEG(t *testing.T, img image.Image, quality int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("encode png: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func decode(t *testing.T, data []byte) image.Image {
+	t.Helper()
+	img, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	return img
+}
+
+func sharesBand(a, b uint64) bool {
+	bandsA, bandsB := SplitBands(a), SplitBands(b)
+	for i := range bandsA {
+		if bandsA[i] == bandsB[i] {
+			return true
+		}
+	}
+	return false
+}
+
+func TestDHashNearDuplicates(t *testing.T) {
+	original := render(800, 600, 1)
+	originalHash := DHash(decode(t, encodePNG(t, original)))
+
+	tests := []struct {
+		name string
+		copy func(t *testing.T) image.Image
+		near bool
+	}{
+		{
+			name: "re-encoded as low quality JPEG",
+			copy: func(t *testing.T) image.Image { return decode(t, encodeJPEG(t, original, 40)) },
+			near: true,
+		},
+		{
+			name: "downscaled by half",
+			copy: func(t *testing.T) image.Image { return decode(t, encodePNG(t, resize(original, 400, 300))) },
+			near: true,
+		},
+		{
+			name: "upscaled and re-encoded as JPEG",
+			copy: func(t *testing.T) image.Image {
+				return decode(t, encodeJPEG(t, resize(original, 1200, 900), 75))
+			},
+			near: true,
+		},
+		{
+			name: "thumbnail with a different aspect ratio",
+			copy: func(t *testing.T) image.Image { return decode(t, encodeJPEG(t, resize(original, 160, 100), 60)) },
+			near: true,
+		},
+		{
+			name: "unrelated picture",
+			copy: func(t *testing.T) image.Image { return decode(t, encodePNG(t, render(800, 600, 2.5))) },
+			near: false,
+		},
+		{
+			name: "unrelated picture re-encoded as JPEG",
+			copy: func(t *testing.T) image.Image { return decode(t, encodeJPEG(t, rings(800, 600), 40)) },
+			near: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hash := DHash(tt.copy(t))
+			distance := Distance(originalHash, hash)
+			if near := distance <= nearDuplicateMaxDistance; near != tt.near {
+				t.Fatalf("distance = %d, near duplicate = %v, want %v", distance, near, tt.near)
+			}
+			if tt.near && !sharesBand(originalHash, hash) {
+				t.Fatalf("near duplicate at distance %d shares no band with the original", distance)
+			}
+		})
+	}
+}
+
+func TestSplitBandsFindsEveryPairBelowBands(t *testing.T) {
+	const hash = 0x0123456789abcdef
+	for distance := 0; distance < Bands; distance++ {
+		// Flip one bit in each of the first distance bands: the worst case for the index.
+		other := uint64(hash)
+		for band := 0; band < distance; band++ {
+			other ^= 1 << (8 * band)
+		}
+		if got := Distance(hash, other); got != distance {
+			t.Fatalf("Distance = %d, want %d", got, distance)
+		}
+		if !sharesBand(hash, other) {
+			t.Fatalf("hashes at distance %d share no band", distance)
+		}
+	}
+
+	all := uint64(hash)
+	for band := 0; band < Bands; band++ {
+		all ^= 1 << (8 * band)
+	}
+	if sharesBand(hash, all) {
+		t.Fatalf("hashes differing in every band share a band")
+	}
+}
+
+func TestDecode(t *testing.T) {
+	small := encodePNG(t, render(32, 24, 1))
+
+	// A GIF whose header claims a 9000x9000 canvas but holds a single pixel.
+	var huge bytes.Buffer
+	if err := gif.Encode(&huge, image.NewPaletted(image.Rect(0, 0, 1, 1), color.Palette{color.White}), nil); err != nil {
+		t.Fatalf("encode gif: %v", err)
+	}
+	hugeGIF := huge.Bytes()
+	binary.LittleEndian.PutUint16(hugeGIF[6:8], 9000)
+	binary.LittleEndian.PutUint16(hugeGIF[8:10], 9000)
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{name: "png", data: small},
+		{name: "jpeg", data: encodeJPEG(t, render(32, 24, 1), 80)},
+		{name: "over the pixel cap", data: hugeGIF, wantErr: ErrImageTooLarge},
+		{name: "not an image", data: []byte("%PDF-1.7 not an image"), wantErr: ErrUndecodableImage},
+		{name: "truncated", data: small[:len(small)/2], wantErr: ErrUndecodableImage},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			img, err := Decode(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Decode error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && img.Bounds().Dx() != 32 {
+				t.Fatalf("decoded width = %d, want 32", img.Bounds().Dx())
+			}
+		})
+	}
+}
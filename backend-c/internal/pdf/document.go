@@ -0,0 +1,459 @@
+// Package pdf is a small, dependency-free PDF reader. It understands enough of the file
+// structure (indirect objects, object streams, Flate/ASCII85/ASCIIHex filters) to pull text
+// and embedded images out of typical generated certificates. It does not use the xref table,
+// so it also copes with slightly damaged files; encrypted PDFs are not supported.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/ascii85"
+	"encoding/hex"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+var (
+	// ErrNotPDF is returned when the data does not start with a PDF header.
+	ErrNotPDF = errors.New("data is not a PDF document")
+	// ErrEncrypted is returned for password-protected or encrypted PDFs.
+	ErrEncrypted = errors.New("encrypted PDFs are not supported")
+	// ErrMalformed is returned when a PDF header is present but no objects can be read.
+	ErrMalformed = errors.New("PDF document has no readable objects")
+)
+
+// maxDecodedStream bounds how much a single stream may inflate to.
+const maxDecodedStream = 32 << 20
+
+var (
+	objHeaderPattern = regexp.MustCompile(`(\d+)\s+\d+\s+obj\b`)
+	refPattern       = regexp.MustCompile(`^(\d+)\s+\d+\s+R\b`)
+	namedRefPattern  = regexp.MustCompile(`/([^\s/<>\[\]()]+)\s+(\d+)\s+\d+\s+R\b`)
+	intPattern       = regexp.MustCompile(`^-?\d+`)
+)
+
+// object is one indirect object: its dictionary (or other value) and raw stream bytes.
+type object struct {
+	dict   []byte
+	stream []byte
+}
+
+// Document is a parsed PDF keyed by object number.
+type Document struct {
+	objects map[int]*object
+	order   []int
+}
+
+// IsPDF reports whether data starts with a PDF header.
+func IsPDF(data []byte) bool {
+	head := data
+	if len(head) > 1024 {
+		head = head[:1024]
+	}
+	return bytes.Contains(head, []byte("%PDF-"))
+}
+
+// Parse scans data for indirect objects. Later definitions of an object number replace earlier
+// ones, which matches how incremental updates are applied.
+func Parse(data []byte) (*Document, error) {
+	if !IsPDF(data) {
+		return nil, ErrNotPDF
+	}
+	doc := &Document{objects: make(map[int]*object)}
+
+	pos := 0
+	for pos < len(data) {
+		loc := objHeaderPattern.FindSubmatchIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+		num, _ := strconv.Atoi(string(data[pos+loc[2] : pos+loc[3]]))
+		start := pos + loc[1]
+		obj, end := readObject(data, start)
+		doc.add(num, obj)
+		pos = end
+	}
+	if len(doc.objects) == 0 {
+		return nil, ErrMalformed
+	}
+
+	if trailerIsEncrypted(data) || doc.xrefStreamIsEncrypted() {
+		return nil, ErrEncrypted
+	}
+	doc.expandObjectStreams()
+	return doc, nil
+}
+
+func (d *Document) add(num int, obj *object) {
+	if _, exists := d.objects[num]; !exists {
+		d.order = append(d.order, num)
+	}
+	d.objects[num] = obj
+}
+
+// readObject reads the body of an object starting right after "N G obj" and returns it with
+// the offset just past its "endobj".
+func readObject(data []byte, start int) (*object, int) {
+	endObj := bytes.Index(data[start:], []byte("endobj"))
+	streamKw := indexStreamKeyword(data[start:])
+	if streamKw < 0 || (endObj >= 0 && streamKw > endObj) {
+		if endObj < 0 {
+			return &object{dict: data[start:]}, len(data)
+		}
+		return &object{dict: data[start : start+endObj]}, start + endObj + len("endobj")
+	}
+
+	dict := data[start : start+streamKw]
+	body := start + streamKw + len("stream")
+	if body < len(data) && data[body] == '\r' {
+		body++
+	}
+	if body < len(data) && data[body] == '\n' {
+		body++
+	}
+
+	bodyEnd := -1
+	if n, ok := dictInt(dict, "Length"); ok && n >= 0 && body+n <= len(data) {
+		rest := bytes.TrimLeft(data[body+n:], " \r\n\t")
+		if bytes.HasPrefix(rest, []byte("endstream")) {
+			bodyEnd = body + n
+		}
+	}
+	if bodyEnd < 0 {
+		idx := bytes.Index(data[body:], []byte("endstream"))
+		if idx < 0 {
+			return &object{dict: dict, stream: data[body:]}, len(data)
+		}
+		bodyEnd = body + idx
+		// The EOL before endstream is not part of the data.
+		for bodyEnd > body && (data[bodyEnd-1] == '\n' || data[bodyEnd-1] == '\r') {
+			bodyEnd--
+		}
+	}
+
+	next := bodyEnd
+	if idx := bytes.Index(data[bodyEnd:], []byte("endobj")); idx >= 0 {
+		next = bodyEnd + idx + len("endobj")
+	} else {
+		next = len(data)
+	}
+	return &object{dict: dict, stream: data[body:bodyEnd]}, next
+}
+
+// indexStreamKeyword finds the "stream" keyword, skipping occurrences inside "endstream".
+func indexStreamKeyword(data []byte) int {
+	offset := 0
+	for {
+		idx := bytes.Index(data[offset:], []byte("stream"))
+		if idx < 0 {
+			return -1
+		}
+		abs := offset + idx
+		if abs >= 3 && string(data[abs-3:abs]) == "end" {
+			offset = abs + len("stream")
+			continue
+		}
+		return abs
+	}
+}
+
+func trailerIsEncrypted(data []byte) bool {
+	idx := bytes.LastIndex(data, []byte("trailer"))
+	if idx < 0 {
+		return false
+	}
+	_, ok := dictEntry(data[idx:], "Encrypt")
+	return ok
+}
+
+// xrefStreamIsEncrypted checks cross-reference streams, which replace the trailer in PDF 1.5+.
+func (d *Document) xrefStreamIsEncrypted() bool {
+	for _, obj := range d.objects {
+		if obj.stream != nil && dictHasName(obj.dict, "Type", "XRef") {
+			if _, ok := dictEntry(obj.dict, "Encrypt"); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// expandObjectStreams adds the objects packed inside /Type /ObjStm streams. Objects that were
+// also written directly keep their direct definition.
+func (d *Document) expandObjectStreams() {
+	for _, num := range append([]int(nil), d.order...) {
+		obj := d.objects[num]
+		if obj.stream == nil || !dictHasName(obj.dict, "Type", "ObjStm") {
+			continue
+		}
+		decoded, err := d.decodeStream(obj)
+		if err != nil {
+			continue
+		}
+		first, ok := d.dictIntResolved(obj.dict, "First")
+		if !ok || first > len(decoded) {
+			continue
+		}
+		fields := bytes.Fields(decoded[:first])
+		type entry struct{ num, off int }
+		var entries []entry
+		for i := 0; i+1 < len(fields); i += 2 {
+			n, err1 := strconv.Atoi(string(fields[i]))
+			off, err2 := strconv.Atoi(string(fields[i+1]))
+			if err1 != nil || err2 != nil {
+				break
+			}
+			entries = append(entries, entry{n, off})
+		}
+		for i, e := range entries {
+			start := first + e.off
+			end := len(decoded)
+			if i+1 < len(entries) {
+				end = first + entries[i+1].off
+			}
+			if start < 0 || start > end || end > len(decoded) {
+				continue
+			}
+			if _, exists := d.objects[e.num]; !exists {
+				d.add(e.num, &object{dict: decoded[start:end]})
+			}
+		}
+	}
+}
+
+// resolve follows an indirect reference ("N G R") to its object.
+func (d *Document) resolve(value []byte) (*object, bool) {
+	m := refPattern.FindSubmatch(bytes.TrimSpace(value))
+	if m == nil {
+		return nil, false
+	}
+	num, _ := strconv.Atoi(string(m[1]))
+	obj, ok := d.objects[num]
+	return obj, ok
+}
+
+// resolveDict returns the dictionary a value denotes, inline or by reference.
+func (d *Document) resolveDict(value []byte) ([]byte, bool) {
+	value = bytes.TrimSpace(value)
+	if bytes.HasPrefix(value, []byte("<<")) {
+		return balancedDict(value)
+	}
+	if obj, ok := d.resolve(value); ok {
+		return balancedDict(bytes.TrimSpace(obj.dict))
+	}
+	return nil, false
+}
+
+func (d *Document) dictIntResolved(dict []byte, key string) (int, bool) {
+	if n, ok := dictInt(dict, key); ok {
+		return n, true
+	}
+	value, ok := dictEntry(dict, key)
+	if !ok {
+		return 0, false
+	}
+	obj, ok := d.resolve(value)
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(string(bytes.TrimSpace(obj.dict)))
+	return n, err == nil
+}
+
+// decodeStream applies the stream's filters. Image codecs (DCTDecode, JPXDecode, ...) are left
+// in place so callers can hand the bytes to an image decoder.
+func (d *Document) decodeStream(obj *object) ([]byte, error) {
+	data := obj.stream
+	for _, filter := range streamFilters(obj.dict) {
+		var err error
+		switch filter {
+		case "FlateDecode", "Fl":
+			data, err = inflate(data)
+		case "ASCII85Decode", "A85":
+			data, err = decodeASCII85(data)
+		case "ASCIIHexDecode", "AHx":
+			data, err = decodeASCIIHex(data)
+		default:
+			return data, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// streamFilters lists the stream's /Filter names in application order.
+func streamFilters(dict []byte) []string {
+	value, ok := dictEntry(dict, "Filter")
+	if !ok {
+		return nil
+	}
+	value = bytes.TrimSpace(value)
+	if bytes.HasPrefix(value, []byte("[")) {
+		end := bytes.IndexByte(value, ']')
+		if end < 0 {
+			return nil
+		}
+		value = value[1:end]
+	} else if end := bytes.IndexAny(value[1:], " \t\r\n/<>[]"); end >= 0 {
+		value = value[:end+1]
+	}
+	var filters []string
+	for _, part := range bytes.Split(value, []byte("/")) {
+		if name := string(bytes.TrimSpace(part)); name != "" {
+			filters = append(filters, name)
+		}
+	}
+	return filters
+}
+
+// inflate decompresses zlib data, keeping whatever was recovered from truncated streams.
+func inflate(data []byte) ([]byte, error) {
+	r, err := zlib.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	out, err := io.ReadAll(io.LimitReader(r, maxDecodedStream))
+	if err != nil && len(out) == 0 {
+		return nil, err
+	}
+	return out, nil
+}
+
+func decodeASCII85(data []byte) ([]byte, error) {
+	data = bytes.TrimSpace(data)
+	data = bytes.TrimPrefix(data, []byte("<~"))
+	if idx := bytes.Index(data, []byte("~>")); idx >= 0 {
+		data = data[:idx]
+	}
+	out := make([]byte, 4*len(data)/5+4)
+	n, _, err := ascii85.Decode(out, data, true)
+	if err != nil {
+		return nil, err
+	}
+	return out[:n], nil
+}
+
+func decodeASCIIHex(data []byte) ([]byte, error) {
+	if idx := bytes.IndexByte(data, '>'); idx >= 0 {
+		data = data[:idx]
+	}
+	clean := make([]byte, 0, len(data)+1)
+	for _, c := range data {
+		if isHexDigit(c) {
+			clean = append(clean, c)
+		}
+	}
+	if len(clean)%2 == 1 {
+		clean = append(clean, '0')
+	}
+	out := make([]byte, len(clean)/2)
+	_, err := hex.Decode(out, clean)
+	return out, err
+}
+
+// Dictionary helpers ----------------------------------------------------------
+// These work on raw dictionary text and find keys at any nesting depth, which is enough for
+// the flat dictionaries found in page trees, fonts and images.
+
+// dictEntry returns the text following /key.
+func dictEntry(dict []byte, key string) ([]byte, bool) {
+	needle := []byte("/" + key)
+	offset := 0
+	for {
+		idx := bytes.Index(dict[offset:], needle)
+		if idx < 0 {
+			return nil, false
+		}
+		end := offset + idx + len(needle)
+		if end >= len(dict) || isDelimiter(dict[end]) {
+			return dict[end:], true
+		}
+		offset = end
+	}
+}
+
+func dictInt(dict []byte, key string) (int, bool) {
+	value, ok := dictEntry(dict, key)
+	if !ok {
+		return 0, false
+	}
+	value = bytes.TrimSpace(value)
+	if refPattern.Match(value) {
+		return 0, false
+	}
+	m := intPattern.Find(value)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(string(m))
+	return n, err == nil
+}
+
+func dictName(dict []byte, key string) string {
+	value, ok := dictEntry(dict, key)
+	if !ok {
+		return ""
+	}
+	value = bytes.TrimSpace(value)
+	if !bytes.HasPrefix(value, []byte("/")) {
+		return ""
+	}
+	end := bytes.IndexFunc(value[1:], func(r rune) bool { return r < 128 && isDelimiter(byte(r)) })
+	if end < 0 {
+		return string(value[1:])
+	}
+	return string(value[1 : end+1])
+}
+
+func dictHasName(dict []byte, key, name string) bool {
+	return dictName(dict, key) == name
+}
+
+// balancedDict returns the "<< ... >>" dictionary at the start of value.
+func balancedDict(value []byte) ([]byte, bool) {
+	if !bytes.HasPrefix(value, []byte("<<")) {
+		return nil, false
+	}
+	depth := 0
+	for i := 0; i+1 < len(value); i++ {
+		switch {
+		case value[i] == '<' && value[i+1] == '<':
+			depth++
+			i++
+		case value[i] == '>' && value[i+1] == '>':
+			depth--
+			i++
+			if depth == 0 {
+				return value[:i+1], true
+			}
+		}
+	}
+	return value, true
+}
+
+// namedRefs maps each /Name N G R entry of a dictionary to its object number.
+func namedRefs(dict []byte) map[string]int {
+	refs := make(map[string]int)
+	for _, m := range namedRefPattern.FindAllSubmatch(dict, -1) {
+		num, _ := strconv.Atoi(string(m[2]))
+		refs[string(m[1])] = num
+	}
+	return refs
+}
+
+func isDelimiter(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0, '/', '<', '>', '[', ']', '(', ')', '%', '{', '}':
+		return true
+	}
+	return false
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
@@ -0,0 +1,277 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"testing"
+)
+
+// pdfObject is one indirect object for buildPDF; stream is written with an accurate /Length
+// unless the dictionary already has one.
+type pdfObject struct {
+	num    int
+	dict   string
+	stream []byte
+}
+
+// buildPDF writes a PDF with the given objects, an xref table and trailer.
+func buildPDF(trailer string, objects ...pdfObject) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.7\n%\xe2\xe3\xcf\xd3\n")
+	offsets := make(map[int]int)
+	for _, obj := range objects {
+		offsets[obj.num] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n", obj.num)
+		if obj.stream == nil {
+			fmt.Fprintf(&buf, "%s\nendobj\n", obj.dict)
+			continue
+		}
+		dict := obj.dict
+		if !bytes.Contains([]byte(dict), []byte("/Length")) {
+			dict = fmt.Sprintf("<< /Length %d %s", len(obj.stream), dict[2:])
+		}
+		fmt.Fprintf(&buf, "%s\nstream\n%s\nendstream\nendobj\n", dict, obj.stream)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, obj := range objects {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offsets[obj.num])
+	}
+	fmt.Fprintf(&buf, "trailer\n%s\nstartxref\n%d\n%%%%EOF\n", trailer, xref)
+	return buf.Bytes()
+}
+
+func deflate(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zlib.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatalf("deflate: %v", err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("deflate: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// textPDF is a one-page document showing content with font F1.
+func textPDF(content []byte, contentDict string) []byte {
+	return buildPDF("<< /Size 6 /Root 1 0 R >>",
+		pdfObject{num: 1, dict: "<< /Type /Catalog /Pages 2 0 R >>"},
+		pdfObject{num: 2, dict: "<< /Type /Pages /Kids [3 0 R] /Count 1 /Resources << /Font << /F1 5 0 R >> >> >>"},
+		pdfObject{num: 3, dict: "<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Contents 4 0 R >>"},
+		pdfObject{num: 4, dict: contentDict, stream: content},
+		pdfObject{num: 5, dict: "<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>"},
+	)
+}
+
+func TestParseErrors(t *testing.T) {
+	catalog := pdfObject{num: 1, dict: "<< /Type /Catalog >>"}
+
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr error
+	}{
+		{name: "plain document", data: buildPDF("<< /Size 2 /Root 1 0 R >>", catalog)},
+		{name: "not a PDF", data: []byte("\x89PNG\r\n\x1a\n"), wantErr: ErrNotPDF},
+		{name: "header without objects", data: []byte("%PDF-1.4\ngarbage\n%%EOF\n"), wantErr: ErrMalformed},
+		{
+			name:    "encrypted trailer",
+			data:    buildPDF("<< /Size 2 /Root 1 0 R /Encrypt 9 0 R >>", catalog),
+			wantErr: ErrEncrypted,
+		},
+		{
+			name: "encrypted cross-reference stream",
+			data: buildPDF("<< /Size 3 >>",
+				catalog,
+				pdfObject{num: 2, dict: "<< /Type /XRef /Size 3 /W [1 2 1] /Encrypt 9 0 R >>", stream: []byte{0, 0, 0, 0}},
+			),
+			wantErr: ErrEncrypted,
+		},
+		{
+			name: "plain cross-reference stream",
+			data: buildPDF("<< /Size 3 >>",
+				catalog,
+				pdfObject{num: 2, dict: "<< /Type /XRef /Size 3 /W [1 2 1] /Root 1 0 R >>", stream: []byte{0, 0, 0, 0}},
+			),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := Parse(tt.data)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse error = %v, want %v", err, tt.wantErr)
+			}
+			if tt.wantErr == nil && doc.objects[1] == nil {
+				t.Fatalf("catalog object was not parsed")
+			}
+		})
+	}
+}
+
+func TestParseObjects(t *testing.T) {
+	packed := []byte("10 0 11 25 << /Name (packed ten) >> << /Name (packed eleven) >>")
+	data := buildPDF("<< /Size 12 /Root 1 0 R >>",
+		pdfObject{num: 1, dict: "<< /Type /Catalog /Version 1 >>"},
+		// /Length covers data that itself contains the endstream keyword.
+		pdfObject{num: 2, dict: "<< >>", stream: []byte("BT (endstream) Tj ET")},
+		// A wrong /Length falls back to scanning for endstream.
+		pdfObject{num: 3, dict: "<< /Length 999 >>", stream: []byte("q Q")},
+		pdfObject{num: 4, dict: "<< /Type /ObjStm /N 2 /First 11 /Filter /FlateDecode >>", stream: deflate(t, packed)},
+		pdfObject{num: 11, dict: "<< /Name (direct eleven) >>"},
+		// An incremental update redefines object 1.
+		pdfObject{num: 1, dict: "<< /Type /Catalog /Version 2 >>"},
+	)
+
+	doc, err := Parse(data)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if n, _ := dictInt(doc.objects[1].dict, "Version"); n != 2 {
+		t.Errorf("object 1 /Version = %d, want the later definition 2", n)
+	}
+	if got := string(doc.objects[2].stream); got != "BT (endstream) Tj ET" {
+		t.Errorf("object 2 stream = %q, want the /Length bytes", got)
+	}
+	if got := string(doc.objects[3].stream); got != "q Q" {
+		t.Errorf("object 3 stream = %q, want %q", got, "q Q")
+	}
+
+	tests := []struct {
+		num  int
+		want string
+	}{
+		{num: 10, want: "(packed ten)"},
+		{num: 11, want: "(direct eleven)"},
+	}
+	for _, tt := range tests {
+		obj, ok := doc.objects[tt.num]
+		if !ok {
+			t.Errorf("object %d missing", tt.num)
+			continue
+		}
+		if !bytes.Contains(obj.dict, []byte(tt.want)) {
+			t.Errorf("object %d = %q, want it to contain %q", tt.num, obj.dict, tt.want)
+		}
+	}
+}
+
+func TestExtractText(t *testing.T) {
+	content := []byte("BT /F1 24 Tf 72 720 Td (Certificate of Merit) Tj 0 -30 Td [(Awarded to) -400 (Asha)] TJ ET")
+
+	tests := []struct {
+		name    string
+		content []byte
+		dict    string
+	}{
+		{name: "plain content stream", content: content, dict: "<< >>"},
+		{name: "flate content stream", content: deflate(t, content), dict: "<< /Filter /FlateDecode >>"},
+		{name: "ascii hex content stream", content: []byte(fmt.Sprintf("%x>", content)), dict: "<< /Filter /ASCIIHexDecode >>"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text, err := ExtractText(textPDF(tt.content, tt.dict))
+			if err != nil {
+				t.Fatalf("ExtractText: %v", err)
+			}
+			if want := "Certificate of Merit\nAwarded to Asha"; text != want {
+				t.Fatalf("ExtractText = %q, want %q", text, want)
+			}
+		})
+	}
+}
+
+func TestLargestImage(t *testing.T) {
+	// A small JPEG whose SOF0 header claims 60000x60000 pixels.
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, image.NewGray(image.Rect(0, 0, 64, 64)), nil); err != nil {
+		t.Fatalf("encode jpeg: %v", err)
+	}
+	hugeJPEG := buf.Bytes()
+	sof := bytes.Index(hugeJPEG, []byte{0xFF, 0xC0})
+	if sof < 0 {
+		t.Fatal("jpeg has no SOF0 marker")
+	}
+	binary.BigEndian.PutUint16(hugeJPEG[sof+5:], 60000)
+	binary.BigEndian.PutUint16(hugeJPEG[sof+7:], 60000)
+
+	gray := func(side int) []byte {
+		samples := make([]byte, side*side)
+		for i := range samples {
+			samples[i] = byte(i)
+		}
+		return deflate(t, samples)
+	}
+	image := func(num, side int) pdfObject {
+		return pdfObject{
+			num:    num,
+			dict:   fmt.Sprintf("<< /Type /XObject /Subtype /Image /Width %d /Height %d /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /FlateDecode >>", side, side),
+			stream: gray(side),
+		}
+	}
+
+	tests := []struct {
+		name      string
+		objects   []pdfObject
+		wantOK    bool
+		wantWidth int
+	}{
+		{
+			name:      "largest of two images",
+			objects:   []pdfObject{image(2, 64), image(3, 96)},
+			wantOK:    true,
+			wantWidth: 96,
+		},
+		{
+			name:    "only an icon",
+			objects: []pdfObject{image(2, 16)},
+		},
+		{
+			name: "dictionary over the pixel cap",
+			objects: []pdfObject{image(2, 64), {
+				num:    3,
+				dict:   "<< /Type /XObject /Subtype /Image /Width 60000 /Height 60000 /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /FlateDecode >>",
+				stream: gray(64),
+			}},
+			wantOK:    true,
+			wantWidth: 64,
+		},
+		{
+			name: "jpeg header over the pixel cap",
+			objects: []pdfObject{{
+				num:    2,
+				dict:   "<< /Type /XObject /Subtype /Image /Width 64 /Height 64 /ColorSpace /DeviceGray /BitsPerComponent 8 /Filter /DCTDecode >>",
+				stream: hugeJPEG,
+			}},
+		},
+		{
+			name:    "no images",
+			objects: []pdfObject{{num: 2, dict: "<< /Type /Page >>"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			objects := append([]pdfObject{{num: 1, dict: "<< /Type /Catalog >>"}}, tt.objects...)
+			img, ok, err := LargestImage(buildPDF("<< /Root 1 0 R >>", objects...))
+			if err != nil {
+				t.Fatalf("LargestImage: %v", err)
+			}
+			if ok != tt.wantOK {
+				t.Fatalf("LargestImage ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && img.Bounds().Dx() != tt.wantWidth {
+				t.Fatalf("image width = %d, want %d", img.Bounds().Dx(), tt.wantWidth)
+			}
+		})
+	}
+}
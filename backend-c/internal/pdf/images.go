@@ -0,0 +1,206 @@
+package pdf
+
+import (
+	"bytes"
+	"image"
+	"image/jpeg"
+	"sort"
+	"strconv"
+
+	"department-eduvault-backend/internal/imagehash"
+)
+
+// minImageSide skips logos, icons and other small decorations.
+const minImageSide = 64
+
+// LargestImage returns the biggest embedded raster image of a PDF, which for scanned or
+// screenshot certificates is the certificate itself. ok is false when the PDF has no
+// decodable image (for example, purely vector documents).
+func LargestImage(data []byte) (img image.Image, ok bool, err error) {
+	doc, err := Parse(data)
+	if err != nil {
+		return nil, false, err
+	}
+	images := doc.Images()
+	if len(images) == 0 {
+		return nil, false, nil
+	}
+	return images[0], true, nil
+}
+
+// Images decodes the document's embedded images, largest first. JPEG (DCTDecode) images and
+// 8-bit Flate-compressed gray, RGB and CMYK samples are supported; other encodings, and images
+// over imagehash.MaxPixels by their dictionary or JPEG header, are skipped.
+func (d *Document) Images() []image.Image {
+	// Soft masks are images too, but only carry transparency.
+	masks := make(map[int]bool)
+	for _, num := range d.order {
+		if value, ok := dictEntry(d.objects[num].dict, "SMask"); ok {
+			if m := refPattern.FindSubmatch(bytes.TrimSpace(value)); m != nil {
+				n, _ := strconv.Atoi(string(m[1]))
+				masks[n] = true
+			}
+		}
+	}
+
+	var images []image.Image
+	for _, num := range d.order {
+		obj := d.objects[num]
+		if obj.stream == nil || masks[num] || !dictHasName(obj.dict, "Subtype", "Image") {
+			continue
+		}
+		width, _ := d.dictIntResolved(obj.dict, "Width")
+		height, _ := d.dictIntResolved(obj.dict, "Height")
+		if width < minImageSide || height < minImageSide || width > imagehash.MaxPixels/height {
+			continue
+		}
+		if img := d.decodeImage(obj, width, height); img != nil {
+			images = append(images, img)
+		}
+	}
+	sort.SliceStable(images, func(i, j int) bool {
+		bi, bj := images[i].Bounds(), images[j].Bounds()
+		return bi.Dx()*bi.Dy() > bj.Dx()*bj.Dy()
+	})
+	return images
+}
+
+func (d *Document) decodeImage(obj *object, width, height int) image.Image {
+	filters := streamFilters(obj.dict)
+	data, err := d.decodeStream(obj)
+	if err != nil {
+		return nil
+	}
+
+	if len(filters) > 0 && (filters[len(filters)-1] == "DCTDecode" || filters[len(filters)-1] == "DCT") {
+		// The JPEG header may disagree with the dictionary, so it is checked on its own.
+		cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+		if err != nil || cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width > imagehash.MaxPixels/cfg.Height {
+			return nil
+		}
+		img, err := jpeg.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil
+		}
+		return img
+	}
+	for _, f := range filters {
+		if f != "FlateDecode" && f != "Fl" && f != "ASCII85Decode" && f != "A85" && f != "ASCIIHexDecode" && f != "AHx" {
+			return nil
+		}
+	}
+
+	if bpc, ok := d.dictIntResolved(obj.dict, "BitsPerComponent"); !ok || bpc != 8 {
+		return nil
+	}
+	if _, indexed := dictEntry(obj.dict, "Indexed"); indexed {
+		return nil
+	}
+
+	if predictor, ok := dictInt(obj.dict, "Predictor"); ok && predictor >= 10 {
+		colors, ok := dictInt(obj.dict, "Colors")
+		if !ok {
+			colors = colorComponents(obj.dict)
+		}
+		if data = unpredictPNG(data, width, colors); data == nil {
+			return nil
+		}
+	}
+
+	pixels := width * height
+	if pixels == 0 || len(data)%pixels != 0 {
+		return nil
+	}
+	switch components := len(data) / pixels; components {
+	case 1:
+		return &image.Gray{Pix: data[:pixels], Stride: width, Rect: image.Rect(0, 0, width, height)}
+	case 3:
+		img := image.NewRGBA(image.Rect(0, 0, width, height))
+		for i := 0; i < pixels; i++ {
+			img.Pix[4*i], img.Pix[4*i+1], img.Pix[4*i+2], img.Pix[4*i+3] = data[3*i], data[3*i+1], data[3*i+2], 0xFF
+		}
+		return img
+	case 4:
+		img := image.NewCMYK(image.Rect(0, 0, width, height))
+		copy(img.Pix, data)
+		return img
+	default:
+		return nil
+	}
+}
+
+// colorComponents infers samples per pixel from a device color space; 1 is the PDF default
+// for predictor Colors.
+func colorComponents(dict []byte) int {
+	switch dictName(dict, "ColorSpace") {
+	case "DeviceRGB", "CalRGB":
+		return 3
+	case "DeviceCMYK":
+		return 4
+	default:
+		return 1
+	}
+}
+
+// unpredictPNG reverses PNG row filters (Predictor >= 10) for 8-bit samples.
+func unpredictPNG(data []byte, columns, colors int) []byte {
+	if colors <= 0 {
+		return nil
+	}
+	rowLen := columns * colors
+	if rowLen == 0 || len(data)%(rowLen+1) != 0 {
+		return nil
+	}
+	rows := len(data) / (rowLen + 1)
+	out := make([]byte, rows*rowLen)
+	prev := make([]byte, rowLen)
+	for r := 0; r < rows; r++ {
+		filter := data[r*(rowLen+1)]
+		src := data[r*(rowLen+1)+1 : (r+1)*(rowLen+1)]
+		row := out[r*rowLen : (r+1)*rowLen]
+		for i := range row {
+			var left, upLeft byte
+			if i >= colors {
+				left = row[i-colors]
+				upLeft = prev[i-colors]
+			}
+			up := prev[i]
+			switch filter {
+			case 0:
+				row[i] = src[i]
+			case 1:
+				row[i] = src[i] + left
+			case 2:
+				row[i] = src[i] + up
+			case 3:
+				row[i] = src[i] + byte((int(left)+int(up))/2)
+			case 4:
+				row[i] = src[i] + paeth(left, up, upLeft)
+			default:
+				return nil
+			}
+		}
+		prev = row
+	}
+	return out
+}
+
+func paeth(a, b, c byte) byte {
+	p := int(a) + int(b) - int(c)
+	pa, pb, pc := abs(p-int(a)), abs(p-int(b)), abs(p-int(c))
+	switch {
+	case pa <= pb && pa <= pc:
+		return a
+	case pb <= pc:
+		return b
+	default:
+		return c
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
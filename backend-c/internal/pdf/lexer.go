@@ -0,0 +1,233 @@
+package pdf
+
+import "bytes"
+
+type tokenKind int
+
+const (
+	tokNumber tokenKind = iota
+	tokString
+	tokName
+	tokArray
+	tokDict
+	tokOperator
+)
+
+// token is one content-stream or CMap token. Strings carry their decoded bytes in raw;
+// arrays carry their elements in items.
+type token struct {
+	kind  tokenKind
+	text  string
+	raw   []byte
+	items []token
+}
+
+// lexer tokenizes PDF content streams and CMaps.
+type lexer struct {
+	data []byte
+	pos  int
+}
+
+// next returns the next token, or false at the end of the data. Array brackets are folded
+// into a single tokArray token; dictionaries are skipped as a single tokDict token.
+func (l *lexer) next() (token, bool) {
+	l.skipSpace()
+	if l.pos >= len(l.data) {
+		return token{}, false
+	}
+
+	c := l.data[l.pos]
+	switch {
+	case c == '(':
+		return token{kind: tokString, raw: l.literalString()}, true
+	case c == '<' && l.peek(1) == '<':
+		l.skipDict()
+		return token{kind: tokDict}, true
+	case c == '<':
+		return token{kind: tokString, raw: l.hexString()}, true
+	case c == '/':
+		l.pos++
+		start := l.pos
+		for l.pos < len(l.data) && !isDelimiter(l.data[l.pos]) {
+			l.pos++
+		}
+		return token{kind: tokName, text: string(l.data[start:l.pos])}, true
+	case c == '[':
+		l.pos++
+		var items []token
+		for {
+			l.skipSpace()
+			if l.pos >= len(l.data) {
+				break
+			}
+			if l.data[l.pos] == ']' {
+				l.pos++
+				break
+			}
+			item, ok := l.next()
+			if !ok {
+				break
+			}
+			items = append(items, item)
+		}
+		return token{kind: tokArray, items: items}, true
+	case c == ']' || c == '>' || c == ')' || c == '{' || c == '}':
+		l.pos++
+		return l.next()
+	case c == '+' || c == '-' || c == '.' || (c >= '0' && c <= '9'):
+		start := l.pos
+		l.pos++
+		for l.pos < len(l.data) && (l.data[l.pos] == '.' || (l.data[l.pos] >= '0' && l.data[l.pos] <= '9')) {
+			l.pos++
+		}
+		return token{kind: tokNumber, text: string(l.data[start:l.pos])}, true
+	default:
+		start := l.pos
+		for l.pos < len(l.data) && !isDelimiter(l.data[l.pos]) {
+			l.pos++
+		}
+		if l.pos == start {
+			l.pos++
+		}
+		return token{kind: tokOperator, text: string(l.data[start:l.pos])}, true
+	}
+}
+
+func (l *lexer) peek(offset int) byte {
+	if l.pos+offset < len(l.data) {
+		return l.data[l.pos+offset]
+	}
+	return 0
+}
+
+func (l *lexer) skipSpace() {
+	for l.pos < len(l.data) {
+		switch l.data[l.pos] {
+		case ' ', '\t', '\r', '\n', '\f', 0:
+			l.pos++
+		case '%':
+			for l.pos < len(l.data) && l.data[l.pos] != '\n' && l.data[l.pos] != '\r' {
+				l.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (l *lexer) skipDict() {
+	depth := 0
+	for l.pos+1 < len(l.data) {
+		switch {
+		case l.data[l.pos] == '<' && l.data[l.pos+1] == '<':
+			depth++
+			l.pos += 2
+		case l.data[l.pos] == '>' && l.data[l.pos+1] == '>':
+			depth--
+			l.pos += 2
+			if depth == 0 {
+				return
+			}
+		case l.data[l.pos] == '(':
+			l.literalString()
+		default:
+			l.pos++
+		}
+	}
+	l.pos = len(l.data)
+}
+
+// literalString reads a (...) string with nested parentheses and escape sequences.
+func (l *lexer) literalString() []byte {
+	l.pos++ // opening parenthesis
+	var out []byte
+	depth := 1
+	for l.pos < len(l.data) {
+		c := l.data[l.pos]
+		l.pos++
+		switch c {
+		case '(':
+			depth++
+			out = append(out, c)
+		case ')':
+			depth--
+			if depth == 0 {
+				return out
+			}
+			out = append(out, c)
+		case '\\':
+			if l.pos >= len(l.data) {
+				return out
+			}
+			e := l.data[l.pos]
+			l.pos++
+			switch e {
+			case 'n':
+				out = append(out, '\n')
+			case 'r':
+				out = append(out, '\r')
+			case 't':
+				out = append(out, '\t')
+			case 'b':
+				out = append(out, '\b')
+			case 'f':
+				out = append(out, '\f')
+			case '\r':
+				// Line continuation.
+				if l.pos < len(l.data) && l.data[l.pos] == '\n' {
+					l.pos++
+				}
+			case '\n':
+			default:
+				if e >= '0' && e <= '7' {
+					val := int(e - '0')
+					for i := 0; i < 2 && l.pos < len(l.data) && l.data[l.pos] >= '0' && l.data[l.pos] <= '7'; i++ {
+						val = val*8 + int(l.data[l.pos]-'0')
+						l.pos++
+					}
+					out = append(out, byte(val))
+				} else {
+					out = append(out, e)
+				}
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (l *lexer) hexString() []byte {
+	l.pos++ // opening angle bracket
+	end := bytes.IndexByte(l.data[l.pos:], '>')
+	if end < 0 {
+		end = len(l.data) - l.pos
+	}
+	raw, _ := decodeASCIIHex(l.data[l.pos : l.pos+end])
+	l.pos += end + 1
+	return raw
+}
+
+// skipInlineImage moves past the binary data of an inline image (BI ... ID data EI).
+func (l *lexer) skipInlineImage() {
+	if l.pos < len(l.data) {
+		l.pos++ // single whitespace after ID
+	}
+	for l.pos+2 < len(l.data) {
+		if isWhite(l.data[l.pos]) && l.data[l.pos+1] == 'E' && l.data[l.pos+2] == 'I' &&
+			(l.pos+3 >= len(l.data) || isDelimiter(l.data[l.pos+3])) {
+			l.pos += 3
+			return
+		}
+		l.pos++
+	}
+	l.pos = len(l.data)
+}
+
+func isWhite(c byte) bool {
+	switch c {
+	case ' ', '\t', '\r', '\n', '\f', 0:
+		return true
+	}
+	return false
+}
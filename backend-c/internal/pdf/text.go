@@ -0,0 +1,421 @@
+package pdf
+
+import (
+	"bytes"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// maxFormDepth limits how deeply nested form XObjects are followed.
+const maxFormDepth = 8
+
+var refListPattern = regexp.MustCompile(`\d+\s+\d+\s+R\b`)
+
+// font decodes string operands shown with a font. Fonts with a ToUnicode CMap are decoded
+// through it; simple fonts without one fall back to Latin-1.
+type font struct {
+	toUnicode map[uint32]string
+	codeBytes int
+}
+
+// resources are the fonts and form XObjects a content stream may reference by name.
+type resources struct {
+	fonts map[string]*font
+	forms map[string]int
+}
+
+// ExtractText returns the text shown on every page, in content-stream order. Pages are
+// separated by blank lines and text positioned on a new line starts a new line. Text drawn
+// as outlines or embedded in images (scanned certificates) cannot be recovered.
+func ExtractText(data []byte) (string, error) {
+	doc, err := Parse(data)
+	if err != nil {
+		return "", err
+	}
+	return doc.Text(), nil
+}
+
+// Text extracts the text of every page.
+func (d *Document) Text() string {
+	var out strings.Builder
+	fontCache := make(map[int]*font)
+
+	pages := d.pages()
+	for _, page := range pages {
+		res := d.resources(d.inheritedResources(page), fontCache)
+		for _, content := range d.pageContents(page) {
+			d.showText(&out, content, res, fontCache, 0)
+			out.WriteString("\n")
+		}
+		out.WriteString("\n")
+	}
+
+	// Without a recognizable page tree, fall back to every stream that draws text.
+	if len(pages) == 0 {
+		res := d.allResources(fontCache)
+		for _, num := range d.order {
+			obj := d.objects[num]
+			if obj.stream == nil {
+				continue
+			}
+			content, err := d.decodeStream(obj)
+			if err != nil || !bytes.Contains(content, []byte("BT")) {
+				continue
+			}
+			d.showText(&out, content, res, fontCache, maxFormDepth)
+			out.WriteString("\n")
+		}
+	}
+	return strings.TrimSpace(out.String())
+}
+
+// pages returns the page dictionaries in object order.
+func (d *Document) pages() [][]byte {
+	var pages [][]byte
+	for _, num := range d.order {
+		obj := d.objects[num]
+		if dictHasName(obj.dict, "Type", "Page") {
+			pages = append(pages, obj.dict)
+		}
+	}
+	return pages
+}
+
+// inheritedResources returns the page's /Resources value, walking up /Parent when the page
+// inherits it from the page tree.
+func (d *Document) inheritedResources(page []byte) []byte {
+	node := page
+	for depth := 0; depth < 32 && node != nil; depth++ {
+		if value, ok := dictEntry(node, "Resources"); ok {
+			if dict, ok := d.resolveDict(value); ok {
+				return dict
+			}
+		}
+		parentRef, ok := dictEntry(node, "Parent")
+		if !ok {
+			return nil
+		}
+		parent, ok := d.resolve(parentRef)
+		if !ok {
+			return nil
+		}
+		node = parent.dict
+	}
+	return nil
+}
+
+func (d *Document) pageContents(page []byte) [][]byte {
+	value, ok := dictEntry(page, "Contents")
+	if !ok {
+		return nil
+	}
+	value = bytes.TrimSpace(value)
+
+	var refs [][]byte
+	if bytes.HasPrefix(value, []byte("[")) {
+		end := bytes.IndexByte(value, ']')
+		if end < 0 {
+			return nil
+		}
+		for _, m := range refListPattern.FindAll(value[1:end], -1) {
+			refs = append(refs, m)
+		}
+	} else {
+		refs = append(refs, value)
+	}
+
+	var contents [][]byte
+	for _, ref := range refs {
+		obj, ok := d.resolve(ref)
+		if !ok || obj.stream == nil {
+			continue
+		}
+		if content, err := d.decodeStream(obj); err == nil {
+			contents = append(contents, content)
+		}
+	}
+	return contents
+}
+
+// resources loads the fonts and form XObjects named in a /Resources dictionary.
+func (d *Document) resources(dict []byte, fontCache map[int]*font) resources {
+	res := resources{fonts: make(map[string]*font), forms: make(map[string]int)}
+	if dict == nil {
+		return res
+	}
+	if value, ok := dictEntry(dict, "Font"); ok {
+		if fonts, ok := d.resolveDict(value); ok {
+			for name, num := range namedRefs(fonts) {
+				res.fonts[name] = d.loadFont(num, fontCache)
+			}
+		}
+	}
+	if value, ok := dictEntry(dict, "XObject"); ok {
+		if xobjects, ok := d.resolveDict(value); ok {
+			for name, num := range namedRefs(xobjects) {
+				if obj, ok := d.objects[num]; ok && dictHasName(obj.dict, "Subtype", "Form") {
+					res.forms[name] = num
+				}
+			}
+		}
+	}
+	return res
+}
+
+// allResources merges every font in the document; used when no page tree is available.
+func (d *Document) allResources(fontCache map[int]*font) resources {
+	res := resources{fonts: make(map[string]*font), forms: make(map[string]int)}
+	for _, num := range d.order {
+		dict := d.objects[num].dict
+		value, ok := dictEntry(dict, "Font")
+		if !ok {
+			continue
+		}
+		if fonts, ok := d.resolveDict(value); ok {
+			for name, ref := range namedRefs(fonts) {
+				res.fonts[name] = d.loadFont(ref, fontCache)
+			}
+		}
+	}
+	return res
+}
+
+func (d *Document) loadFont(num int, cache map[int]*font) *font {
+	if f, ok := cache[num]; ok {
+		return f
+	}
+	f := &font{codeBytes: 1}
+	cache[num] = f
+
+	obj, ok := d.objects[num]
+	if !ok {
+		return f
+	}
+	if dictHasName(obj.dict, "Subtype", "Type0") {
+		f.codeBytes = 2
+	}
+	if value, ok := dictEntry(obj.dict, "ToUnicode"); ok {
+		if cmapObj, ok := d.resolve(value); ok && cmapObj.stream != nil {
+			if cmap, err := d.decodeStream(cmapObj); err == nil {
+				f.toUnicode, f.codeBytes = parseToUnicode(cmap, f.codeBytes)
+			}
+		}
+	}
+	return f
+}
+
+// showText interprets a content stream and writes the strings it shows.
+func (d *Document) showText(out *strings.Builder, content []byte, res resources, fontCache map[int]*font, depth int) {
+	var current *font
+	var operands []token
+
+	lex := lexer{data: content}
+	for {
+		tok, ok := lex.next()
+		if !ok {
+			return
+		}
+		if tok.kind != tokOperator {
+			operands = append(operands, tok)
+			continue
+		}
+
+		switch tok.text {
+		case "Tf":
+			if len(operands) >= 2 && operands[len(operands)-2].kind == tokName {
+				current = res.fonts[operands[len(operands)-2].text]
+			}
+		case "Tj":
+			if s, ok := lastString(operands); ok {
+				out.WriteString(current.decode(s))
+			}
+		case "'", "\"":
+			out.WriteString("\n")
+			if s, ok := lastString(operands); ok {
+				out.WriteString(current.decode(s))
+			}
+		case "TJ":
+			if len(operands) > 0 && operands[len(operands)-1].kind == tokArray {
+				for _, el := range operands[len(operands)-1].items {
+					switch el.kind {
+					case tokString:
+						out.WriteString(current.decode(el.raw))
+					case tokNumber:
+						// Large negative adjustments are word gaps (units of 1/1000 em).
+						if n, err := strconv.ParseFloat(el.text, 64); err == nil && n < -250 {
+							out.WriteString(" ")
+						}
+					}
+				}
+			}
+		case "Td", "TD":
+			if len(operands) >= 2 && !isZero(operands[len(operands)-1].text) {
+				out.WriteString("\n")
+			} else {
+				out.WriteString(" ")
+			}
+		case "T*", "Tm", "ET":
+			out.WriteString("\n")
+		case "Do":
+			if depth >= maxFormDepth || len(operands) == 0 {
+				break
+			}
+			num, ok := res.forms[operands[len(operands)-1].text]
+			if !ok {
+				break
+			}
+			form := d.objects[num]
+			formContent, err := d.decodeStream(form)
+			if err != nil {
+				break
+			}
+			formRes := res
+			if value, ok := dictEntry(form.dict, "Resources"); ok {
+				if dict, ok := d.resolveDict(value); ok {
+					formRes = d.resources(dict, fontCache)
+				}
+			}
+			d.showText(out, formContent, formRes, fontCache, depth+1)
+		case "ID":
+			lex.skipInlineImage()
+		}
+		operands = operands[:0]
+	}
+}
+
+func isZero(number string) bool {
+	n, err := strconv.ParseFloat(number, 64)
+	return err == nil && n == 0
+}
+
+func lastString(operands []token) ([]byte, bool) {
+	if len(operands) == 0 || operands[len(operands)-1].kind != tokString {
+		return nil, false
+	}
+	return operands[len(operands)-1].raw, true
+}
+
+// decode maps a shown string's character codes to text.
+func (f *font) decode(s []byte) string {
+	if f == nil || f.toUnicode == nil {
+		if f != nil && f.codeBytes == 2 {
+			// Composite font without a ToUnicode map: the codes are glyph IDs.
+			return ""
+		}
+		if len(s) >= 2 && s[0] == 0xFE && s[1] == 0xFF {
+			return decodeUTF16(s[2:])
+		}
+		return latin1(s)
+	}
+
+	var out strings.Builder
+	for i := 0; i+f.codeBytes <= len(s); i += f.codeBytes {
+		var code uint32
+		for _, b := range s[i : i+f.codeBytes] {
+			code = code<<8 | uint32(b)
+		}
+		if text, ok := f.toUnicode[code]; ok {
+			out.WriteString(text)
+		}
+	}
+	return out.String()
+}
+
+// parseToUnicode reads the bfchar and bfrange mappings of a ToUnicode CMap. The code width is
+// taken from the codespace range, falling back to the font's default.
+func parseToUnicode(cmap []byte, defaultBytes int) (map[uint32]string, int) {
+	mapping := make(map[uint32]string)
+	codeBytes := defaultBytes
+
+	lex := lexer{data: cmap}
+	var operands []token
+	mode := ""
+	for {
+		tok, ok := lex.next()
+		if !ok {
+			break
+		}
+		if tok.kind != tokOperator {
+			operands = append(operands, tok)
+			continue
+		}
+		switch tok.text {
+		case "begincodespacerange", "beginbfchar", "beginbfrange":
+			mode = tok.text
+			operands = operands[:0]
+			continue
+		case "endcodespacerange":
+			if len(operands) >= 1 && operands[0].kind == tokString && len(operands[0].raw) > 0 {
+				codeBytes = len(operands[0].raw)
+			}
+			mode = ""
+		case "endbfchar":
+			for i := 0; i+1 < len(operands); i += 2 {
+				if operands[i].kind == tokString && operands[i+1].kind == tokString {
+					mapping[codeOf(operands[i].raw)] = decodeUTF16(operands[i+1].raw)
+				}
+			}
+			mode = ""
+		case "endbfrange":
+			for i := 0; i+2 < len(operands); i += 3 {
+				lo, hi, dst := operands[i], operands[i+1], operands[i+2]
+				if lo.kind != tokString || hi.kind != tokString {
+					continue
+				}
+				start, end := codeOf(lo.raw), codeOf(hi.raw)
+				if end < start || end-start > 0xFFFF {
+					continue
+				}
+				switch dst.kind {
+				case tokString:
+					base := []rune(decodeUTF16(dst.raw))
+					if len(base) == 0 {
+						continue
+					}
+					for code := start; code <= end; code++ {
+						r := append([]rune(nil), base...)
+						r[len(r)-1] += rune(code - start)
+						mapping[code] = string(r)
+					}
+				case tokArray:
+					for j, item := range dst.items {
+						if item.kind == tokString && start+uint32(j) <= end {
+							mapping[start+uint32(j)] = decodeUTF16(item.raw)
+						}
+					}
+				}
+			}
+			mode = ""
+		}
+		if mode == "" {
+			operands = operands[:0]
+		}
+	}
+	return mapping, codeBytes
+}
+
+func codeOf(raw []byte) uint32 {
+	var code uint32
+	for _, b := range raw {
+		code = code<<8 | uint32(b)
+	}
+	return code
+}
+
+func decodeUTF16(raw []byte) string {
+	units := make([]uint16, 0, len(raw)/2)
+	for i := 0; i+1 < len(raw); i += 2 {
+		units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+	}
+	return string(utf16.Decode(units))
+}
+
+func latin1(raw []byte) string {
+	runes := make([]rune, len(raw))
+	for i, b := range raw {
+		runes[i] = rune(b)
+	}
+	return string(runes)
+}
@@ -2,7 +2,9 @@ package router
 
 import (
 	"department-eduvault-backend/controllers"
+	"department-eduvault-backend/internal/config"
 	internalController "department-eduvault-backend/internal/controller"
+	"department-eduvault-backend/internal/drive"
 	internalService "department-eduvault-backend/internal/service"
 	"department-eduvault-backend/middleware"
 	"department-eduvault-backend/repositories"
@@ -15,7 +17,7 @@ import (
 )
 
 // New constructs the HTTP router and wires routes to controllers.
-func New(healthService internalService.HealthService, dashboardService services.DashboardService, adminRepo repositories.AdminRepository, db *gorm.DB, logger *zap.Logger) *gin.Engine {
+func New(cfg *config.Config, healthService internalService.HealthService, dashboardService services.DashboardService, slaService services.ReviewSLAService, statsService services.StatsService, adminRepo repositories.AdminRepository, db *gorm.DB, logger *zap.Logger) *gin.Engine {
 	engine := gin.New()
 	engine.Use(
 		middleware.CORSMiddleware(),
@@ -28,21 +30,47 @@ func New(healthService internalService.HealthService, dashboardService services.
 	engine.GET("/health", healthController.Health)
 
 	dashboardController := controllers.NewDashboardController(dashboardService)
+	leaderboardRepo := repositories.NewLeaderboardRepository(db)
+	leaderboardController := controllers.NewLeaderboardController(services.NewLeaderboardService(leaderboardRepo))
 	dashboard := engine.Group("/dashboard")
 	{
 		dashboard.GET("/overview", dashboardController.GetOverview)
 		dashboard.GET("/sections", dashboardController.GetSections)
+		dashboard.GET("/trends", dashboardController.GetTrends)
+		dashboard.GET("/funnel", dashboardController.GetFunnel)
+		dashboard.GET("/leaderboard",
+			middleware.MockAuthMiddleware("citchennai.net"),
+			middleware.RequireRoles("HOD", "FACULTY"),
+			leaderboardController.GetLeaderboard,
+		)
+		dashboard.GET("/leaderboard/export",
+			middleware.MockAuthMiddleware("citchennai.net"),
+			middleware.RequireRoles("HOD", "FACULTY"),
+			leaderboardController.ExportLeaderboard,
+		)
+		dashboard.GET("/terms", leaderboardController.ListTerms)
 	}
 
-	adminController := controllers.NewAdminController(adminRepo)
+	adminController := controllers.NewAdminController(adminRepo, statsService)
 	admin := engine.Group("/admin")
 	{
 		admin.POST("/seed", adminController.Seed)
+		admin.POST("/stats/reconcile",
+			middleware.MockAuthMiddleware("citchennai.net"),
+			middleware.RequireRoles("HOD"),
+			adminController.ReconcileStats,
+		)
 	}
 
 	// Certificate workflows (faculty & HOD)
 	certRepo := repositories.NewCertificateRepository(db)
-	certService := services.NewCertificateService(certRepo)
+	certRepo.OnMutation(dashboardService.Invalidate)
+	idempotencyRepo := repositories.NewIdempotencyRepository(db, cfg.IdempotencyStaleAfter)
+	mlPolicyRepo := repositories.NewMLPolicyRepository(db)
+	studentRepo := repositories.NewStudentRepository(db)
+	studentRepo.OnMutation(dashboardService.Invalidate)
+	sectionPolicy := services.SectionMismatchPolicy(cfg.UploadSectionMismatch)
+	certService := services.NewCertificateService(certRepo, idempotencyRepo, mlPolicyRepo, studentRepo, sectionPolicy, cfg.ReviewLeaseDuration)
 	certController := controllers.NewCertificateController(certService)
 
 	certificates := engine.Group("/certificates")
@@ -51,6 +79,31 @@ func New(healthService internalService.HealthService, dashboardService services.
 		certificates.POST("/upload", certController.UploadCertificates)
 		certificates.GET("/pending-review", certController.GetPendingReview)
 		certificates.POST("/review", certController.SubmitReview)
+		certificates.POST("/:id/claim", certController.ClaimCertificate)
+		certificates.DELETE("/:id/claim", certController.ReleaseClaim)
+	}
+
+	// Automated checks on certificate files (Faculty/HOD)
+	signalRepo := repositories.NewVerificationSignalRepository(db)
+	imageHashRepo := repositories.NewImageHashRepository(db)
+	driveClient := drive.NewClient(cfg.DriveFetchTimeout, drive.DefaultMaxFileBytes)
+	verificationService := services.NewVerificationService(certRepo, signalRepo, imageHashRepo, driveClient)
+	verificationController := controllers.NewVerificationController(verificationService)
+
+	certificates.POST("/:id/text-verification", middleware.RequireRoles("FACULTY", "HOD"), verificationController.VerifyText)
+	certificates.POST("/:id/image-hash", middleware.RequireRoles("FACULTY", "HOD"), verificationController.HashImage)
+
+	// Spreadsheet bulk import (Faculty; HOD may poll)
+	importJobRepo := repositories.NewImportJobRepository(db)
+	importService := services.NewImportService(importJobRepo, certRepo, certService, studentRepo, sectionPolicy)
+	importController := controllers.NewImportController(importService)
+
+	imports := certificates.Group("/import")
+	{
+		imports.POST("", middleware.RequireRoles("FACULTY"), importController.ImportCertificates)
+		imports.GET("/template", middleware.RequireRoles("FACULTY", "HOD"), importController.DownloadImportTemplate)
+		imports.GET("/:id", middleware.RequireRoles("FACULTY", "HOD"), importController.GetImportJob)
+		imports.GET("/:id/errors", middleware.RequireRoles("FACULTY", "HOD"), importController.DownloadImportErrors)
 	}
 
 	// Verify endpoint (Faculty/HOD)
@@ -63,6 +116,11 @@ func New(healthService internalService.HealthService, dashboardService services.
 	hodRepo := repositories.NewHodRepository(db)
 	hodService := services.NewHodService(hodRepo)
 	hodController := controllers.NewHodController(hodService)
+	slaController := controllers.NewReviewSLAController(slaService)
+	mlPolicyController := controllers.NewMLPolicyController(services.NewMLPolicyService(mlPolicyRepo))
+	quotaController := controllers.NewQuotaController(
+		services.NewQuotaService(repositories.NewQuotaRepository(db), leaderboardRepo),
+	)
 
 	hod := engine.Group("/hod")
 	hod.Use(
@@ -75,7 +133,66 @@ func New(healthService internalService.HealthService, dashboardService services.
 		hod.GET("/student/certificates", hodController.ListStudentCertificates)
 		hod.GET("/export/certificates/section", hodController.ExportCertificatesBySection)
 		hod.GET("/export/certificates/student", hodController.ExportCertificatesByStudent)
+		hod.GET("/faculty-stats", hodController.GetFacultyStats)
+		hod.GET("/export/faculty-stats", hodController.ExportFacultyStats)
+		hod.GET("/sla-policies", slaController.ListPolicies)
+		hod.PUT("/sla-policies", slaController.SetPolicy)
+		hod.DELETE("/sla-policies/:id", slaController.DeletePolicy)
+		hod.GET("/review-ageing", slaController.GetAgeingReport)
+		hod.GET("/escalations", slaController.ListEscalations)
+		hod.GET("/ml-policy", mlPolicyController.GetActivePolicy)
+		hod.GET("/ml-policy/versions", mlPolicyController.ListPolicyVersions)
+		hod.POST("/ml-policy", mlPolicyController.CreatePolicyVersion)
+		hod.GET("/near-duplicates", verificationController.ListNearDuplicates)
+		hod.POST("/academic-terms", leaderboardController.CreateTerm)
+		hod.GET("/category-points", leaderboardController.ListCategoryPoints)
+		hod.PUT("/category-points", leaderboardController.SetCategoryPoints)
+		hod.GET("/quota-rules", quotaController.ListRules)
+		hod.PUT("/quota-rules", quotaController.SetRule)
+		hod.DELETE("/quota-rules/:id", quotaController.DeleteRule)
+		hod.GET("/compliance", quotaController.GetCompliance)
+		hod.GET("/export/compliance", quotaController.ExportCompliance)
+	}
+
+	// Student roster (HOD, assigned faculty) and records (also the student)
+	studentController := controllers.NewStudentController(services.NewStudentService(studentRepo))
+	rosterImportRepo := repositories.NewRosterImportRepository(db)
+	rosterImportRepo.OnMutation(dashboardService.Invalidate)
+	rosterImportController := controllers.NewRosterImportController(services.NewRosterImportService(rosterImportRepo, studentRepo))
+	students := engine.Group("/students")
+	students.Use(
+		middleware.MockAuthMiddleware("citchennai.net"),
+		middleware.RequireRoles("HOD", "FACULTY", "STUDENT"),
+	)
+	{
+		students.GET("", middleware.RequireRoles("HOD", "FACULTY"), studentController.ListStudents)
+		students.POST("", middleware.RequireRoles("HOD", "FACULTY"), studentController.CreateStudent)
+		students.POST("/imports", middleware.RequireRoles("HOD"), rosterImportController.PreviewImport)
+		students.GET("/imports", middleware.RequireRoles("HOD"), rosterImportController.ListImports)
+		students.GET("/imports/:id", middleware.RequireRoles("HOD"), rosterImportController.GetImport)
+		students.POST("/imports/:id/confirm", middleware.RequireRoles("HOD"), rosterImportController.ConfirmImport)
+		students.PATCH("/:reg_no", middleware.RequireRoles("HOD", "FACULTY"), studentController.UpdateStudent)
+		students.DELETE("/:reg_no", middleware.RequireRoles("HOD", "FACULTY"), studentController.DeleteStudent)
+		students.POST("/:reg_no/move-section", middleware.RequireRoles("HOD", "FACULTY"), studentController.MoveSection)
+		students.GET("/:reg_no/summary", studentController.GetSummary)
+		students.GET("/:reg_no/enrollments", studentController.ListEnrollments)
 	}
+	engine.GET("/sections/:section/roster",
+		middleware.MockAuthMiddleware("citchennai.net"),
+		middleware.RequireRoles("HOD", "FACULTY"),
+		studentController.GetSectionRoster,
+	)
+
+	// Search (Faculty/HOD)
+	searchRepo := repositories.NewSearchRepository(db)
+	searchService := services.NewSearchService(searchRepo)
+	searchController := controllers.NewSearchController(searchService)
+
+	engine.GET("/search",
+		middleware.MockAuthMiddleware("citchennai.net"),
+		middleware.RequireRoles("HOD", "FACULTY"),
+		searchController.Search,
+	)
 
 	return engine
 }
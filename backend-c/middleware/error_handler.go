@@ -27,13 +27,17 @@ func ErrorHandler(logger *zap.Logger) gin.HandlerFunc {
 			zap.String("code", appErr.Code),
 		)
 
-		c.JSON(appErr.Status, gin.H{
+		body := gin.H{
 			"success": false,
 			"error": gin.H{
 				"code":    appErr.Code,
 				"message": appErr.Message,
 			},
-		})
+		}
+		if appErr.Items != nil {
+			body["items"] = appErr.Items
+		}
+		c.JSON(appErr.Status, body)
 		c.Abort()
 	}
 }
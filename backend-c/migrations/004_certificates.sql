@@ -0,0 +1,20 @@
+-- Certificates (Supabase schema: uuid id, reg_no, faculty_id, uploaded_at, enum statuses, archived)
+
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS ml_score NUMERIC(5,2);
+
+-- Pending faculty review queue: keyset pagination over (uploaded_at, id).
+CREATE INDEX IF NOT EXISTS idx_certificates_pending_review
+    ON certificates (uploaded_at, id)
+    WHERE ml_status = 'VERIFIED' AND faculty_status = 'PENDING' AND archived = false;
+
+CREATE INDEX IF NOT EXISTS idx_certificates_pending_review_section
+    ON certificates (section, uploaded_at, id)
+    WHERE ml_status = 'VERIFIED' AND faculty_status = 'PENDING' AND archived = false;
+
+CREATE INDEX IF NOT EXISTS idx_certificates_pending_review_uploader
+    ON certificates (faculty_id, uploaded_at, id)
+    WHERE ml_status = 'VERIFIED' AND faculty_status = 'PENDING' AND archived = false;
+
+CREATE INDEX IF NOT EXISTS idx_certificates_pending_review_score
+    ON certificates (ml_score, uploaded_at, id)
+    WHERE ml_status = 'VERIFIED' AND faculty_status = 'PENDING' AND archived = false;
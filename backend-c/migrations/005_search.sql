@@ -0,0 +1,33 @@
+-- Full-text and fuzzy search over students and certificates
+
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS title TEXT NOT NULL DEFAULT '';
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS issuer TEXT NOT NULL DEFAULT '';
+
+-- Google Drive file ID from either /file/d/<id>/ or ?id=<id> links.
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS drive_file_id TEXT
+    GENERATED ALWAYS AS (
+        COALESCE(
+            substring(drive_link from '/d/([A-Za-z0-9_-]+)'),
+            substring(drive_link from '[?&]id=([A-Za-z0-9_-]+)')
+        )
+    ) STORED;
+
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS search_vector tsvector
+    GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', coalesce(student_name, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(reg_no, '')), 'A') ||
+        setweight(to_tsvector('simple', coalesce(title, '')), 'B') ||
+        setweight(to_tsvector('simple', coalesce(issuer, '')), 'C')
+    ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_certificates_search_vector ON certificates USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_certificates_student_name_trgm ON certificates USING GIN (student_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_certificates_reg_no_trgm ON certificates USING GIN (reg_no gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_certificates_title_trgm ON certificates USING GIN (title gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_certificates_issuer_trgm ON certificates USING GIN (issuer gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_certificates_drive_file_id_trgm ON certificates USING GIN (drive_file_id gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_students_name_trgm ON students USING GIN (name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS idx_students_register_number_trgm ON students USING GIN (register_number gin_trgm_ops);
@@ -0,0 +1,12 @@
+-- Idempotency-Key records for retried certificate uploads
+
+CREATE TABLE IF NOT EXISTS idempotency_keys (
+    actor_email     TEXT NOT NULL,
+    key             TEXT NOT NULL,
+    request_hash    TEXT NOT NULL,
+    response_status INT,
+    response_body   JSONB,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    completed_at    TIMESTAMPTZ,
+    PRIMARY KEY (actor_email, key)
+);
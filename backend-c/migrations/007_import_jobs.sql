@@ -0,0 +1,29 @@
+-- Background spreadsheet imports and their per-row validation errors
+
+CREATE TABLE IF NOT EXISTS import_jobs (
+    id             UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    kind           TEXT NOT NULL,
+    status         TEXT NOT NULL DEFAULT 'QUEUED',   -- QUEUED | RUNNING | COMPLETED | FAILED
+    file_name      TEXT NOT NULL,
+    created_by     TEXT NOT NULL,
+    total_rows     INT NOT NULL DEFAULT 0,
+    valid_rows     INT NOT NULL DEFAULT 0,
+    invalid_rows   INT NOT NULL DEFAULT 0,
+    processed_rows INT NOT NULL DEFAULT 0,
+    error          TEXT NOT NULL DEFAULT '',
+    created_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    started_at     TIMESTAMPTZ,
+    finished_at    TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_jobs_created_by ON import_jobs(created_by, created_at DESC);
+
+CREATE TABLE IF NOT EXISTS import_job_errors (
+    id         BIGSERIAL PRIMARY KEY,
+    job_id     UUID NOT NULL REFERENCES import_jobs(id) ON DELETE CASCADE,
+    row_number INT NOT NULL,
+    field      TEXT NOT NULL DEFAULT '',
+    message    TEXT NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_import_job_errors_job ON import_job_errors(job_id, row_number);
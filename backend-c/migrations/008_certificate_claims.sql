@@ -0,0 +1,10 @@
+-- Time-limited review leases so two reviewers do not work on the same certificate
+
+CREATE TABLE IF NOT EXISTS certificate_claims (
+    certificate_id UUID PRIMARY KEY REFERENCES certificates(id) ON DELETE CASCADE,
+    claimed_by     TEXT NOT NULL,
+    claimed_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    expires_at     TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_certificate_claims_expires_at ON certificate_claims(expires_at);
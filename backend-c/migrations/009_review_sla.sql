@@ -0,0 +1,27 @@
+-- Review SLA thresholds, waiting-time tracking and escalation to the HOD
+
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS category TEXT NOT NULL DEFAULT 'GENERAL';
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS ml_verified_at TIMESTAMPTZ;
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS escalated_at TIMESTAMPTZ;
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS escalation_reason TEXT NOT NULL DEFAULT '';
+
+-- Certificates verified before this migration start their review clock at upload time.
+UPDATE certificates SET ml_verified_at = uploaded_at
+WHERE ml_status = 'VERIFIED' AND ml_verified_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_certificates_escalated
+    ON certificates (escalated_at)
+    WHERE escalated_at IS NOT NULL AND faculty_status = 'PENDING' AND archived = false;
+
+-- NULL section/category match every section/category; the most specific row wins.
+CREATE TABLE IF NOT EXISTS review_sla_policies (
+    id              SERIAL PRIMARY KEY,
+    section         TEXT,
+    category        TEXT,
+    threshold_hours INT NOT NULL CHECK (threshold_hours > 0),
+    updated_by      TEXT NOT NULL,
+    updated_at      TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_review_sla_policies_scope
+    ON review_sla_policies (COALESCE(section, ''), COALESCE(category, ''));
@@ -0,0 +1,20 @@
+-- Versioned score-based auto-decision policy for ML results
+
+CREATE TABLE IF NOT EXISTS ml_decision_policies (
+    version                SERIAL PRIMARY KEY,
+    auto_approve_threshold NUMERIC(5,2) NOT NULL,
+    hod_review_threshold   NUMERIC(5,2) NOT NULL,
+    enabled                BOOLEAN NOT NULL DEFAULT TRUE,
+    created_by             TEXT NOT NULL,
+    created_at             TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (hod_review_threshold >= 0 AND auto_approve_threshold <= 100),
+    CHECK (hod_review_threshold <= auto_approve_threshold)
+);
+
+-- Decision attribution; reviewed_by is the faculty/HOD email or a system actor.
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS reviewed_by TEXT;
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS reviewed_at TIMESTAMPTZ;
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS requires_hod_review BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS decision_policy_version INT REFERENCES ml_decision_policies(version);
+
+CREATE INDEX IF NOT EXISTS idx_certificates_reviewed_by ON certificates(reviewed_by, reviewed_at);
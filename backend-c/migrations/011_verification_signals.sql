@@ -0,0 +1,12 @@
+-- Automated verification signals shown next to certificates in the review queue.
+-- One row per certificate and signal kind; re-running a verifier replaces its row.
+
+CREATE TABLE IF NOT EXISTS certificate_verification_signals (
+    certificate_id UUID NOT NULL REFERENCES certificates(id) ON DELETE CASCADE,
+    kind           TEXT NOT NULL,
+    confidence     NUMERIC(4,3) NOT NULL CHECK (confidence BETWEEN 0 AND 1),
+    details        JSONB NOT NULL DEFAULT '{}'::jsonb,
+    created_by     TEXT NOT NULL,
+    updated_at     TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (certificate_id, kind)
+);
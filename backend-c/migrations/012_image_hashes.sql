@@ -0,0 +1,33 @@
+-- Perceptual (dHash) fingerprints of certificate images for near-duplicate detection.
+
+CREATE TABLE IF NOT EXISTS certificate_image_hashes (
+    certificate_id UUID PRIMARY KEY REFERENCES certificates(id) ON DELETE CASCADE,
+    hash           BIGINT NOT NULL,
+    source         TEXT NOT NULL,
+    created_by     TEXT NOT NULL,
+    computed_at    TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+-- Each 64-bit hash is split into 8 one-byte bands. Hashes within Hamming distance 7 share
+-- at least one band, so candidates are found with an exact (band_no, band_value) lookup
+-- and the precise distance is checked afterwards.
+CREATE TABLE IF NOT EXISTS certificate_image_hash_bands (
+    certificate_id UUID NOT NULL REFERENCES certificate_image_hashes(certificate_id) ON DELETE CASCADE,
+    band_no        SMALLINT NOT NULL,
+    band_value     SMALLINT NOT NULL,
+    PRIMARY KEY (certificate_id, band_no)
+);
+
+CREATE INDEX IF NOT EXISTS idx_image_hash_bands_lookup ON certificate_image_hash_bands(band_no, band_value);
+
+-- Suspicious pairs across different students; certificate_a sorts before certificate_b.
+CREATE TABLE IF NOT EXISTS near_duplicate_pairs (
+    certificate_a UUID NOT NULL REFERENCES certificates(id) ON DELETE CASCADE,
+    certificate_b UUID NOT NULL REFERENCES certificates(id) ON DELETE CASCADE,
+    distance      SMALLINT NOT NULL,
+    detected_at   TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (certificate_a, certificate_b),
+    CHECK (certificate_a < certificate_b)
+);
+
+CREATE INDEX IF NOT EXISTS idx_near_duplicate_pairs_b ON near_duplicate_pairs(certificate_b);
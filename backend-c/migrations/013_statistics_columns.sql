@@ -0,0 +1,79 @@
+-- Statistics rows are now upserted by the application, so every counter the models describe
+-- needs a real column and both tables need a unique key to conflict on.
+
+ALTER TABLE student_statistics ADD COLUMN IF NOT EXISTS student_name TEXT NOT NULL DEFAULT '';
+ALTER TABLE student_statistics ADD COLUMN IF NOT EXISTS section TEXT NOT NULL DEFAULT '';
+ALTER TABLE student_statistics ADD COLUMN IF NOT EXISTS pending_count INT NOT NULL DEFAULT 0;
+ALTER TABLE student_statistics ADD COLUMN IF NOT EXISTS ml_verified_count INT NOT NULL DEFAULT 0;
+ALTER TABLE student_statistics ALTER COLUMN last_updated SET DEFAULT NOW();
+
+ALTER TABLE section_statistics ADD COLUMN IF NOT EXISTS pending_count INT NOT NULL DEFAULT 0;
+ALTER TABLE section_statistics ADD COLUMN IF NOT EXISTS ml_verified_count INT NOT NULL DEFAULT 0;
+ALTER TABLE section_statistics ALTER COLUMN last_updated SET DEFAULT NOW();
+
+-- Duplicate rows would block the unique indexes; their counts are rebuilt below anyway.
+DELETE FROM student_statistics a
+USING student_statistics b
+WHERE a.reg_no = b.reg_no AND a.ctid < b.ctid;
+
+DELETE FROM section_statistics a
+USING section_statistics b
+WHERE a.section = b.section AND a.ctid < b.ctid;
+
+-- Named like the implicit UNIQUE constraint indexes, so existing ones are reused.
+CREATE UNIQUE INDEX IF NOT EXISTS student_statistics_reg_no_key ON student_statistics (reg_no);
+CREATE UNIQUE INDEX IF NOT EXISTS section_statistics_section_key ON section_statistics (section);
+
+-- Backfill every counter from active certificates. Name and section come from the
+-- student's most recent upload.
+WITH actual AS (
+    SELECT
+        reg_no,
+        (array_agg(student_name ORDER BY uploaded_at DESC))[1] AS student_name,
+        (array_agg(section ORDER BY uploaded_at DESC))[1] AS section,
+        COUNT(*) AS total_uploaded,
+        COUNT(*) FILTER (WHERE faculty_status = 'PENDING') AS pending_count,
+        COUNT(*) FILTER (WHERE faculty_status = 'LEGIT') AS legit_count,
+        COUNT(*) FILTER (WHERE faculty_status = 'NOT_LEGIT') AS not_legit_count,
+        COUNT(*) FILTER (WHERE ml_status = 'VERIFIED') AS ml_verified_count
+    FROM certificates
+    WHERE archived = false
+    GROUP BY reg_no
+)
+INSERT INTO student_statistics
+    (reg_no, student_name, section, total_uploaded, pending_count, legit_count, not_legit_count, ml_verified_count, last_updated)
+SELECT reg_no, student_name, section, total_uploaded, pending_count, legit_count, not_legit_count, ml_verified_count, NOW()
+FROM actual
+ON CONFLICT (reg_no) DO UPDATE SET
+    student_name = EXCLUDED.student_name,
+    section = EXCLUDED.section,
+    total_uploaded = EXCLUDED.total_uploaded,
+    pending_count = EXCLUDED.pending_count,
+    legit_count = EXCLUDED.legit_count,
+    not_legit_count = EXCLUDED.not_legit_count,
+    ml_verified_count = EXCLUDED.ml_verified_count,
+    last_updated = NOW();
+
+WITH actual AS (
+    SELECT
+        section,
+        COUNT(*) AS total_uploaded,
+        COUNT(*) FILTER (WHERE faculty_status = 'PENDING') AS pending_count,
+        COUNT(*) FILTER (WHERE faculty_status = 'LEGIT') AS legit_count,
+        COUNT(*) FILTER (WHERE faculty_status = 'NOT_LEGIT') AS not_legit_count,
+        COUNT(*) FILTER (WHERE ml_status = 'VERIFIED') AS ml_verified_count
+    FROM certificates
+    WHERE archived = false
+    GROUP BY section
+)
+INSERT INTO section_statistics
+    (section, total_uploaded, pending_count, legit_count, not_legit_count, ml_verified_count, last_updated)
+SELECT section, total_uploaded, pending_count, legit_count, not_legit_count, ml_verified_count, NOW()
+FROM actual
+ON CONFLICT (section) DO UPDATE SET
+    total_uploaded = EXCLUDED.total_uploaded,
+    pending_count = EXCLUDED.pending_count,
+    legit_count = EXCLUDED.legit_count,
+    not_legit_count = EXCLUDED.not_legit_count,
+    ml_verified_count = EXCLUDED.ml_verified_count,
+    last_updated = NOW();
@@ -0,0 +1,17 @@
+-- Append-only statistics deltas. Uploads, ML verification and reviews insert a row here
+-- instead of incrementing the shared student/section statistics rows, so concurrent writers
+-- never wait on each other's row locks. A background job folds deltas into the statistics
+-- tables in batches; until then, the effective count is the stored row plus its deltas.
+
+CREATE TABLE IF NOT EXISTS stats_deltas (
+    id                BIGSERIAL PRIMARY KEY,
+    reg_no            TEXT NOT NULL,
+    student_name      TEXT NOT NULL DEFAULT '',
+    section           TEXT NOT NULL,
+    total_uploaded    INT NOT NULL DEFAULT 0,
+    pending_count     INT NOT NULL DEFAULT 0,
+    legit_count       INT NOT NULL DEFAULT 0,
+    not_legit_count   INT NOT NULL DEFAULT 0,
+    ml_verified_count INT NOT NULL DEFAULT 0,
+    created_at        TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
@@ -0,0 +1,23 @@
+-- Academic terms and category weights for the student leaderboards.
+
+CREATE TABLE IF NOT EXISTS academic_terms (
+    id         SERIAL PRIMARY KEY,
+    name       TEXT NOT NULL UNIQUE,
+    starts_on  DATE NOT NULL,
+    ends_on    DATE NOT NULL,
+    created_by TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (ends_on >= starts_on)
+);
+
+-- Points awarded per LEGIT certificate of a category. Categories without a row earn 1 point.
+CREATE TABLE IF NOT EXISTS category_points (
+    category   TEXT PRIMARY KEY,
+    points     NUMERIC(6,2) NOT NULL CHECK (points >= 0),
+    updated_by TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_certificates_legit_reg_no
+    ON certificates (reg_no)
+    WHERE faculty_status = 'LEGIT' AND archived = false;
@@ -0,0 +1,12 @@
+-- Department of each certificate, used to slice the dashboards. Existing rows and uploads
+-- that do not name a department keep the empty default.
+
+ALTER TABLE certificates ADD COLUMN IF NOT EXISTS department TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_certificates_department
+    ON certificates (department)
+    WHERE archived = false;
+
+CREATE INDEX IF NOT EXISTS idx_certificates_category
+    ON certificates (category)
+    WHERE archived = false;
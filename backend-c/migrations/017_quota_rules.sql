@@ -0,0 +1,23 @@
+-- Certificate quotas per academic term, scoped by batch and section, and the student batch
+-- (admission year) they are matched against.
+
+ALTER TABLE students ADD COLUMN IF NOT EXISTS batch TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_students_batch ON students(batch);
+
+-- NULL batch/section/category match every batch/section/category. For each student and
+-- category the most specific matching rule applies (section over batch over neither). A
+-- NULL category counts LEGIT certificates of any category.
+CREATE TABLE IF NOT EXISTS quota_rules (
+    id         SERIAL PRIMARY KEY,
+    term_id    INT NOT NULL REFERENCES academic_terms(id) ON DELETE CASCADE,
+    batch      TEXT,
+    section    TEXT,
+    category   TEXT,
+    min_legit  INT NOT NULL CHECK (min_legit > 0),
+    updated_by TEXT NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_quota_rules_scope
+    ON quota_rules (term_id, COALESCE(batch, ''), COALESCE(section, ''), COALESCE(category, ''));
@@ -0,0 +1,10 @@
+-- Students are soft-deleted so their certificates and history keep their roster context.
+-- register_number stays unique across deleted rows; re-adding a deleted student restores it.
+
+ALTER TABLE students ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMPTZ;
+ALTER TABLE students ADD COLUMN IF NOT EXISTS created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE students ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX IF NOT EXISTS idx_students_active_section
+    ON students (section, register_number)
+    WHERE deleted_at IS NULL;
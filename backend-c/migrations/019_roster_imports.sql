@@ -0,0 +1,35 @@
+-- Roster spreadsheet imports: a preview diff stored until confirmed, and the per-student
+-- history of every applied import.
+
+CREATE TABLE IF NOT EXISTS roster_imports (
+    id              UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    status          TEXT NOT NULL DEFAULT 'PENDING',  -- PENDING | APPLIED
+    file_name       TEXT NOT NULL,
+    created_by      TEXT NOT NULL,
+    roster_version  TEXT NOT NULL,                    -- fingerprint of the roster the diff was computed against
+    total_rows      INT NOT NULL DEFAULT 0,
+    invalid_rows    INT NOT NULL DEFAULT 0,
+    added           INT NOT NULL DEFAULT 0,
+    changed         INT NOT NULL DEFAULT 0,
+    missing         INT NOT NULL DEFAULT 0,
+    diff            JSONB NOT NULL DEFAULT '{}'::jsonb,
+    remove_missing  BOOLEAN NOT NULL DEFAULT false,
+    created_at      TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    applied_by      TEXT,
+    applied_at      TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_roster_imports_created_at ON roster_imports(created_at DESC);
+
+CREATE TABLE IF NOT EXISTS roster_changes (
+    id               BIGSERIAL PRIMARY KEY,
+    import_id        UUID NOT NULL REFERENCES roster_imports(id) ON DELETE CASCADE,
+    register_number  TEXT NOT NULL,
+    action           TEXT NOT NULL,                   -- ADDED | UPDATED | REMOVED
+    before           JSONB,
+    after            JSONB,
+    created_at       TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_roster_changes_import ON roster_changes(import_id, id);
+CREATE INDEX IF NOT EXISTS idx_roster_changes_student ON roster_changes(register_number, created_at DESC);
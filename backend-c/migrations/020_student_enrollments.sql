@@ -0,0 +1,63 @@
+-- Enrollment history: the section and semester a student belonged to over time. A student has
+-- at most one open enrollment; changing section or semester ends it and opens the next one.
+-- term_id is the academic term the enrollment started in, when one covers that date.
+
+CREATE TABLE IF NOT EXISTS student_enrollments (
+    id               BIGSERIAL PRIMARY KEY,
+    student_id       INT NOT NULL REFERENCES students(id),
+    register_number  TEXT NOT NULL,
+    term_id          INT REFERENCES academic_terms(id) ON DELETE SET NULL,
+    section          TEXT NOT NULL,
+    semester         INT,
+    started_at       TIMESTAMPTZ NOT NULL,
+    ended_at         TIMESTAMPTZ,
+    CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_student_enrollments_open
+    ON student_enrollments (student_id)
+    WHERE ended_at IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_student_enrollments_reg_no
+    ON student_enrollments (register_number, started_at);
+
+-- Certificates point at the enrollment active when they were uploaded; NULL when the student
+-- was not on the roster at the time.
+ALTER TABLE certificates
+    ADD COLUMN IF NOT EXISTS enrollment_id BIGINT REFERENCES student_enrollments(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_certificates_enrollment ON certificates (enrollment_id);
+
+-- Backfill one open enrollment per active student with their current section, starting at
+-- their earliest upload so existing certificates are attributed to it.
+WITH starts AS (
+    SELECT s.id, LEAST(s.created_at, MIN(c.uploaded_at)) AS started_at
+    FROM students s
+    LEFT JOIN certificates c ON c.reg_no = s.register_number
+    WHERE s.deleted_at IS NULL
+        AND NOT EXISTS (SELECT 1 FROM student_enrollments e WHERE e.student_id = s.id)
+    GROUP BY s.id
+)
+INSERT INTO student_enrollments (student_id, register_number, term_id, section, semester, started_at)
+SELECT
+    s.id,
+    s.register_number,
+    (
+        SELECT t.id FROM academic_terms t
+        WHERE (st.started_at AT TIME ZONE 'UTC')::date BETWEEN t.starts_on AND t.ends_on
+        ORDER BY t.starts_on DESC
+        LIMIT 1
+    ),
+    s.section,
+    s.semester,
+    st.started_at
+FROM starts st
+JOIN students s ON s.id = st.id;
+
+UPDATE certificates c
+SET enrollment_id = e.id
+FROM student_enrollments e
+WHERE c.enrollment_id IS NULL
+    AND e.register_number = c.reg_no
+    AND e.started_at <= c.uploaded_at
+    AND (e.ended_at IS NULL OR e.ended_at > c.uploaded_at);
@@ -0,0 +1,9 @@
+-- Idempotency keys expire: completed responses after a TTL, unfinished reservations once stale.
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_completed_at
+    ON idempotency_keys (completed_at)
+    WHERE completed_at IS NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_pending_created_at
+    ON idempotency_keys (created_at)
+    WHERE completed_at IS NULL;
@@ -0,0 +1,43 @@
+-- Register numbers are stored upper-case, and lookups upper-case their input. Rows written
+-- before that rule was enforced everywhere are brought in line so they can be found again.
+
+-- A legacy student whose upper-cased number is already on the roster is a duplicate of that
+-- row; it is left as is so the two can be merged by hand.
+UPDATE students s
+SET register_number = UPPER(s.register_number)
+WHERE s.register_number <> UPPER(s.register_number)
+    AND NOT EXISTS (
+        SELECT 1 FROM students o WHERE o.register_number = UPPER(s.register_number)
+    );
+
+UPDATE student_enrollments e
+SET register_number = s.register_number
+FROM students s
+WHERE s.id = e.student_id AND e.register_number <> s.register_number;
+
+UPDATE certificates
+SET reg_no = UPPER(reg_no)
+WHERE reg_no <> UPPER(reg_no);
+
+UPDATE roster_changes
+SET register_number = UPPER(register_number)
+WHERE register_number <> UPPER(register_number);
+
+UPDATE stats_deltas
+SET reg_no = UPPER(reg_no)
+WHERE reg_no <> UPPER(reg_no);
+
+-- Statistics rows are unique per register number. Mixed-case rows that would collide are
+-- dropped; POST /admin/stats/reconcile rebuilds the merged counts from certificates.
+DELETE FROM student_statistics a
+USING student_statistics b
+WHERE a.reg_no <> UPPER(a.reg_no) AND b.reg_no = UPPER(a.reg_no);
+
+DELETE FROM student_statistics a
+USING student_statistics b
+WHERE a.reg_no <> UPPER(a.reg_no) AND b.reg_no <> UPPER(b.reg_no)
+    AND UPPER(a.reg_no) = UPPER(b.reg_no) AND a.ctid < b.ctid;
+
+UPDATE student_statistics
+SET reg_no = UPPER(reg_no)
+WHERE reg_no <> UPPER(reg_no);
@@ -0,0 +1,5 @@
+-- committed_at is set in the same transaction that inserts an upload's certificates. A
+-- committed reservation is never taken over or swept as stale, even if its response was not
+-- stored, so a retry cannot insert the batch twice.
+
+ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS committed_at TIMESTAMPTZ;
@@ -0,0 +1,4 @@
+-- Import job rows also record warnings: changes made to rows that were imported, such as a
+-- section corrected to the roster or a student name that does not match it.
+
+ALTER TABLE import_job_errors ADD COLUMN IF NOT EXISTS warning BOOLEAN NOT NULL DEFAULT false;
@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// AcademicTerm is a named date range used to slice dashboards and leaderboards. Both dates
+// are inclusive.
+type AcademicTerm struct {
+	ID        int       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"column:name;type:text;unique;not null" json:"name"`
+	StartsOn  time.Time `gorm:"column:starts_on;type:date;not null" json:"starts_on"`
+	EndsOn    time.Time `gorm:"column:ends_on;type:date;not null" json:"ends_on"`
+	CreatedBy string    `gorm:"column:created_by;type:text;not null" json:"created_by"`
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp with time zone;autoCreateTime" json:"created_at"`
+}
+
+func (AcademicTerm) TableName() string {
+	return "academic_terms"
+}
+
+// EndsBefore is the exclusive upper bound of the term: midnight after EndsOn.
+func (t AcademicTerm) EndsBefore() time.Time {
+	return t.EndsOn.AddDate(0, 0, 1)
+}
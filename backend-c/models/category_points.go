@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// DefaultCategoryPoints is awarded for LEGIT certificates of categories without a weight.
+const DefaultCategoryPoints = 1.0
+
+// CategoryPoints weights LEGIT certificates of a category on the points leaderboard.
+type CategoryPoints struct {
+	Category  string    `gorm:"column:category;type:text;primaryKey" json:"category"`
+	Points    float64   `gorm:"column:points;type:numeric(6,2);not null" json:"points"`
+	UpdatedBy string    `gorm:"column:updated_by;type:text;not null" json:"updated_by"`
+	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp with time zone;not null" json:"updated_at"`
+}
+
+func (CategoryPoints) TableName() string {
+	return "category_points"
+}
@@ -27,15 +27,33 @@ type Certificate struct {
 	RegisterNumber string        `gorm:"column:reg_no;type:text;not null"`
 	Section        string        `gorm:"column:section;type:text;not null"`
 	StudentName    string        `gorm:"column:student_name;type:text;not null"`	
+	Title          string        `gorm:"column:title;type:text;default:'';not null"`
+	Issuer         string        `gorm:"column:issuer;type:text;default:'';not null"`
+	Category       string        `gorm:"column:category;type:text;default:'GENERAL';not null"`
+	Department     string        `gorm:"column:department;type:text;default:'';not null"`
+	EnrollmentID   *int64        `gorm:"column:enrollment_id;type:bigint"` // Enrollment active at upload
 	UploadedBy     string        `gorm:"column:faculty_id;type:text;not null"`
 	UploadedAt     time.Time     `gorm:"column:uploaded_at;type:timestamp with time zone;not null"`
 	MLStatus       MLStatus      `gorm:"column:ml_status;type:ml_status_enum;default:'PENDING';not null"`
+	MLVerifiedAt   *time.Time    `gorm:"column:ml_verified_at;type:timestamp with time zone"`
 	FacultyStatus  FacultyStatus `gorm:"column:faculty_status;type:faculty_status_enum;default:'PENDING';not null"`
+	ReviewedBy     *string       `gorm:"column:reviewed_by;type:text"`
+	ReviewedAt     *time.Time    `gorm:"column:reviewed_at;type:timestamp with time zone"`
+	RequiresHOD    bool          `gorm:"column:requires_hod_review;type:boolean;default:false;not null"`
+	PolicyVersion  *int          `gorm:"column:decision_policy_version;type:int"`
 	IsLegit        *bool         `gorm:"-"` // Missing in DB
-	MLScore        *float64      `gorm:"-"` // Missing in DB
+	MLScore        *float64      `gorm:"column:ml_score;type:numeric(5,2)"`
 	Archived       bool          `gorm:"column:archived;type:boolean;default:false;not null"`
+	EscalatedAt    *time.Time    `gorm:"column:escalated_at;type:timestamp with time zone"`
+	EscalationNote string        `gorm:"column:escalation_reason;type:text;default:'';not null"`
+
+	// Signals holds automated verification results; loaded only when preloaded.
+	Signals []CertificateVerificationSignal `gorm:"foreignKey:CertificateID"`
 }
 
+// DefaultCertificateCategory is used when an upload does not name a category.
+const DefaultCertificateCategory = "GENERAL"
+
 func (Certificate) TableName() string {
 	return "certificates"
 }
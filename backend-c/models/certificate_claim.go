@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// CertificateClaim is a reviewer's lease on a certificate. A claim stops counting once
+// ExpiresAt has passed, even if the row is still present.
+type CertificateClaim struct {
+	CertificateID string    `gorm:"column:certificate_id;type:uuid;primaryKey" json:"certificate_id"`
+	ClaimedBy     string    `gorm:"column:claimed_by;type:text;not null" json:"claimed_by"`
+	ClaimedAt     time.Time `gorm:"column:claimed_at;type:timestamp with time zone;not null" json:"claimed_at"`
+	ExpiresAt     time.Time `gorm:"column:expires_at;type:timestamp with time zone;not null" json:"expires_at"`
+}
+
+func (CertificateClaim) TableName() string {
+	return "certificate_claims"
+}
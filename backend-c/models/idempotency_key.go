@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// IdempotencyKey records a client-supplied Idempotency-Key, the fingerprint of the request
+// it was first used with and, once the request finished, the response to replay. CommittedAt
+// is set in the transaction that inserts the request's certificates.
+type IdempotencyKey struct {
+	ActorEmail     string     `gorm:"column:actor_email;type:text;primaryKey"`
+	Key            string     `gorm:"column:key;type:text;primaryKey"`
+	RequestHash    string     `gorm:"column:request_hash;type:text;not null"`
+	ResponseStatus *int       `gorm:"column:response_status;type:int"`
+	ResponseBody   *string    `gorm:"column:response_body;type:jsonb"`
+	CreatedAt      time.Time  `gorm:"column:created_at;type:timestamp with time zone;not null"`
+	CommittedAt    *time.Time `gorm:"column:committed_at;type:timestamp with time zone"`
+	CompletedAt    *time.Time `gorm:"column:completed_at;type:timestamp with time zone"`
+}
+
+func (IdempotencyKey) TableName() string {
+	return "idempotency_keys"
+}
@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+// CertificateImageHash is the perceptual hash of a certificate's image. Hash holds the
+// 64 hash bits reinterpreted as a signed integer for the BIGINT column.
+type CertificateImageHash struct {
+	CertificateID string    `gorm:"column:certificate_id;type:uuid;primaryKey" json:"certificate_id"`
+	Hash          int64     `gorm:"column:hash;type:bigint;not null" json:"-"`
+	Source        string    `gorm:"column:source;type:text;not null" json:"source"`
+	CreatedBy     string    `gorm:"column:created_by;type:text;not null" json:"created_by"`
+	ComputedAt    time.Time `gorm:"column:computed_at;type:timestamp with time zone;not null" json:"computed_at"`
+}
+
+func (CertificateImageHash) TableName() string {
+	return "certificate_image_hashes"
+}
+
+// CertificateImageHashBand is one indexed byte of an image hash.
+type CertificateImageHashBand struct {
+	CertificateID string `gorm:"column:certificate_id;type:uuid;primaryKey"`
+	BandNo        int16  `gorm:"column:band_no;type:smallint;primaryKey"`
+	BandValue     int16  `gorm:"column:band_value;type:smallint;not null"`
+}
+
+func (CertificateImageHashBand) TableName() string {
+	return "certificate_image_hash_bands"
+}
+
+// NearDuplicatePair links two certificates of different students whose images are
+// perceptually close. CertificateA always sorts before CertificateB.
+type NearDuplicatePair struct {
+	CertificateA string    `gorm:"column:certificate_a;type:uuid;primaryKey"`
+	CertificateB string    `gorm:"column:certificate_b;type:uuid;primaryKey"`
+	Distance     int       `gorm:"column:distance;type:smallint;not null"`
+	DetectedAt   time.Time `gorm:"column:detected_at;type:timestamp with time zone;not null"`
+}
+
+func (NearDuplicatePair) TableName() string {
+	return "near_duplicate_pairs"
+}
@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// ImportJobStatus tracks a background import through its lifecycle.
+type ImportJobStatus string
+
+const (
+	ImportJobQueued    ImportJobStatus = "QUEUED"
+	ImportJobRunning   ImportJobStatus = "RUNNING"
+	ImportJobCompleted ImportJobStatus = "COMPLETED"
+	ImportJobFailed    ImportJobStatus = "FAILED"
+)
+
+// ImportJobKindCertificates marks a spreadsheet import of certificates.
+const ImportJobKindCertificates = "CERTIFICATES"
+
+// ImportJob is a spreadsheet import whose valid rows are inserted in the background.
+type ImportJob struct {
+	ID            string          `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Kind          string          `gorm:"column:kind;type:text;not null" json:"kind"`
+	Status        ImportJobStatus `gorm:"column:status;type:text;not null" json:"status"`
+	FileName      string          `gorm:"column:file_name;type:text;not null" json:"file_name"`
+	CreatedBy     string          `gorm:"column:created_by;type:text;not null" json:"created_by"`
+	TotalRows     int             `gorm:"column:total_rows;type:int;not null" json:"total_rows"`
+	ValidRows     int             `gorm:"column:valid_rows;type:int;not null" json:"valid_rows"`
+	InvalidRows   int             `gorm:"column:invalid_rows;type:int;not null" json:"invalid_rows"`
+	ProcessedRows int             `gorm:"column:processed_rows;type:int;not null" json:"processed_rows"`
+	Error         string          `gorm:"column:error;type:text;not null" json:"error,omitempty"`
+	CreatedAt     time.Time       `gorm:"column:created_at;type:timestamp with time zone;not null" json:"created_at"`
+	StartedAt     *time.Time      `gorm:"column:started_at;type:timestamp with time zone" json:"started_at"`
+	FinishedAt    *time.Time      `gorm:"column:finished_at;type:timestamp with time zone" json:"finished_at"`
+}
+
+func (ImportJob) TableName() string {
+	return "import_jobs"
+}
+
+// ImportJobError is a validation failure for one spreadsheet row. With Warning set, the row
+// was imported and the message records a change made to it, such as a corrected section.
+type ImportJobError struct {
+	ID        int64  `gorm:"column:id;primaryKey;autoIncrement" json:"-"`
+	JobID     string `gorm:"column:job_id;type:uuid;not null" json:"-"`
+	RowNumber int    `gorm:"column:row_number;type:int;not null" json:"row_number"`
+	Field     string `gorm:"column:field;type:text;not null" json:"field"`
+	Message   string `gorm:"column:message;type:text;not null" json:"message"`
+	Warning   bool   `gorm:"column:warning;type:boolean;not null" json:"warning"`
+}
+
+func (ImportJobError) TableName() string {
+	return "import_job_errors"
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// SystemActorMLPolicy is recorded as the reviewer of certificates decided by the ML policy.
+const SystemActorMLPolicy = "system:ml-policy"
+
+// MLDecisionPolicy routes ML-verified certificates by score. Scores at or above
+// AutoApproveThreshold are approved automatically; scores below HodReviewThreshold require
+// an HOD decision; everything else goes to the faculty queue. Each change is a new version.
+type MLDecisionPolicy struct {
+	Version              int       `gorm:"column:version;primaryKey;autoIncrement" json:"version"`
+	AutoApproveThreshold float64   `gorm:"column:auto_approve_threshold;type:numeric(5,2);not null" json:"auto_approve_threshold"`
+	HodReviewThreshold   float64   `gorm:"column:hod_review_threshold;type:numeric(5,2);not null" json:"hod_review_threshold"`
+	Enabled              bool      `gorm:"column:enabled;type:boolean;not null" json:"enabled"`
+	CreatedBy            string    `gorm:"column:created_by;type:text;not null" json:"created_by"`
+	CreatedAt            time.Time `gorm:"column:created_at;type:timestamp with time zone;not null" json:"created_at"`
+}
+
+func (MLDecisionPolicy) TableName() string {
+	return "ml_decision_policies"
+}
@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// QuotaRule is the minimum number of LEGIT certificates a student must earn within an academic
+// term. A nil Batch, Section or Category matches every value; for each student and category
+// the most specific matching rule applies.
+type QuotaRule struct {
+	ID        int       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	TermID    int       `gorm:"column:term_id;type:int;not null" json:"term_id"`
+	Batch     *string   `gorm:"column:batch;type:text" json:"batch"`
+	Section   *string   `gorm:"column:section;type:text" json:"section"`
+	Category  *string   `gorm:"column:category;type:text" json:"category"`
+	MinLegit  int       `gorm:"column:min_legit;type:int;not null" json:"min_legit"`
+	UpdatedBy string    `gorm:"column:updated_by;type:text;not null" json:"updated_by"`
+	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp with time zone;not null" json:"updated_at"`
+}
+
+func (QuotaRule) TableName() string {
+	return "quota_rules"
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+// ReviewSLAPolicy sets how long certificates may wait for faculty review. A nil Section or
+// Category matches every value; the most specific matching policy applies.
+type ReviewSLAPolicy struct {
+	ID             int       `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	Section        *string   `gorm:"column:section;type:text" json:"section"`
+	Category       *string   `gorm:"column:category;type:text" json:"category"`
+	ThresholdHours int       `gorm:"column:threshold_hours;type:int;not null" json:"threshold_hours"`
+	UpdatedBy      string    `gorm:"column:updated_by;type:text;not null" json:"updated_by"`
+	UpdatedAt      time.Time `gorm:"column:updated_at;type:timestamp with time zone;not null" json:"updated_at"`
+}
+
+func (ReviewSLAPolicy) TableName() string {
+	return "review_sla_policies"
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// RosterImportStatus tracks a roster import from preview to application.
+type RosterImportStatus string
+
+const (
+	RosterImportPending RosterImportStatus = "PENDING"
+	RosterImportApplied RosterImportStatus = "APPLIED"
+)
+
+// RosterChangeAction is what an applied roster import did to one student.
+type RosterChangeAction string
+
+const (
+	RosterChangeAdded   RosterChangeAction = "ADDED"
+	RosterChangeUpdated RosterChangeAction = "UPDATED"
+	RosterChangeRemoved RosterChangeAction = "REMOVED"
+)
+
+// RosterImport is an uploaded roster spreadsheet and the diff it would make to the students
+// table. Nothing is changed until it is confirmed; RosterVersion fingerprints the roster the
+// diff was computed against so a confirm over a since-changed roster can be refused.
+type RosterImport struct {
+	ID            string             `gorm:"column:id;type:uuid;default:gen_random_uuid();primaryKey" json:"id"`
+	Status        RosterImportStatus `gorm:"column:status;type:text;not null" json:"status"`
+	FileName      string             `gorm:"column:file_name;type:text;not null" json:"file_name"`
+	CreatedBy     string             `gorm:"column:created_by;type:text;not null" json:"created_by"`
+	RosterVersion string             `gorm:"column:roster_version;type:text;not null" json:"-"`
+	TotalRows     int                `gorm:"column:total_rows;type:int;not null" json:"total_rows"`
+	InvalidRows   int                `gorm:"column:invalid_rows;type:int;not null" json:"invalid_rows"`
+	Added         int                `gorm:"column:added;type:int;not null" json:"added"`
+	Changed       int                `gorm:"column:changed;type:int;not null" json:"changed"`
+	Missing       int                `gorm:"column:missing;type:int;not null" json:"missing"`
+	Diff          json.RawMessage    `gorm:"column:diff;type:jsonb;not null" json:"-"`
+	RemoveMissing bool               `gorm:"column:remove_missing;type:boolean;not null" json:"remove_missing"`
+	CreatedAt     time.Time          `gorm:"column:created_at;type:timestamp with time zone;not null" json:"created_at"`
+	AppliedBy     *string            `gorm:"column:applied_by;type:text" json:"applied_by"`
+	AppliedAt     *time.Time         `gorm:"column:applied_at;type:timestamp with time zone" json:"applied_at"`
+}
+
+func (RosterImport) TableName() string {
+	return "roster_imports"
+}
+
+// RosterChange records one student changed by an applied roster import. Before is null for
+// added students and After for removed ones.
+type RosterChange struct {
+	ID             int64              `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	ImportID       string             `gorm:"column:import_id;type:uuid;not null" json:"import_id"`
+	RegisterNumber string             `gorm:"column:register_number;type:text;not null" json:"register_number"`
+	Action         RosterChangeAction `gorm:"column:action;type:text;not null" json:"action"`
+	Before         json.RawMessage    `gorm:"column:before;type:jsonb" json:"before"`
+	After          json.RawMessage    `gorm:"column:after;type:jsonb" json:"after"`
+	CreatedAt      time.Time          `gorm:"column:created_at;type:timestamp with time zone;not null" json:"created_at"`
+}
+
+func (RosterChange) TableName() string {
+	return "roster_changes"
+}
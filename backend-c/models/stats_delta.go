@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// StatsDelta is an unfolded change to one student's and one section's statistics counters.
+type StatsDelta struct {
+	ID              int64     `gorm:"column:id;primaryKey;autoIncrement"`
+	RegisterNumber  string    `gorm:"column:reg_no;type:text;not null"`
+	StudentName     string    `gorm:"column:student_name;type:text;default:'';not null"`
+	Section         string    `gorm:"column:section;type:text;not null"`
+	TotalUploaded   int       `gorm:"column:total_uploaded;type:int;default:0;not null"`
+	PendingCount    int       `gorm:"column:pending_count;type:int;default:0;not null"`
+	LegitCount      int       `gorm:"column:legit_count;type:int;default:0;not null"`
+	NotLegitCount   int       `gorm:"column:not_legit_count;type:int;default:0;not null"`
+	MLVerifiedCount int       `gorm:"column:ml_verified_count;type:int;default:0;not null"`
+	CreatedAt       time.Time `gorm:"column:created_at;type:timestamp with time zone;autoCreateTime"`
+}
+
+func (StatsDelta) TableName() string {
+	return "stats_deltas"
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Student mirrors the students roster table. FacultyEmail is the faculty advisor the student
+// is assigned to; Email is the student's own login, when known. Batch is the admission year
+// quota rules are matched against. Deleted students are kept with DeletedAt set.
+type Student struct {
+	ID             int            `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	RegisterNumber string         `gorm:"column:register_number;type:text;unique;not null" json:"register_number"`
+	Name           string         `gorm:"column:name;type:text;not null" json:"name"`
+	Email          *string        `gorm:"column:email;type:text" json:"email"`
+	Section        string         `gorm:"column:section;type:text;not null" json:"section"`
+	Semester       *int           `gorm:"column:semester;type:int" json:"semester"`
+	Batch          string         `gorm:"column:batch;type:text;default:'';not null" json:"batch"`
+	IsPresent      bool           `gorm:"column:is_present;type:boolean;not null" json:"is_present"`
+	FacultyEmail   string         `gorm:"column:faculty_email;type:text;not null" json:"faculty_email"`
+	CreatedAt      time.Time      `gorm:"column:created_at;type:timestamp with time zone;autoCreateTime" json:"created_at"`
+	UpdatedAt      time.Time      `gorm:"column:updated_at;type:timestamp with time zone;autoUpdateTime" json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"column:deleted_at;type:timestamp with time zone;index" json:"-"`
+}
+
+func (Student) TableName() string {
+	return "students"
+}
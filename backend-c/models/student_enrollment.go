@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// StudentEnrollment is a period during which a student belonged to one section and semester.
+// EndedAt is nil for the student's current enrollment; TermID is the academic term the
+// period started in, when one covers that date.
+type StudentEnrollment struct {
+	ID             int64      `gorm:"column:id;primaryKey;autoIncrement" json:"id"`
+	StudentID      int        `gorm:"column:student_id;type:int;not null" json:"student_id"`
+	RegisterNumber string     `gorm:"column:register_number;type:text;not null" json:"register_number"`
+	TermID         *int       `gorm:"column:term_id;type:int" json:"term_id"`
+	Section        string     `gorm:"column:section;type:text;not null" json:"section"`
+	Semester       *int       `gorm:"column:semester;type:int" json:"semester"`
+	StartedAt      time.Time  `gorm:"column:started_at;type:timestamp with time zone;not null" json:"started_at"`
+	EndedAt        *time.Time `gorm:"column:ended_at;type:timestamp with time zone" json:"ended_at"`
+}
+
+func (StudentEnrollment) TableName() string {
+	return "student_enrollments"
+}
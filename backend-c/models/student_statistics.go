@@ -3,18 +3,16 @@ package models
 import "time"
 
 // StudentStatistics mirrors the student_statistics table in Supabase.
+// StudentName and Section follow the student's most recent upload.
 type StudentStatistics struct {
-	ID                     string    `gorm:"-"` // Missing
-	StudentID              string    `gorm:"-"` // Missing
-	RegisterNumber         string    `gorm:"column:reg_no;type:text;not null"`
-	StudentName            string    `gorm:"-"` // Missing
-	Section                string    `gorm:"-"` // Missing
-	Semester               int       `gorm:"-"` // Missing
+	RegisterNumber         string    `gorm:"column:reg_no;type:text;unique;not null"`
+	StudentName            string    `gorm:"column:student_name;type:text;default:'';not null"`
+	Section                string    `gorm:"column:section;type:text;default:'';not null"`
 	TotalCertificates      int       `gorm:"column:total_uploaded;type:int;default:0;not null"`
 	LegitCertificates      int       `gorm:"column:legit_count;type:int;default:0;not null"`
 	NotLegitCertificates   int       `gorm:"column:not_legit_count;type:int;default:0;not null"`
-	PendingCertificates    int       `gorm:"-"` // Missing
-	MlVerifiedCertificates int       `gorm:"-"` // Missing
+	PendingCertificates    int       `gorm:"column:pending_count;type:int;default:0;not null"`
+	MlVerifiedCertificates int       `gorm:"column:ml_verified_count;type:int;default:0;not null"`
 	UpdatedAt              time.Time `gorm:"column:last_updated;type:timestamp with time zone;not null"`
 }
 
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// VerificationSignalKind names the automated check that produced a signal.
+type VerificationSignalKind string
+
+const (
+	// VerificationSignalTextMatch compares text extracted from the certificate PDF with the
+	// student name and declared issuer.
+	VerificationSignalTextMatch VerificationSignalKind = "TEXT_MATCH"
+)
+
+// CertificateVerificationSignal is the latest result of one automated check on a certificate.
+// Confidence is in [0, 1]; Details holds check-specific evidence such as matched snippets.
+type CertificateVerificationSignal struct {
+	CertificateID string                 `gorm:"column:certificate_id;type:uuid;primaryKey" json:"certificate_id"`
+	Kind          VerificationSignalKind `gorm:"column:kind;type:text;primaryKey" json:"kind"`
+	Confidence    float64                `gorm:"column:confidence;type:numeric(4,3);not null" json:"confidence"`
+	Details       json.RawMessage        `gorm:"column:details;type:jsonb;not null" json:"details"`
+	CreatedBy     string                 `gorm:"column:created_by;type:text;not null" json:"created_by"`
+	UpdatedAt     time.Time              `gorm:"column:updated_at;type:timestamp with time zone;not null" json:"updated_at"`
+}
+
+func (CertificateVerificationSignal) TableName() string {
+	return "certificate_verification_signals"
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	"department-eduvault-backend/models"
 
@@ -16,21 +17,72 @@ var (
 	ErrTooManyCertificates = errors.New("cannot insert more than 10 certificates at once")
 	// ErrCertificateNotFound is returned when a certificate lookup fails.
 	ErrCertificateNotFound = errors.New("certificate not found")
-	// ErrStatsNotFound indicates related stats rows are missing for updates.
-	ErrStatsNotFound = errors.New("statistics record not found")
+	// ErrCertificateClaimed is returned when another reviewer holds a live lease on the certificate.
+	ErrCertificateClaimed = errors.New("certificate is claimed by another reviewer")
+	// ErrClaimNotHeld is returned when releasing a lease the caller does not hold.
+	ErrClaimNotHeld = errors.New("no active claim held on this certificate")
 )
 
+// PendingReviewSort orders the faculty review queue.
+type PendingReviewSort string
+
+const (
+	PendingReviewOldestFirst PendingReviewSort = "oldest"
+	PendingReviewNewestFirst PendingReviewSort = "newest"
+)
+
+// PendingReviewCursor is the keyset position of the last certificate already returned.
+type PendingReviewCursor struct {
+	UploadedAt time.Time
+	ID         string
+}
+
+// PendingReviewFilter narrows, orders and pages the faculty review queue.
+// Zero-valued fields are not applied, except that certificates under a live claim by
+// anyone other than Reviewer are always excluded.
+type PendingReviewFilter struct {
+	Reviewer     string
+	Section      string
+	UploadedBy   string
+	UploadedFrom *time.Time
+	UploadedTo   *time.Time
+	MinMLScore   *float64
+	MaxMLScore   *float64
+	Sort         PendingReviewSort
+	After        *PendingReviewCursor
+	Limit        int
+}
+
+// MLRouting is where an ML decision policy sends a certificate it has just seen verified.
+// UpdateMLStatus applies it in the transaction that records the verification, so a
+// certificate never sits in the faculty queue between the two.
+type MLRouting struct {
+	PolicyVersion int
+	// AutoApprove marks the certificate LEGIT on behalf of the policy.
+	AutoApprove bool
+	// HODReviewReason, when set, takes the certificate out of the faculty queue for the HOD.
+	HODReviewReason string
+}
+
 // CertificateRepository defines database operations for certificates and related statistics.
 type CertificateRepository interface {
 	GetByID(ctx context.Context, certificateID string) (*models.Certificate, error)
 	CreateCertificates(ctx context.Context, certs []models.Certificate) error
-	UpdateMLStatus(ctx context.Context, certificateID string, status models.MLStatus, mlScore *float64) error
-	GetCertificatesPendingFacultyReview(ctx context.Context, limit int) ([]models.Certificate, error)
-	UpdateFacultyDecision(ctx context.Context, certificateID string, status models.FacultyStatus, isLegit bool) error
+	CreateCertificatesIdempotent(ctx context.Context, certs []models.Certificate, reservation models.IdempotencyKey) error
+	CreateCertificatesBulk(ctx context.Context, certs []models.Certificate) error
+	UpdateMLStatus(ctx context.Context, certificateID string, status models.MLStatus, mlScore *float64, routing *MLRouting) error
+	GetCertificatesPendingFacultyReview(ctx context.Context, filter PendingReviewFilter) ([]models.Certificate, error)
+	UpdateFacultyDecision(ctx context.Context, certificateID, reviewer string, status models.FacultyStatus, isLegit bool) error
+	ClaimCertificate(ctx context.Context, certificateID, reviewer string, ttl time.Duration) (*models.CertificateClaim, error)
+	ReleaseClaim(ctx context.Context, certificateID, reviewer string) error
+	// OnMutation registers a listener run after every committed insert or status change of
+	// certificates. Claims are not certificate mutations and do not notify.
+	OnMutation(listener MutationListener)
 }
 
 type certificateRepository struct {
 	db *gorm.DB
+	mutationListeners
 }
 
 // NewCertificateRepository constructs a CertificateRepository.
@@ -58,25 +110,67 @@ func (r *certificateRepository) CreateCertificates(ctx context.Context, certs []
 	if len(certs) > 10 {
 		return ErrTooManyCertificates
 	}
+	return r.createWithStats(ctx, certs, nil)
+}
+
+// CreateCertificatesIdempotent inserts a batch like CreateCertificates and, in the same
+// transaction, marks the Idempotency-Key reservation committed so it is never taken over or
+// swept as abandoned. Nothing is inserted and ErrIdempotencyKeyNotFound is returned when the
+// reservation is no longer held by this request.
+func (r *certificateRepository) CreateCertificatesIdempotent(ctx context.Context, certs []models.Certificate, reservation models.IdempotencyKey) error {
+	if len(certs) == 0 {
+		return nil
+	}
+	if len(certs) > 10 {
+		return ErrTooManyCertificates
+	}
+	return r.createWithStats(ctx, certs, &reservation)
+}
 
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+// CreateCertificatesBulk inserts an import chunk of any size and updates stats in one
+// transaction. Callers are expected to chunk large imports themselves.
+func (r *certificateRepository) CreateCertificatesBulk(ctx context.Context, certs []models.Certificate) error {
+	if len(certs) == 0 {
+		return nil
+	}
+	return r.createWithStats(ctx, certs, nil)
+}
+
+func (r *certificateRepository) createWithStats(ctx context.Context, certs []models.Certificate, reservation *models.IdempotencyKey) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if reservation != nil {
+			if err := markIdempotencyKeyCommitted(tx, *reservation); err != nil {
+				return err
+			}
+		}
 		if err := tx.Create(&certs).Error; err != nil {
 			return fmt.Errorf("insert certificates: %w", err)
 		}
+		ids := make([]string, 0, len(certs))
+		for _, cert := range certs {
+			ids = append(ids, cert.ID)
+		}
+		if err := linkEnrollments(tx, ids); err != nil {
+			return err
+		}
+		sections, err := attributedSections(tx, ids)
+		if err != nil {
+			return err
+		}
 
-		// Update stats for each certificate; assumes rows already exist in stats tables.
+		deltas := make([]models.StatsDelta, 0, len(certs))
 		for _, cert := range certs {
-			if err := r.incrementStats(ctx, tx, cert); err != nil {
-				return err
-			}
+			deltas = append(deltas, newCertificateDelta(cert, sections[cert.ID]))
 		}
-		return nil
+		return r.recordStats(ctx, tx, deltas...)
 	})
+	return r.committed(err)
 }
 
-// UpdateMLStatus sets ml_status (and optional score) and syncs stats counts.
-func (r *certificateRepository) UpdateMLStatus(ctx context.Context, certificateID string, status models.MLStatus, mlScore *float64) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+// UpdateMLStatus sets ml_status (and optional score) and syncs stats counts. routing, when
+// set, is applied if the certificate becomes VERIFIED while still pending faculty action.
+func (r *certificateRepository) UpdateMLStatus(ctx context.Context, certificateID string, status models.MLStatus, mlScore *float64, routing *MLRouting) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var cert models.Certificate
 		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&cert, "id = ?", certificateID).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -88,10 +182,13 @@ func (r *certificateRepository) UpdateMLStatus(ctx context.Context, certificateI
 		updates := map[string]interface{}{
 			"ml_status": status,
 		}
-		// ml_score column is missing in DB
-		// if mlScore != nil {
-		// 	updates["ml_score"] = mlScore
-		// }
+		if mlScore != nil {
+			updates["ml_score"] = *mlScore
+		}
+		if status == models.MLStatusVerified && cert.MLStatus != models.MLStatusVerified {
+			// Starts the faculty review clock used for SLA tracking.
+			updates["ml_verified_at"] = time.Now().UTC()
+		}
 
 		if err := tx.Model(&cert).Updates(updates).Error; err != nil {
 			return fmt.Errorf("update ml status: %w", err)
@@ -102,31 +199,76 @@ func (r *certificateRepository) UpdateMLStatus(ctx context.Context, certificateI
 			if err := r.bumpMlVerified(ctx, tx, cert); err != nil {
 				return err
 			}
+			if routing != nil && cert.FacultyStatus == models.FacultyStatusPending {
+				return r.applyMLRouting(ctx, tx, cert, *routing)
+			}
 		}
 		return nil
 	})
+	return r.committed(err)
 }
 
-// GetCertificatesPendingFacultyReview returns ML-verified certificates awaiting faculty decision.
-func (r *certificateRepository) GetCertificatesPendingFacultyReview(ctx context.Context, limit int) ([]models.Certificate, error) {
+// GetCertificatesPendingFacultyReview returns ML-verified certificates awaiting faculty decision,
+// paged by keyset over (uploaded_at, id), with their verification signals.
+func (r *certificateRepository) GetCertificatesPendingFacultyReview(ctx context.Context, filter PendingReviewFilter) ([]models.Certificate, error) {
+	limit := filter.Limit
 	if limit <= 0 {
 		limit = 50
 	}
-	var certs []models.Certificate
-	err := r.db.WithContext(ctx).
+
+	query := r.db.WithContext(ctx).
 		Where("ml_status = ? AND faculty_status = ? AND archived = ?", models.MLStatusVerified, models.FacultyStatusPending, false).
-		Order("uploaded_at ASC").
-		Limit(limit).
-		Find(&certs).Error
-	if err != nil {
+		Where("requires_hod_review = ?", false).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM certificate_claims cl
+			WHERE cl.certificate_id = certificates.id AND cl.expires_at > NOW() AND cl.claimed_by <> ?
+		)`, filter.Reviewer)
+
+	if filter.Section != "" {
+		query = query.Where("section = ?", filter.Section)
+	}
+	if filter.UploadedBy != "" {
+		query = query.Where("faculty_id = ?", filter.UploadedBy)
+	}
+	if filter.UploadedFrom != nil {
+		query = query.Where("uploaded_at >= ?", *filter.UploadedFrom)
+	}
+	if filter.UploadedTo != nil {
+		query = query.Where("uploaded_at < ?", *filter.UploadedTo)
+	}
+	if filter.MinMLScore != nil {
+		query = query.Where("ml_score >= ?", *filter.MinMLScore)
+	}
+	if filter.MaxMLScore != nil {
+		query = query.Where("ml_score <= ?", *filter.MaxMLScore)
+	}
+
+	if filter.Sort == PendingReviewNewestFirst {
+		if filter.After != nil {
+			query = query.Where("(uploaded_at, id) < (?, ?)", filter.After.UploadedAt, filter.After.ID)
+		}
+		query = query.Order("uploaded_at DESC").Order("id DESC")
+	} else {
+		if filter.After != nil {
+			query = query.Where("(uploaded_at, id) > (?, ?)", filter.After.UploadedAt, filter.After.ID)
+		}
+		query = query.Order("uploaded_at ASC").Order("id ASC")
+	}
+
+	var certs []models.Certificate
+	if err := query.Preload("Signals", func(db *gorm.DB) *gorm.DB {
+		return db.Order("kind")
+	}).Limit(limit).Find(&certs).Error; err != nil {
 		return nil, fmt.Errorf("query pending faculty review: %w", err)
 	}
 	return certs, nil
 }
 
 // UpdateFacultyDecision records the faculty decision and updates stats in a transaction.
-func (r *certificateRepository) UpdateFacultyDecision(ctx context.Context, certificateID string, status models.FacultyStatus, isLegit bool) error {
-	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+// It fails with ErrCertificateClaimed if another reviewer holds a live lease, and releases
+// the reviewer's own lease once the decision is stored.
+func (r *certificateRepository) UpdateFacultyDecision(ctx context.Context, certificateID, reviewer string, status models.FacultyStatus, isLegit bool) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
 		var cert models.Certificate
 		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&cert, "id = ?", certificateID).Error; err != nil {
 			if errors.Is(err, gorm.ErrRecordNotFound) {
@@ -135,106 +277,178 @@ func (r *certificateRepository) UpdateFacultyDecision(ctx context.Context, certi
 			return fmt.Errorf("fetch certificate: %w", err)
 		}
 
-		if err := tx.Model(&cert).Updates(map[string]interface{}{
-			"faculty_status": status,
-			// "is_legit":       isLegit, // Missing in DB
-		}).Error; err != nil {
-			return fmt.Errorf("update faculty decision: %w", err)
+		var claims []models.CertificateClaim
+		if err := tx.Where("certificate_id = ? AND expires_at > NOW()", certificateID).Find(&claims).Error; err != nil {
+			return fmt.Errorf("fetch certificate claim: %w", err)
 		}
-
-		// Adjust stats only when transitioning from pending.
-		if cert.FacultyStatus == models.FacultyStatusPending {
-			if err := r.applyFacultyDecisionStats(ctx, tx, cert, status); err != nil {
-				return err
-			}
+		if len(claims) > 0 && claims[0].ClaimedBy != reviewer {
+			return ErrCertificateClaimed
 		}
-		return nil
+		if err := tx.Where("certificate_id = ?", certificateID).Delete(&models.CertificateClaim{}).Error; err != nil {
+			return fmt.Errorf("release certificate claim: %w", err)
+		}
+
+		return r.recordDecision(ctx, tx, cert, reviewer, status, nil)
 	})
+	return r.committed(err)
 }
 
-// incrementStats bumps per-student and per-section totals for new certificates.
-func (r *certificateRepository) incrementStats(ctx context.Context, tx *gorm.DB, cert models.Certificate) error {
-	// Student statistics updates.
-	if err := tx.WithContext(ctx).
-		Model(&models.StudentStatistics{}).
-		Where("reg_no = ?", cert.RegisterNumber).
-		Clauses(clause.Locking{Strength: "UPDATE"}).
-		Updates(map[string]interface{}{
-			"total_uploaded": gorm.Expr("total_uploaded + 1"),
-			// "pending_certificates": gorm.Expr("pending_certificates + 1"), // Missing
+// applyMLRouting auto-approves or flags for HOD review a locked certificate that is pending
+// faculty action.
+func (r *certificateRepository) applyMLRouting(ctx context.Context, tx *gorm.DB, cert models.Certificate, routing MLRouting) error {
+	switch {
+	case routing.AutoApprove:
+		if err := tx.Where("certificate_id = ?", cert.ID).Delete(&models.CertificateClaim{}).Error; err != nil {
+			return fmt.Errorf("release certificate claim: %w", err)
+		}
+		return r.recordDecision(ctx, tx, cert, models.SystemActorMLPolicy, models.FacultyStatusLegit, map[string]interface{}{
+			"decision_policy_version": routing.PolicyVersion,
+		})
+	case routing.HODReviewReason != "":
+		if err := tx.Model(&cert).Updates(map[string]interface{}{
+			"requires_hod_review":     true,
+			"decision_policy_version": routing.PolicyVersion,
+			"escalated_at":            time.Now().UTC(),
+			"escalation_reason":       routing.HODReviewReason,
 		}).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrStatsNotFound
+			return fmt.Errorf("flag certificate for hod review: %w", err)
 		}
-		return fmt.Errorf("update student statistics: %w", err)
 	}
+	return nil
+}
 
-	// Section statistics updates.
-	if err := tx.WithContext(ctx).
-		Model(&models.SectionStatistics{}).
-		Where("section = ?", cert.Section).
-		Clauses(clause.Locking{Strength: "UPDATE"}).
-		Updates(map[string]interface{}{
-			"total_uploaded": gorm.Expr("total_uploaded + 1"),
-		}).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return ErrStatsNotFound
-		}
-		return fmt.Errorf("update section statistics: %w", err)
+// ClaimCertificate grants or renews a lease for reviewer. An expired lease held by someone
+// else is taken over; a live one yields ErrCertificateClaimed.
+func (r *certificateRepository) ClaimCertificate(ctx context.Context, certificateID, reviewer string, ttl time.Duration) (*models.CertificateClaim, error) {
+	var claims []models.CertificateClaim
+	query := `
+		INSERT INTO certificate_claims (certificate_id, claimed_by, claimed_at, expires_at)
+		VALUES (?, ?, NOW(), NOW() + make_interval(secs => ?))
+		ON CONFLICT (certificate_id) DO UPDATE SET
+			claimed_by = EXCLUDED.claimed_by,
+			claimed_at = CASE WHEN certificate_claims.claimed_by = EXCLUDED.claimed_by
+				THEN certificate_claims.claimed_at ELSE EXCLUDED.claimed_at END,
+			expires_at = EXCLUDED.expires_at
+		WHERE certificate_claims.claimed_by = EXCLUDED.claimed_by
+			OR certificate_claims.expires_at <= NOW()
+		RETURNING certificate_id, claimed_by, claimed_at, expires_at;
+	`
+	if err := r.db.WithContext(ctx).Raw(query, certificateID, reviewer, ttl.Seconds()).Scan(&claims).Error; err != nil {
+		return nil, fmt.Errorf("claim certificate: %w", err)
 	}
+	if len(claims) == 0 {
+		return nil, ErrCertificateClaimed
+	}
+	return &claims[0], nil
+}
 
+// ReleaseClaim drops the reviewer's live lease on a certificate.
+func (r *certificateRepository) ReleaseClaim(ctx context.Context, certificateID, reviewer string) error {
+	res := r.db.WithContext(ctx).
+		Where("certificate_id = ? AND claimed_by = ? AND expires_at > NOW()", certificateID, reviewer).
+		Delete(&models.CertificateClaim{})
+	if res.Error != nil {
+		return fmt.Errorf("release certificate claim: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrClaimNotHeld
+	}
 	return nil
 }
 
-func (r *certificateRepository) bumpMlVerified(_ context.Context, _ *gorm.DB, _ models.Certificate) error {
-	// Columns pending_certificates and ml_verified_certificates are missing in DB.
-	// Skipping update.
+// recordDecision stores a decision and its attribution on a locked certificate and adjusts stats
+// when it leaves the pending state. extra carries additional columns to set.
+func (r *certificateRepository) recordDecision(ctx context.Context, tx *gorm.DB, cert models.Certificate, reviewer string, status models.FacultyStatus, extra map[string]interface{}) error {
+	updates := map[string]interface{}{
+		"faculty_status": status,
+		"reviewed_by":    reviewer,
+		"reviewed_at":    time.Now().UTC(),
+		// "is_legit":       isLegit, // Missing in DB
+	}
+	for col, val := range extra {
+		updates[col] = val
+	}
+	if err := tx.Model(&cert).Updates(updates).Error; err != nil {
+		return fmt.Errorf("update faculty decision: %w", err)
+	}
+
+	// Adjust stats only when transitioning from pending.
+	if cert.FacultyStatus == models.FacultyStatusPending {
+		if err := r.applyFacultyDecisionStats(ctx, tx, cert, status); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (r *certificateRepository) applyFacultyDecisionStats(ctx context.Context, tx *gorm.DB, cert models.Certificate, status models.FacultyStatus) error {
-	studentUpdates := map[string]interface{}{
-		// "pending_certificates": gorm.Expr("pending_certificates - 1"), // Missing
-	}
-	switch status {
+// newCertificateDelta counts a new certificate in its current state under section.
+func newCertificateDelta(cert models.Certificate, section string) models.StatsDelta {
+	delta := statsDeltaFor(cert, section)
+	delta.TotalUploaded = 1
+	switch cert.FacultyStatus {
 	case models.FacultyStatusLegit:
-		studentUpdates["legit_count"] = gorm.Expr("legit_count + 1")
+		delta.LegitCount = 1
 	case models.FacultyStatusNotLegit:
-		studentUpdates["not_legit_count"] = gorm.Expr("not_legit_count + 1")
+		delta.NotLegitCount = 1
+	default:
+		delta.PendingCount = 1
+	}
+	if cert.MLStatus == models.MLStatusVerified {
+		delta.MLVerifiedCount = 1
 	}
+	return delta
+}
 
-	if len(studentUpdates) > 0 {
-		if err := tx.WithContext(ctx).
-			Model(&models.StudentStatistics{}).
-			Where("reg_no = ?", cert.RegisterNumber).
-			Clauses(clause.Locking{Strength: "UPDATE"}).
-			Updates(studentUpdates).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return ErrStatsNotFound
-			}
-			return fmt.Errorf("update student statistics: %w", err)
-		}
+func (r *certificateRepository) bumpMlVerified(ctx context.Context, tx *gorm.DB, cert models.Certificate) error {
+	sections, err := attributedSections(tx, []string{cert.ID})
+	if err != nil {
+		return err
 	}
+	delta := statsDeltaFor(cert, sections[cert.ID])
+	delta.MLVerifiedCount = 1
+	return r.recordStats(ctx, tx, delta)
+}
 
-	sectionUpdates := map[string]interface{}{
-		// "total_uploaded": gorm.Expr("total_uploaded"), // No change
+func (r *certificateRepository) applyFacultyDecisionStats(ctx context.Context, tx *gorm.DB, cert models.Certificate, status models.FacultyStatus) error {
+	if status != models.FacultyStatusLegit && status != models.FacultyStatusNotLegit {
+		return nil
 	}
-	switch status {
-	case models.FacultyStatusLegit:
-		sectionUpdates["legit_count"] = gorm.Expr("legit_count + 1")
+	sections, err := attributedSections(tx, []string{cert.ID})
+	if err != nil {
+		return err
+	}
+	delta := statsDeltaFor(cert, sections[cert.ID])
+	delta.PendingCount = -1
+	if status == models.FacultyStatusLegit {
+		delta.LegitCount = 1
+	} else {
+		delta.NotLegitCount = 1
 	}
+	return r.recordStats(ctx, tx, delta)
+}
 
-	if len(sectionUpdates) > 0 {
-		if err := tx.WithContext(ctx).
-			Model(&models.SectionStatistics{}).
-			Where("section = ?", cert.Section).
-			Clauses(clause.Locking{Strength: "UPDATE"}).
-			Updates(sectionUpdates).Error; err != nil {
-			if errors.Is(err, gorm.ErrRecordNotFound) {
-				return ErrStatsNotFound
-			}
-			return fmt.Errorf("update section statistics: %w", err)
-		}
+// statsDeltaFor starts a delta for cert counted under section, its attributed section; the
+// section typed at upload is used when section is empty.
+func statsDeltaFor(cert models.Certificate, section string) models.StatsDelta {
+	if section == "" {
+		section = cert.Section
+	}
+	return models.StatsDelta{
+		RegisterNumber: cert.RegisterNumber,
+		StudentName:    cert.StudentName,
+		Section:        section,
+	}
+}
+
+// recordStats appends counter deltas instead of updating the statistics rows, so concurrent
+// uploads and reviews in the same section do not contend on one row lock. Deltas are folded
+// into student_statistics and section_statistics by StatsRepository.FoldDeltas.
+func (r *certificateRepository) recordStats(ctx context.Context, tx *gorm.DB, deltas ...models.StatsDelta) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+	if err := tx.WithContext(ctx).Create(&deltas).Error; err != nil {
+		return fmt.Errorf("record statistics deltas: %w", err)
 	}
 	return nil
 }
@@ -2,10 +2,77 @@ package repositories
 
 import (
 	"context"
+	"time"
 
 	"gorm.io/gorm"
 )
 
+// DashboardFilter narrows every dashboard aggregate to certificates matching all set fields.
+// UploadedFrom is inclusive and UploadedTo exclusive; empty strings are not applied.
+type DashboardFilter struct {
+	UploadedFrom *time.Time
+	UploadedTo   *time.Time
+	Category     string
+	UploadedBy   string
+	Department   string
+}
+
+// conditions renders the filter as SQL appended to a WHERE clause over certificates, adding
+// its named arguments to args.
+func (f DashboardFilter) conditions(args map[string]interface{}) string {
+	sql := ""
+	if f.UploadedFrom != nil {
+		sql += " AND uploaded_at >= @filter_from"
+		args["filter_from"] = *f.UploadedFrom
+	}
+	if f.UploadedTo != nil {
+		sql += " AND uploaded_at < @filter_to"
+		args["filter_to"] = *f.UploadedTo
+	}
+	if f.Category != "" {
+		sql += " AND category = @filter_category"
+		args["filter_category"] = f.Category
+	}
+	if f.UploadedBy != "" {
+		sql += " AND faculty_id = @filter_uploaded_by"
+		args["filter_uploaded_by"] = f.UploadedBy
+	}
+	if f.Department != "" {
+		sql += " AND department = @filter_department"
+		args["filter_department"] = f.Department
+	}
+	return sql
+}
+
+// rosterMembers renders a query over the roster the filter's sections are measured against:
+// register_number, section and is_present of each member. With an upload range, members are
+// the students enrolled at some point within it, in the section of their latest enrollment
+// there, less students deleted before the range ended; otherwise the current roster.
+func (f DashboardFilter) rosterMembers(args map[string]interface{}) string {
+	if f.UploadedFrom == nil && f.UploadedTo == nil {
+		return `
+			SELECT register_number, section, is_present
+			FROM students
+			WHERE deleted_at IS NULL`
+	}
+	fromArg, toArg := "", ""
+	deleted := "s.deleted_at IS NULL"
+	if f.UploadedFrom != nil {
+		fromArg = "filter_from"
+		args[fromArg] = *f.UploadedFrom
+	}
+	if f.UploadedTo != nil {
+		toArg = "filter_to"
+		args[toArg] = *f.UploadedTo
+		deleted = "(s.deleted_at IS NULL OR s.deleted_at >= @filter_to)"
+	}
+	return `
+			SELECT m.register_number, m.section, s.is_present
+			FROM (` + enrollmentsDuring(fromArg, toArg) + `) m
+			JOIN students s ON s.id = m.student_id
+			WHERE ` + deleted
+}
+
 type DashboardOverview struct {
 	TotalStudents        int64
 	TotalCertificates    int64
@@ -14,17 +81,62 @@ type DashboardOverview struct {
 	PendingCertificates  int64
 }
 
+// SectionDashboardRow aggregates one section's certificates and its students roster. Roster
+// counts cover present students only, except TotalStudents and AbsentStudents. Certificates
+// count towards the section their student was enrolled in when they were uploaded.
 type SectionDashboardRow struct {
-	Section              string
-	TotalCertificates    int64
-	VerifiedCertificates int64
-	RejectedCertificates int64
-	PendingCertificates  int64
+	Section               string
+	TotalCertificates     int64
+	VerifiedCertificates  int64
+	RejectedCertificates  int64
+	PendingCertificates   int64
+	TotalStudents         int64
+	PresentStudents       int64
+	AbsentStudents        int64
+	ParticipatingStudents int64
+	NonSubmitters         int64
+}
+
+// TrendRow counts one section's pipeline events within one time bucket.
+type TrendRow struct {
+	Section     string
+	BucketStart time.Time
+	Uploaded    int64
+	MLVerified  int64
+	Legit       int64
+	NotLegit    int64
+}
+
+// TrendQuery selects the events counted by GetTrends. Bucket is a date_trunc unit; From is
+// inclusive and To exclusive. An empty Section covers every section. Filter selects the
+// certificates whose events are counted.
+type TrendQuery struct {
+	Bucket  string
+	From    time.Time
+	To      time.Time
+	Section string
+	Filter  DashboardFilter
+}
+
+// FunnelRow counts one section's certificates at each pipeline stage. Archived certificates
+// count towards Uploaded and Archived only.
+type FunnelRow struct {
+	Section        string
+	Uploaded       int64
+	MLPending      int64
+	MLVerified     int64
+	MLDuplicate    int64
+	FacultyPending int64
+	Legit          int64
+	NotLegit       int64
+	Archived       int64
 }
 
 type DashboardRepository interface {
-	GetOverview(ctx context.Context) (DashboardOverview, error)
-	GetSectionStats(ctx context.Context) ([]SectionDashboardRow, error)
+	GetOverview(ctx context.Context, filter DashboardFilter) (DashboardOverview, error)
+	GetSectionStats(ctx context.Context, filter DashboardFilter) ([]SectionDashboardRow, error)
+	GetTrends(ctx context.Context, q TrendQuery) ([]TrendRow, error)
+	GetFunnel(ctx context.Context, filter DashboardFilter) ([]FunnelRow, error)
 }
 
 type dashboardRepository struct {
@@ -35,7 +147,7 @@ func NewDashboardRepository(db *gorm.DB) DashboardRepository {
 	return &dashboardRepository{db: db}
 }
 
-func (r *dashboardRepository) GetOverview(ctx context.Context) (DashboardOverview, error) {
+func (r *dashboardRepository) GetOverview(ctx context.Context, filter DashboardFilter) (DashboardOverview, error) {
 	// Aggregate solely from certificates table using COUNT + CASE expressions.
 	type aggRow struct {
 		TotalStudents     int64
@@ -47,6 +159,7 @@ func (r *dashboardRepository) GetOverview(ctx context.Context) (DashboardOvervie
 
 	var row aggRow
 
+	args := map[string]interface{}{}
 	query := `
 		SELECT
 			COALESCE(COUNT(DISTINCT reg_no), 0) AS total_students,
@@ -55,10 +168,10 @@ func (r *dashboardRepository) GetOverview(ctx context.Context) (DashboardOvervie
 			COALESCE(COUNT(CASE WHEN faculty_status = 'NOT_LEGIT' THEN 1 END), 0) AS rejected_count,
 			COALESCE(COUNT(CASE WHEN faculty_status = 'PENDING' AND ml_status = 'VERIFIED' THEN 1 END), 0) AS pending_count
 		FROM certificates
-		WHERE archived = false;
+		WHERE archived = false` + filter.conditions(args) + `;
 	`
 
-	if err := r.db.WithContext(ctx).Raw(query).Scan(&row).Error; err != nil {
+	if err := r.db.WithContext(ctx).Raw(query, args).Scan(&row).Error; err != nil {
 		return DashboardOverview{}, err
 	}
 
@@ -71,25 +184,141 @@ func (r *dashboardRepository) GetOverview(ctx context.Context) (DashboardOvervie
 	}, nil
 }
 
-func (r *dashboardRepository) GetSectionStats(ctx context.Context) ([]SectionDashboardRow, error) {
+func (r *dashboardRepository) GetSectionStats(ctx context.Context, filter DashboardFilter) ([]SectionDashboardRow, error) {
 	var rows []SectionDashboardRow
 
+	// Sections appear when they have certificates or roster students. A student participates
+	// with any LEGIT certificate matching the filter, whatever section it was uploaded under.
+	args := map[string]interface{}{}
 	query := `
+		WITH certs AS (
+			SELECT reg_no, enrolled_section AS section, faculty_status, ml_status
+			FROM ` + attributedCertificates + `
+			WHERE archived = false` + filter.conditions(args) + `
+		),
+		members AS (` + filter.rosterMembers(args) + `
+		),
+		cert_stats AS (
+			SELECT
+				section,
+				COUNT(*) AS total_certificates,
+				COUNT(*) FILTER (WHERE faculty_status = 'LEGIT') AS verified_certificates,
+				COUNT(*) FILTER (WHERE faculty_status = 'NOT_LEGIT') AS rejected_certificates,
+				COUNT(*) FILTER (WHERE faculty_status = 'PENDING' AND ml_status = 'VERIFIED') AS pending_certificates
+			FROM certs
+			GROUP BY section
+		),
+		roster AS (
+			SELECT
+				s.section,
+				COUNT(*) AS total_students,
+				COUNT(*) FILTER (WHERE s.is_present) AS present_students,
+				COUNT(*) FILTER (WHERE NOT s.is_present) AS absent_students,
+				COUNT(*) FILTER (WHERE s.is_present AND EXISTS (
+					SELECT 1 FROM certs c WHERE c.reg_no = s.register_number AND c.faculty_status = 'LEGIT'
+				)) AS participating_students,
+				COUNT(*) FILTER (WHERE s.is_present AND NOT EXISTS (
+					SELECT 1 FROM certs c WHERE c.reg_no = s.register_number
+				)) AS non_submitters
+			FROM members s
+			GROUP BY s.section
+		)
+		SELECT
+			COALESCE(cs.section, r.section) AS section,
+			COALESCE(cs.total_certificates, 0) AS total_certificates,
+			COALESCE(cs.verified_certificates, 0) AS verified_certificates,
+			COALESCE(cs.rejected_certificates, 0) AS rejected_certificates,
+			COALESCE(cs.pending_certificates, 0) AS pending_certificates,
+			COALESCE(r.total_students, 0) AS total_students,
+			COALESCE(r.present_students, 0) AS present_students,
+			COALESCE(r.absent_students, 0) AS absent_students,
+			COALESCE(r.participating_students, 0) AS participating_students,
+			COALESCE(r.non_submitters, 0) AS non_submitters
+		FROM cert_stats cs
+		FULL OUTER JOIN roster r ON r.section = cs.section
+		ORDER BY 1;
+	`
+
+	if err := r.db.WithContext(ctx).Raw(query, args).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	return rows, nil
+}
+
+// GetTrends counts uploads, ML verifications and faculty decisions per section and bucket,
+// attributing certificates to the section their student was enrolled in at upload.
+// Each event is bucketed by its own timestamp (uploaded_at, ml_verified_at, reviewed_at), in
+// UTC. Decisions recorded before reviewed_at was tracked have no timestamp and are not counted.
+func (r *dashboardRepository) GetTrends(ctx context.Context, q TrendQuery) ([]TrendRow, error) {
+	var rows []TrendRow
+
+	args := map[string]interface{}{
+		"from":    q.From,
+		"to":      q.To,
+		"bucket":  q.Bucket,
+		"section": q.Section,
+	}
+	filters := q.Filter.conditions(args)
+	query := `
+		WITH events AS (
+			SELECT enrolled_section AS section, uploaded_at AS at, 'UPLOADED' AS kind
+			FROM ` + attributedCertificates + `
+			WHERE archived = false AND uploaded_at >= @from AND uploaded_at < @to` + filters + `
+			UNION ALL
+			SELECT enrolled_section, ml_verified_at, 'ML_VERIFIED'
+			FROM ` + attributedCertificates + `
+			WHERE archived = false AND ml_verified_at >= @from AND ml_verified_at < @to` + filters + `
+			UNION ALL
+			SELECT enrolled_section, reviewed_at, faculty_status::text
+			FROM ` + attributedCertificates + `
+			WHERE archived = false AND faculty_status IN ('LEGIT', 'NOT_LEGIT')
+				AND reviewed_at >= @from AND reviewed_at < @to` + filters + `
+		)
 		SELECT
 			section AS section,
-			COALESCE(COUNT(*), 0) AS total_certificates,
-			COALESCE(COUNT(CASE WHEN faculty_status = 'LEGIT' THEN 1 END), 0) AS verified_certificates,
-			COALESCE(COUNT(CASE WHEN faculty_status = 'NOT_LEGIT' THEN 1 END), 0) AS rejected_certificates,
-			COALESCE(COUNT(CASE WHEN faculty_status = 'PENDING' AND ml_status = 'VERIFIED' THEN 1 END), 0) AS pending_certificates
-		FROM certificates
-		WHERE archived = false
-		GROUP BY section
-		ORDER BY section;
+			date_trunc(@bucket, at AT TIME ZONE 'UTC') AS bucket_start,
+			COUNT(*) FILTER (WHERE kind = 'UPLOADED') AS uploaded,
+			COUNT(*) FILTER (WHERE kind = 'ML_VERIFIED') AS ml_verified,
+			COUNT(*) FILTER (WHERE kind = 'LEGIT') AS legit,
+			COUNT(*) FILTER (WHERE kind = 'NOT_LEGIT') AS not_legit
+		FROM events
+		WHERE @section = '' OR section = @section
+		GROUP BY section, bucket_start
+		ORDER BY section, bucket_start;
 	`
 
-	if err := r.db.WithContext(ctx).Raw(query).Scan(&rows).Error; err != nil {
+	if err := r.db.WithContext(ctx).Raw(query, args).Scan(&rows).Error; err != nil {
 		return nil, err
 	}
+	return rows, nil
+}
+
+// GetFunnel counts certificates per pipeline stage and the section their student was enrolled
+// in at upload.
+func (r *dashboardRepository) GetFunnel(ctx context.Context, filter DashboardFilter) ([]FunnelRow, error) {
+	var rows []FunnelRow
 
+	args := map[string]interface{}{}
+	query := `
+		SELECT
+			enrolled_section AS section,
+			COUNT(*) AS uploaded,
+			COUNT(*) FILTER (WHERE archived = false AND ml_status = 'PENDING') AS ml_pending,
+			COUNT(*) FILTER (WHERE archived = false AND ml_status = 'VERIFIED') AS ml_verified,
+			COUNT(*) FILTER (WHERE archived = false AND ml_status = 'DUPLICATE') AS ml_duplicate,
+			COUNT(*) FILTER (WHERE archived = false AND ml_status = 'VERIFIED' AND faculty_status = 'PENDING') AS faculty_pending,
+			COUNT(*) FILTER (WHERE archived = false AND faculty_status = 'LEGIT') AS legit,
+			COUNT(*) FILTER (WHERE archived = false AND faculty_status = 'NOT_LEGIT') AS not_legit,
+			COUNT(*) FILTER (WHERE archived = true) AS archived
+		FROM ` + attributedCertificates + `
+		WHERE true` + filter.conditions(args) + `
+		GROUP BY enrolled_section
+		ORDER BY enrolled_section;
+	`
+
+	if err := r.db.WithContext(ctx).Raw(query, args).Scan(&rows).Error; err != nil {
+		return nil, err
+	}
 	return rows, nil
 }
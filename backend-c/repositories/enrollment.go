@@ -0,0 +1,142 @@
+package repositories
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"department-eduvault-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// syncEnrollment keeps the open enrollment of an active student in step with its section and
+// semester, ending it and opening the next one at when either differs. It must run in the
+// transaction that wrote student.
+func syncEnrollment(tx *gorm.DB, student *models.Student, at time.Time) error {
+	var open models.StudentEnrollment
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("student_id = ? AND ended_at IS NULL", student.ID).
+		First(&open).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+	case err != nil:
+		return fmt.Errorf("fetch enrollment: %w", err)
+	case open.Section == student.Section && sameSemester(open.Semester, student.Semester):
+		return nil
+	default:
+		if err := tx.Model(&open).Update("ended_at", at).Error; err != nil {
+			return fmt.Errorf("end enrollment: %w", err)
+		}
+	}
+
+	query := `
+		INSERT INTO student_enrollments (student_id, register_number, term_id, section, semester, started_at)
+		VALUES (@student_id, @reg_no, (
+			SELECT t.id FROM academic_terms t
+			WHERE (@at::timestamptz AT TIME ZONE 'UTC')::date BETWEEN t.starts_on AND t.ends_on
+			ORDER BY t.starts_on DESC
+			LIMIT 1
+		), @section, @semester, @at);
+	`
+	args := map[string]interface{}{
+		"student_id": student.ID,
+		"reg_no":     student.RegisterNumber,
+		"section":    student.Section,
+		"semester":   student.Semester,
+		"at":         at,
+	}
+	if err := tx.Exec(query, args).Error; err != nil {
+		return fmt.Errorf("open enrollment: %w", err)
+	}
+	return nil
+}
+
+// endEnrollment ends the open enrollment of a student leaving the roster.
+func endEnrollment(tx *gorm.DB, regNo string, at time.Time) error {
+	if err := tx.Model(&models.StudentEnrollment{}).
+		Where("register_number = ? AND ended_at IS NULL", regNo).
+		Update("ended_at", at).Error; err != nil {
+		return fmt.Errorf("end enrollment: %w", err)
+	}
+	return nil
+}
+
+// linkEnrollments points the given certificates at the enrollment of their student that was
+// active at upload time. Certificates of students not on the roster then stay unlinked.
+func linkEnrollments(tx *gorm.DB, certificateIDs []string) error {
+	if len(certificateIDs) == 0 {
+		return nil
+	}
+	query := `
+		UPDATE certificates c
+		SET enrollment_id = e.id
+		FROM student_enrollments e
+		WHERE c.id IN (@ids)
+			AND e.register_number = c.reg_no
+			AND e.started_at <= c.uploaded_at
+			AND (e.ended_at IS NULL OR e.ended_at > c.uploaded_at);
+	`
+	if err := tx.Exec(query, map[string]interface{}{"ids": certificateIDs}).Error; err != nil {
+		return fmt.Errorf("link certificate enrollments: %w", err)
+	}
+	return nil
+}
+
+// attributedSections maps each of the given certificates to the section it is reported under:
+// the section of its enrollment, or the section typed at upload when it has none.
+func attributedSections(tx *gorm.DB, certificateIDs []string) (map[string]string, error) {
+	var rows []struct {
+		ID      string
+		Section string
+	}
+	query := `
+		SELECT c.id, COALESCE(e.section, c.section) AS section
+		FROM certificates c
+		LEFT JOIN student_enrollments e ON e.id = c.enrollment_id
+		WHERE c.id IN (@ids);
+	`
+	if err := tx.Raw(query, map[string]interface{}{"ids": certificateIDs}).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("query attributed sections: %w", err)
+	}
+	sections := make(map[string]string, len(rows))
+	for _, row := range rows {
+		sections[row.ID] = row.Section
+	}
+	return sections, nil
+}
+
+// enrollmentsDuring renders a query over the latest enrollment of every student enrolled at
+// some point between the named SQL arguments from (inclusive) and to (exclusive). An empty
+// argument name leaves that side of the range open.
+func enrollmentsDuring(fromArg, toArg string) string {
+	where := "true"
+	if toArg != "" {
+		where += " AND e.started_at < @" + toArg
+	}
+	if fromArg != "" {
+		where += " AND (e.ended_at IS NULL OR e.ended_at > @" + fromArg + ")"
+	}
+	return `
+		SELECT DISTINCT ON (e.student_id) e.student_id, e.register_number, e.section, e.semester
+		FROM student_enrollments e
+		WHERE ` + where + `
+		ORDER BY e.student_id, e.started_at DESC`
+}
+
+// attributedCertificates stands in for the certificates table in reports, adding
+// enrolled_section: the section of the enrollment active at upload, falling back to the
+// section typed at upload for certificates without one.
+const attributedCertificates = `(
+		SELECT c.*, COALESCE(e.section, c.section) AS enrolled_section
+		FROM certificates c
+		LEFT JOIN student_enrollments e ON e.id = c.enrollment_id
+	) certificates`
+
+func sameSemester(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return *a == *b
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"department-eduvault-backend/models"
 
@@ -20,9 +21,23 @@ type StudentStatsRow struct {
 	Pending        int64
 }
 
+// FacultyPerformanceRow aggregates one faculty member's uploads and reviews. Decision times
+// are hours from ML verification to the faculty decision and are nil without timed reviews.
+type FacultyPerformanceRow struct {
+	Faculty             string
+	Uploaded            int64
+	Reviewed            int64
+	Legit               int64
+	NotLegit            int64
+	MLDisagreements     int64 `gorm:"column:ml_disagreements"`
+	MedianDecisionHours *float64
+	P90DecisionHours    *float64 `gorm:"column:p90_decision_hours"`
+}
+
 // HodRepository exposes queries used by HOD-facing APIs.
 type HodRepository interface {
 	GetStudentStatsByFaculty(ctx context.Context, facultyID string) ([]StudentStatsRow, error)
+	GetFacultyPerformance(ctx context.Context, from, to *time.Time) ([]FacultyPerformanceRow, error)
 	GetCertificatesByStudent(ctx context.Context, regNo string) ([]models.Certificate, error)
 	GetCertificatesBySection(ctx context.Context, section string) ([]models.Certificate, error)
 }
@@ -98,3 +113,71 @@ func (r *hodRepository) GetCertificatesBySection(ctx context.Context, section st
 
 	return certs, nil
 }
+
+// GetFacultyPerformance aggregates uploads (by uploaded_at) and reviews (by reviewed_at) per
+// faculty member within an optional [from, to) range. Faculty are keyed on the trimmed,
+// lowercased email, so uploads and reviews by one person share a row however the address was
+// typed. Decisions made by the ML policy are not attributed to anyone. A decision disagrees
+// with ML when ML verified a certificate the reviewer rejected, or flagged as duplicate one
+// the reviewer accepted.
+func (r *hodRepository) GetFacultyPerformance(ctx context.Context, from, to *time.Time) ([]FacultyPerformanceRow, error) {
+	args := map[string]interface{}{"system": models.SystemActorMLPolicy}
+	inRange := func(column string) string {
+		cond := ""
+		if from != nil {
+			cond += " AND " + column + " >= @from"
+			args["from"] = *from
+		}
+		if to != nil {
+			cond += " AND " + column + " < @to"
+			args["to"] = *to
+		}
+		return cond
+	}
+
+	var rows []FacultyPerformanceRow
+	query := `
+		WITH uploads AS (
+			SELECT LOWER(BTRIM(faculty_id)) AS faculty, COUNT(*) AS uploaded
+			FROM certificates
+			WHERE archived = false` + inRange("uploaded_at") + `
+			GROUP BY 1
+		),
+		reviews AS (
+			SELECT
+				LOWER(BTRIM(reviewed_by)) AS faculty,
+				COUNT(*) AS reviewed,
+				COUNT(*) FILTER (WHERE faculty_status = 'LEGIT') AS legit,
+				COUNT(*) FILTER (WHERE faculty_status = 'NOT_LEGIT') AS not_legit,
+				COUNT(*) FILTER (
+					WHERE (ml_status = 'VERIFIED' AND faculty_status = 'NOT_LEGIT')
+						OR (ml_status = 'DUPLICATE' AND faculty_status = 'LEGIT')
+				) AS ml_disagreements,
+				percentile_cont(0.5) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM reviewed_at - ml_verified_at) / 3600)
+					FILTER (WHERE ml_verified_at IS NOT NULL AND reviewed_at >= ml_verified_at) AS median_decision_hours,
+				percentile_cont(0.9) WITHIN GROUP (ORDER BY EXTRACT(EPOCH FROM reviewed_at - ml_verified_at) / 3600)
+					FILTER (WHERE ml_verified_at IS NOT NULL AND reviewed_at >= ml_verified_at) AS p90_decision_hours
+			FROM certificates
+			WHERE archived = false
+				AND faculty_status IN ('LEGIT', 'NOT_LEGIT')
+				AND reviewed_by IS NOT NULL AND reviewed_by <> @system` + inRange("reviewed_at") + `
+			GROUP BY 1
+		)
+		SELECT
+			COALESCE(u.faculty, r.faculty) AS faculty,
+			COALESCE(u.uploaded, 0) AS uploaded,
+			COALESCE(r.reviewed, 0) AS reviewed,
+			COALESCE(r.legit, 0) AS legit,
+			COALESCE(r.not_legit, 0) AS not_legit,
+			COALESCE(r.ml_disagreements, 0) AS ml_disagreements,
+			r.median_decision_hours,
+			r.p90_decision_hours
+		FROM uploads u
+		FULL OUTER JOIN reviews r ON r.faculty = u.faculty
+		ORDER BY 1;
+	`
+	if err := r.db.WithContext(ctx).Raw(query, args).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("query faculty performance: %w", err)
+	}
+	return rows, nil
+}
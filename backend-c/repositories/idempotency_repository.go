@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"department-eduvault-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrIdempotencyKeyNotFound is returned when a reserved key disappeared before completion.
+	ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+)
+
+// IdempotencyRepository stores Idempotency-Key reservations and their responses.
+type IdempotencyRepository interface {
+	Reserve(ctx context.Context, actor, key, requestHash string) (*models.IdempotencyKey, bool, error)
+	Complete(ctx context.Context, actor, key string, status int, body string) error
+	Release(ctx context.Context, actor, key string) error
+	// DeleteExpired drops completed keys older than ttl and stale reservations.
+	DeleteExpired(ctx context.Context, ttl time.Duration) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db         *gorm.DB
+	staleAfter time.Duration
+}
+
+// NewIdempotencyRepository constructs an IdempotencyRepository. A reservation that is still
+// incomplete staleAfter past its creation is treated as abandoned (the request crashed before
+// completing or releasing it) and may be claimed again; staleAfter must exceed the longest
+// upload.
+func NewIdempotencyRepository(db *gorm.DB, staleAfter time.Duration) IdempotencyRepository {
+	return &idempotencyRepository{db: db, staleAfter: staleAfter}
+}
+
+// Reserve claims the key for this request, taking over a stale reservation whose certificates
+// were never committed. The boolean is
+// true when the key was reserved; otherwise the existing record is returned for the caller to
+// compare or replay.
+func (r *idempotencyRepository) Reserve(ctx context.Context, actor, key, requestHash string) (*models.IdempotencyKey, bool, error) {
+	// Truncated to the column's precision so the reservation can be matched by created_at.
+	now := time.Now().UTC().Truncate(time.Microsecond)
+	rec := models.IdempotencyKey{
+		ActorEmail:  actor,
+		Key:         key,
+		RequestHash: requestHash,
+		CreatedAt:   now,
+	}
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "actor_email"}, {Name: "key"}},
+		DoUpdates: clause.AssignmentColumns([]string{
+			"request_hash", "response_status", "response_body", "created_at", "completed_at",
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Expr{
+				SQL:  "idempotency_keys.completed_at IS NULL AND idempotency_keys.committed_at IS NULL AND idempotency_keys.created_at < ?",
+				Vars: []interface{}{now.Add(-r.staleAfter)},
+			},
+		}},
+	}).Create(&rec)
+	if res.Error != nil {
+		return nil, false, fmt.Errorf("reserve idempotency key: %w", res.Error)
+	}
+	if res.RowsAffected == 1 {
+		return &rec, true, nil
+	}
+
+	var existing models.IdempotencyKey
+	if err := r.db.WithContext(ctx).First(&existing, "actor_email = ? AND key = ?", actor, key).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, false, ErrIdempotencyKeyNotFound
+		}
+		return nil, false, fmt.Errorf("get idempotency key: %w", err)
+	}
+	return &existing, false, nil
+}
+
+// Complete stores the response to replay for later requests with the same key.
+func (r *idempotencyRepository) Complete(ctx context.Context, actor, key string, status int, body string) error {
+	res := r.db.WithContext(ctx).
+		Model(&models.IdempotencyKey{}).
+		Where("actor_email = ? AND key = ?", actor, key).
+		Updates(map[string]interface{}{
+			"response_status": status,
+			"response_body":   body,
+			"completed_at":    time.Now().UTC(),
+		})
+	if res.Error != nil {
+		return fmt.Errorf("complete idempotency key: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrIdempotencyKeyNotFound
+	}
+	return nil
+}
+
+// Release drops an unfinished reservation so the client can retry after a failure. Committed
+// reservations are kept, since a retry would insert their certificates again.
+func (r *idempotencyRepository) Release(ctx context.Context, actor, key string) error {
+	if err := r.db.WithContext(ctx).
+		Where("actor_email = ? AND key = ? AND completed_at IS NULL AND committed_at IS NULL", actor, key).
+		Delete(&models.IdempotencyKey{}).Error; err != nil {
+		return fmt.Errorf("release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpired removes completed keys whose response is older than ttl, after which a retry
+// with the same key is treated as a new request, and reservations that went stale before
+// committing anything. A committed reservation whose response was never stored is kept for
+// ttl like a completed one.
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context, ttl time.Duration) (int64, error) {
+	now := time.Now().UTC()
+	res := r.db.WithContext(ctx).
+		Where("completed_at < ?", now.Add(-ttl)).
+		Or("completed_at IS NULL AND committed_at < ?", now.Add(-ttl)).
+		Or("completed_at IS NULL AND committed_at IS NULL AND created_at < ?", now.Add(-r.staleAfter)).
+		Delete(&models.IdempotencyKey{})
+	if res.Error != nil {
+		return 0, fmt.Errorf("delete expired idempotency keys: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+// markIdempotencyKeyCommitted records, inside the transaction inserting a reservation's
+// certificates, that they are committed. It matches the reservation by created_at so a request
+// whose stale reservation was taken over cannot commit under the new owner's key.
+func markIdempotencyKeyCommitted(tx *gorm.DB, reservation models.IdempotencyKey) error {
+	res := tx.Model(&models.IdempotencyKey{}).
+		Where("actor_email = ? AND key = ? AND created_at = ? AND completed_at IS NULL AND committed_at IS NULL",
+			reservation.ActorEmail, reservation.Key, reservation.CreatedAt).
+		Update("committed_at", time.Now().UTC())
+	if res.Error != nil {
+		return fmt.Errorf("commit idempotency key: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrIdempotencyKeyNotFound
+	}
+	return nil
+}
@@ -0,0 +1,173 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"department-eduvault-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ImageHashCandidate is a stored hash sharing at least one band with a probe hash.
+type ImageHashCandidate struct {
+	CertificateID  string
+	RegisterNumber string
+	Hash           int64
+}
+
+// NearDuplicateCertificate is one side of a near-duplicate pair.
+type NearDuplicateCertificate struct {
+	ID             string
+	RegisterNumber string
+	StudentName    string
+	Section        string
+	Title          string
+	DriveLink      string
+	UploadedBy     string
+	UploadedAt     time.Time
+}
+
+// NearDuplicatePairRow is a flagged pair with both certificates.
+type NearDuplicatePairRow struct {
+	Distance   int
+	DetectedAt time.Time
+	First      NearDuplicateCertificate
+	Second     NearDuplicateCertificate
+}
+
+// ImageHashRepository stores perceptual hashes and the near-duplicate pairs found with them.
+type ImageHashRepository interface {
+	FindCandidates(ctx context.Context, certificateID, registerNumber string, bands []int16) ([]ImageHashCandidate, error)
+	SaveHash(ctx context.Context, hash *models.CertificateImageHash, bands []int16, pairs []models.NearDuplicatePair) error
+	ListNearDuplicates(ctx context.Context, limit int) ([]NearDuplicatePairRow, error)
+}
+
+type imageHashRepository struct {
+	db *gorm.DB
+}
+
+// NewImageHashRepository constructs an ImageHashRepository.
+func NewImageHashRepository(db *gorm.DB) ImageHashRepository {
+	return &imageHashRepository{db: db}
+}
+
+// FindCandidates returns hashes of other students' active certificates that share a band with
+// the probe. Callers must still check the exact Hamming distance.
+func (r *imageHashRepository) FindCandidates(ctx context.Context, certificateID, registerNumber string, bands []int16) ([]ImageHashCandidate, error) {
+	pairs := make([][]interface{}, 0, len(bands))
+	for i, value := range bands {
+		pairs = append(pairs, []interface{}{i, value})
+	}
+
+	var candidates []ImageHashCandidate
+	err := r.db.WithContext(ctx).
+		Table("certificate_image_hashes h").
+		Select("h.certificate_id, c.reg_no AS register_number, h.hash").
+		Joins("JOIN certificates c ON c.id = h.certificate_id").
+		Where(`EXISTS (
+			SELECT 1 FROM certificate_image_hash_bands b
+			WHERE b.certificate_id = h.certificate_id AND (b.band_no, b.band_value) IN ?
+		)`, pairs).
+		Where("h.certificate_id <> ? AND c.reg_no <> ? AND c.archived = ?", certificateID, registerNumber, false).
+		Scan(&candidates).Error
+	if err != nil {
+		return nil, fmt.Errorf("query image hash candidates: %w", err)
+	}
+	return candidates, nil
+}
+
+// SaveHash stores a certificate's hash and bands and replaces the near-duplicate pairs it is
+// part of, in one transaction.
+func (r *imageHashRepository) SaveHash(ctx context.Context, hash *models.CertificateImageHash, bands []int16, pairs []models.NearDuplicatePair) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "certificate_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"hash", "source", "created_by", "computed_at"}),
+		}).Create(hash).Error; err != nil {
+			return fmt.Errorf("upsert image hash: %w", err)
+		}
+
+		if err := tx.Where("certificate_id = ?", hash.CertificateID).Delete(&models.CertificateImageHashBand{}).Error; err != nil {
+			return fmt.Errorf("delete image hash bands: %w", err)
+		}
+		rows := make([]models.CertificateImageHashBand, 0, len(bands))
+		for i, value := range bands {
+			rows = append(rows, models.CertificateImageHashBand{CertificateID: hash.CertificateID, BandNo: int16(i), BandValue: value})
+		}
+		if err := tx.Create(&rows).Error; err != nil {
+			return fmt.Errorf("insert image hash bands: %w", err)
+		}
+
+		if err := tx.Where("certificate_a = ? OR certificate_b = ?", hash.CertificateID, hash.CertificateID).
+			Delete(&models.NearDuplicatePair{}).Error; err != nil {
+			return fmt.Errorf("delete near-duplicate pairs: %w", err)
+		}
+		if len(pairs) > 0 {
+			if err := tx.Create(&pairs).Error; err != nil {
+				return fmt.Errorf("insert near-duplicate pairs: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ListNearDuplicates returns flagged pairs of active certificates, closest first.
+func (r *imageHashRepository) ListNearDuplicates(ctx context.Context, limit int) ([]NearDuplicatePairRow, error) {
+	var flat []struct {
+		Distance     int
+		DetectedAt   time.Time
+		AID          string    `gorm:"column:a_id"`
+		ARegNo       string    `gorm:"column:a_reg_no"`
+		AStudentName string    `gorm:"column:a_student_name"`
+		ASection     string    `gorm:"column:a_section"`
+		ATitle       string    `gorm:"column:a_title"`
+		ADriveLink   string    `gorm:"column:a_drive_link"`
+		AUploadedBy  string    `gorm:"column:a_uploaded_by"`
+		AUploadedAt  time.Time `gorm:"column:a_uploaded_at"`
+		BID          string    `gorm:"column:b_id"`
+		BRegNo       string    `gorm:"column:b_reg_no"`
+		BStudentName string    `gorm:"column:b_student_name"`
+		BSection     string    `gorm:"column:b_section"`
+		BTitle       string    `gorm:"column:b_title"`
+		BDriveLink   string    `gorm:"column:b_drive_link"`
+		BUploadedBy  string    `gorm:"column:b_uploaded_by"`
+		BUploadedAt  time.Time `gorm:"column:b_uploaded_at"`
+	}
+	query := `
+		SELECT
+			p.distance, p.detected_at,
+			a.id AS a_id, a.reg_no AS a_reg_no, a.student_name AS a_student_name, a.section AS a_section,
+			a.title AS a_title, a.drive_link AS a_drive_link, a.faculty_id AS a_uploaded_by, a.uploaded_at AS a_uploaded_at,
+			b.id AS b_id, b.reg_no AS b_reg_no, b.student_name AS b_student_name, b.section AS b_section,
+			b.title AS b_title, b.drive_link AS b_drive_link, b.faculty_id AS b_uploaded_by, b.uploaded_at AS b_uploaded_at
+		FROM near_duplicate_pairs p
+		JOIN certificates a ON a.id = p.certificate_a
+		JOIN certificates b ON b.id = p.certificate_b
+		WHERE a.archived = false AND b.archived = false
+		ORDER BY p.distance ASC, p.detected_at DESC
+		LIMIT ?;
+	`
+	if err := r.db.WithContext(ctx).Raw(query, limit).Scan(&flat).Error; err != nil {
+		return nil, fmt.Errorf("query near-duplicate pairs: %w", err)
+	}
+
+	rows := make([]NearDuplicatePairRow, 0, len(flat))
+	for _, f := range flat {
+		rows = append(rows, NearDuplicatePairRow{
+			Distance:   f.Distance,
+			DetectedAt: f.DetectedAt,
+			First: NearDuplicateCertificate{
+				ID: f.AID, RegisterNumber: f.ARegNo, StudentName: f.AStudentName, Section: f.ASection,
+				Title: f.ATitle, DriveLink: f.ADriveLink, UploadedBy: f.AUploadedBy, UploadedAt: f.AUploadedAt,
+			},
+			Second: NearDuplicateCertificate{
+				ID: f.BID, RegisterNumber: f.BRegNo, StudentName: f.BStudentName, Section: f.BSection,
+				Title: f.BTitle, DriveLink: f.BDriveLink, UploadedBy: f.BUploadedBy, UploadedAt: f.BUploadedAt,
+			},
+		})
+	}
+	return rows, nil
+}
@@ -0,0 +1,132 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"department-eduvault-backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrImportJobNotFound is returned when an import job lookup fails.
+	ErrImportJobNotFound = errors.New("import job not found")
+)
+
+// ImportJobRepository persists background import jobs and their row errors.
+type ImportJobRepository interface {
+	Create(ctx context.Context, job *models.ImportJob, rowErrors []models.ImportJobError) error
+	GetByID(ctx context.Context, jobID string) (*models.ImportJob, error)
+	ListErrors(ctx context.Context, jobID string) ([]models.ImportJobError, error)
+	MarkRunning(ctx context.Context, jobID string) error
+	AddProgress(ctx context.Context, jobID string, processed int) error
+	Finish(ctx context.Context, jobID string, status models.ImportJobStatus, errMsg string) error
+	FailInterrupted(ctx context.Context) (int64, error)
+}
+
+type importJobRepository struct {
+	db *gorm.DB
+}
+
+// NewImportJobRepository constructs an ImportJobRepository.
+func NewImportJobRepository(db *gorm.DB) ImportJobRepository {
+	return &importJobRepository{db: db}
+}
+
+// Create stores the job together with its validation errors.
+func (r *importJobRepository) Create(ctx context.Context, job *models.ImportJob, rowErrors []models.ImportJobError) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(job).Error; err != nil {
+			return fmt.Errorf("insert import job: %w", err)
+		}
+		if len(rowErrors) == 0 {
+			return nil
+		}
+		for i := range rowErrors {
+			rowErrors[i].JobID = job.ID
+		}
+		if err := tx.CreateInBatches(&rowErrors, 500).Error; err != nil {
+			return fmt.Errorf("insert import job errors: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetByID fetches an import job by ID.
+func (r *importJobRepository) GetByID(ctx context.Context, jobID string) (*models.ImportJob, error) {
+	var job models.ImportJob
+	if err := r.db.WithContext(ctx).First(&job, "id = ?", jobID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrImportJobNotFound
+		}
+		return nil, fmt.Errorf("get import job: %w", err)
+	}
+	return &job, nil
+}
+
+// ListErrors returns row errors for a job in spreadsheet order.
+func (r *importJobRepository) ListErrors(ctx context.Context, jobID string) ([]models.ImportJobError, error) {
+	var rowErrors []models.ImportJobError
+	if err := r.db.WithContext(ctx).
+		Where("job_id = ?", jobID).
+		Order("row_number ASC").Order("id ASC").
+		Find(&rowErrors).Error; err != nil {
+		return nil, fmt.Errorf("query import job errors: %w", err)
+	}
+	return rowErrors, nil
+}
+
+// MarkRunning moves a queued job to RUNNING.
+func (r *importJobRepository) MarkRunning(ctx context.Context, jobID string) error {
+	return r.update(ctx, jobID, map[string]interface{}{
+		"status":     models.ImportJobRunning,
+		"started_at": time.Now().UTC(),
+	})
+}
+
+// AddProgress increments the number of inserted rows.
+func (r *importJobRepository) AddProgress(ctx context.Context, jobID string, processed int) error {
+	return r.update(ctx, jobID, map[string]interface{}{
+		"processed_rows": gorm.Expr("processed_rows + ?", processed),
+	})
+}
+
+// Finish records the terminal status of a job.
+func (r *importJobRepository) Finish(ctx context.Context, jobID string, status models.ImportJobStatus, errMsg string) error {
+	return r.update(ctx, jobID, map[string]interface{}{
+		"status":      status,
+		"error":       errMsg,
+		"finished_at": time.Now().UTC(),
+	})
+}
+
+// FailInterrupted marks every QUEUED or RUNNING job as FAILED. Jobs run in the server
+// process, so at startup any such job was cut off by a restart and will never finish.
+func (r *importJobRepository) FailInterrupted(ctx context.Context) (int64, error) {
+	res := r.db.WithContext(ctx).
+		Model(&models.ImportJob{}).
+		Where("status IN ?", []models.ImportJobStatus{models.ImportJobQueued, models.ImportJobRunning}).
+		Updates(map[string]interface{}{
+			"status":      models.ImportJobFailed,
+			"error":       "interrupted by a server restart; rows up to processed_rows were imported",
+			"finished_at": time.Now().UTC(),
+		})
+	if res.Error != nil {
+		return 0, fmt.Errorf("fail interrupted import jobs: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
+
+func (r *importJobRepository) update(ctx context.Context, jobID string, updates map[string]interface{}) error {
+	res := r.db.WithContext(ctx).Model(&models.ImportJob{}).Where("id = ?", jobID).Updates(updates)
+	if res.Error != nil {
+		return fmt.Errorf("update import job: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrImportJobNotFound
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"department-eduvault-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrAcademicTermNotFound is returned when no term has the requested name.
+	ErrAcademicTermNotFound = errors.New("academic term not found")
+	// ErrAcademicTermExists is returned when creating a term whose name is taken.
+	ErrAcademicTermExists = errors.New("academic term already exists")
+)
+
+// Leaderboard metrics.
+const (
+	LeaderboardMetricLegit    = "legit"
+	LeaderboardMetricPoints   = "points"
+	LeaderboardMetricCategory = "category"
+)
+
+// LeaderboardQuery selects and ranks students by their LEGIT certificates.
+//
+// With PerSection, students are ranked within the section of their latest certificate and
+// Limit applies to each section; otherwise they are ranked department-wide. Section restricts
+// the result to one section. Category is required by the category metric. From/To bound
+// uploaded_at when set (To exclusive).
+type LeaderboardQuery struct {
+	Metric     string
+	Category   string
+	PerSection bool
+	Section    string
+	From       *time.Time
+	To         *time.Time
+	Limit      int
+}
+
+// LeaderboardRow is one ranked student. Percentile is PERCENT_RANK over ascending score,
+// (rank - 1) / (n - 1): the top score gets 1, the lowest gets 0, and so does a student ranked
+// alone.
+type LeaderboardRow struct {
+	Rank           int64
+	Percentile     float64
+	RegisterNumber string
+	StudentName    string
+	Section        string
+	LegitCount     int64
+	Points         float64
+	Score          float64
+}
+
+// LeaderboardRepository ranks students and stores the terms and category weights it uses.
+type LeaderboardRepository interface {
+	GetLeaderboard(ctx context.Context, q LeaderboardQuery) ([]LeaderboardRow, error)
+	ListTerms(ctx context.Context) ([]models.AcademicTerm, error)
+	GetTermByName(ctx context.Context, name string) (*models.AcademicTerm, error)
+	CreateTerm(ctx context.Context, term *models.AcademicTerm) error
+	ListCategoryPoints(ctx context.Context) ([]models.CategoryPoints, error)
+	UpsertCategoryPoints(ctx context.Context, points *models.CategoryPoints) error
+}
+
+type leaderboardRepository struct {
+	db *gorm.DB
+}
+
+// NewLeaderboardRepository constructs a LeaderboardRepository.
+func NewLeaderboardRepository(db *gorm.DB) LeaderboardRepository {
+	return &leaderboardRepository{db: db}
+}
+
+// GetLeaderboard ranks students with at least one active LEGIT certificate matching q. Ties
+// share a rank (RANK), so more than Limit students may be returned.
+func (r *leaderboardRepository) GetLeaderboard(ctx context.Context, q LeaderboardQuery) ([]LeaderboardRow, error) {
+	args := map[string]interface{}{
+		"default_points": models.DefaultCategoryPoints,
+		"limit":          q.Limit,
+	}
+	filters := ""
+	if q.Metric == LeaderboardMetricCategory {
+		filters += " AND c.category = @category"
+		args["category"] = q.Category
+	}
+	if q.From != nil {
+		filters += " AND c.uploaded_at >= @from"
+		args["from"] = *q.From
+	}
+	if q.To != nil {
+		filters += " AND c.uploaded_at < @to"
+		args["to"] = *q.To
+	}
+
+	score := "legit_count"
+	if q.Metric == LeaderboardMetricPoints {
+		score = "points"
+	}
+	partition := ""
+	if q.PerSection {
+		partition = "PARTITION BY section"
+	}
+	orderBy := "rank, register_number"
+	if q.PerSection {
+		orderBy = "section, " + orderBy
+	}
+	sectionFilter := ""
+	if q.Section != "" {
+		sectionFilter = "WHERE section = @section"
+		args["section"] = q.Section
+	}
+
+	var rows []LeaderboardRow
+	query := `
+		WITH scored AS (
+			SELECT
+				c.reg_no AS register_number,
+				(array_agg(c.student_name ORDER BY c.uploaded_at DESC))[1] AS student_name,
+				(array_agg(COALESCE(e.section, c.section) ORDER BY c.uploaded_at DESC))[1] AS section,
+				COUNT(*) AS legit_count,
+				SUM(COALESCE(p.points, @default_points)) AS points
+			FROM certificates c
+			LEFT JOIN category_points p ON p.category = c.category
+			LEFT JOIN student_enrollments e ON e.id = c.enrollment_id
+			WHERE c.archived = false AND c.faculty_status = 'LEGIT'` + filters + `
+			GROUP BY c.reg_no
+		),
+		ranked AS (
+			SELECT
+				*,
+				` + score + `::float8 AS score,
+				RANK() OVER (` + partition + ` ORDER BY ` + score + ` DESC) AS rank,
+				PERCENT_RANK() OVER (` + partition + ` ORDER BY ` + score + ` ASC) AS percentile
+			FROM scored
+			` + sectionFilter + `
+		)
+		SELECT rank, percentile, register_number, student_name, section, legit_count, points, score
+		FROM ranked
+		WHERE rank <= @limit
+		ORDER BY ` + orderBy + `;
+	`
+	if err := r.db.WithContext(ctx).Raw(query, args).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("query leaderboard: %w", err)
+	}
+	return rows, nil
+}
+
+// ListTerms returns every academic term, latest first.
+func (r *leaderboardRepository) ListTerms(ctx context.Context) ([]models.AcademicTerm, error) {
+	var terms []models.AcademicTerm
+	if err := r.db.WithContext(ctx).Order("starts_on DESC").Find(&terms).Error; err != nil {
+		return nil, fmt.Errorf("query academic terms: %w", err)
+	}
+	return terms, nil
+}
+
+// GetTermByName looks up a term by its unique name.
+func (r *leaderboardRepository) GetTermByName(ctx context.Context, name string) (*models.AcademicTerm, error) {
+	var term models.AcademicTerm
+	if err := r.db.WithContext(ctx).Where("name = ?", name).First(&term).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAcademicTermNotFound
+		}
+		return nil, fmt.Errorf("get academic term: %w", err)
+	}
+	return &term, nil
+}
+
+// CreateTerm stores a new term, failing with ErrAcademicTermExists if the name is taken.
+func (r *leaderboardRepository) CreateTerm(ctx context.Context, term *models.AcademicTerm) error {
+	res := r.db.WithContext(ctx).Clauses(clause.OnConflict{DoNothing: true}).Create(term)
+	if res.Error != nil {
+		return fmt.Errorf("insert academic term: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrAcademicTermExists
+	}
+	return nil
+}
+
+// ListCategoryPoints returns the configured category weights.
+func (r *leaderboardRepository) ListCategoryPoints(ctx context.Context) ([]models.CategoryPoints, error) {
+	var points []models.CategoryPoints
+	if err := r.db.WithContext(ctx).Order("category").Find(&points).Error; err != nil {
+		return nil, fmt.Errorf("query category points: %w", err)
+	}
+	return points, nil
+}
+
+// UpsertCategoryPoints creates or replaces the weight of a category.
+func (r *leaderboardRepository) UpsertCategoryPoints(ctx context.Context, points *models.CategoryPoints) error {
+	points.UpdatedAt = time.Now().UTC()
+	err := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "category"}},
+		DoUpdates: clause.AssignmentColumns([]string{"points", "updated_by", "updated_at"}),
+	}).Create(points).Error
+	if err != nil {
+		return fmt.Errorf("upsert category points: %w", err)
+	}
+	return nil
+}
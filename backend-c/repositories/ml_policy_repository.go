@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"department-eduvault-backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrMLPolicyNotFound is returned when no ML decision policy has been configured yet.
+	ErrMLPolicyNotFound = errors.New("ml decision policy not found")
+)
+
+// MLPolicyRepository stores versioned ML decision policies. The highest version is active.
+type MLPolicyRepository interface {
+	GetActive(ctx context.Context) (*models.MLDecisionPolicy, error)
+	ListVersions(ctx context.Context) ([]models.MLDecisionPolicy, error)
+	Create(ctx context.Context, policy *models.MLDecisionPolicy) error
+}
+
+type mlPolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewMLPolicyRepository constructs an MLPolicyRepository.
+func NewMLPolicyRepository(db *gorm.DB) MLPolicyRepository {
+	return &mlPolicyRepository{db: db}
+}
+
+// GetActive returns the latest policy version.
+func (r *mlPolicyRepository) GetActive(ctx context.Context) (*models.MLDecisionPolicy, error) {
+	var policy models.MLDecisionPolicy
+	if err := r.db.WithContext(ctx).Order("version DESC").First(&policy).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrMLPolicyNotFound
+		}
+		return nil, fmt.Errorf("get ml decision policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// ListVersions returns every policy version, newest first.
+func (r *mlPolicyRepository) ListVersions(ctx context.Context) ([]models.MLDecisionPolicy, error) {
+	var policies []models.MLDecisionPolicy
+	if err := r.db.WithContext(ctx).Order("version DESC").Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("query ml decision policies: %w", err)
+	}
+	return policies, nil
+}
+
+// Create stores a new policy version; earlier versions are kept for attribution.
+func (r *mlPolicyRepository) Create(ctx context.Context, policy *models.MLDecisionPolicy) error {
+	if err := r.db.WithContext(ctx).Create(policy).Error; err != nil {
+		return fmt.Errorf("insert ml decision policy: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,32 @@
+package repositories
+
+import "sync"
+
+// MutationListener is called after a write to a repository's tables has been committed.
+type MutationListener func()
+
+// mutationListeners is embedded by repositories whose writes invalidate derived views.
+type mutationListeners struct {
+	mu        sync.RWMutex
+	listeners []MutationListener
+}
+
+// OnMutation registers listener to run after every committed write.
+func (m *mutationListeners) OnMutation(listener MutationListener) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, listener)
+}
+
+// committed notifies the listeners when err is nil and passes err through.
+func (m *mutationListeners) committed(err error) error {
+	if err != nil {
+		return err
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	for _, listener := range m.listeners {
+		listener()
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"department-eduvault-backend/models"
+
+	"gorm.io/gorm"
+)
+
+// ErrQuotaRuleNotFound is returned when a quota rule lookup fails.
+var ErrQuotaRuleNotFound = errors.New("quota rule not found")
+
+// ComplianceQuery selects the students and term a compliance report covers. From is
+// inclusive and To exclusive; empty Section and Batch cover every section and batch.
+type ComplianceQuery struct {
+	TermID  int
+	From    time.Time
+	To      time.Time
+	Section string
+	Batch   string
+}
+
+// ComplianceRow is one student's progress against one applicable quota rule. A nil Category
+// counts LEGIT certificates of any category.
+type ComplianceRow struct {
+	RegisterNumber string
+	StudentName    string
+	Section        string
+	Batch          string
+	FacultyEmail   string
+	RuleID         int
+	Category       *string
+	Required       int64
+	Earned         int64
+}
+
+// QuotaRepository stores quota rules and measures students against them.
+type QuotaRepository interface {
+	ListRules(ctx context.Context, termID int) ([]models.QuotaRule, error)
+	UpsertRule(ctx context.Context, rule *models.QuotaRule) error
+	DeleteRule(ctx context.Context, id int) error
+	GetCompliance(ctx context.Context, q ComplianceQuery) ([]ComplianceRow, error)
+}
+
+type quotaRepository struct {
+	db *gorm.DB
+}
+
+// NewQuotaRepository constructs a QuotaRepository.
+func NewQuotaRepository(db *gorm.DB) QuotaRepository {
+	return &quotaRepository{db: db}
+}
+
+// ListRules returns the rules of a term, general ones first. A zero termID lists every term.
+func (r *quotaRepository) ListRules(ctx context.Context, termID int) ([]models.QuotaRule, error) {
+	query := r.db.WithContext(ctx)
+	if termID != 0 {
+		query = query.Where("term_id = ?", termID)
+	}
+	var rules []models.QuotaRule
+	if err := query.
+		Order("term_id").Order("batch NULLS FIRST").Order("section NULLS FIRST").Order("category NULLS FIRST").
+		Find(&rules).Error; err != nil {
+		return nil, fmt.Errorf("query quota rules: %w", err)
+	}
+	return rules, nil
+}
+
+// UpsertRule creates the rule for its (term, batch, section, category) scope or replaces its
+// minimum.
+func (r *quotaRepository) UpsertRule(ctx context.Context, rule *models.QuotaRule) error {
+	query := `
+		INSERT INTO quota_rules (term_id, batch, section, category, min_legit, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, NOW())
+		ON CONFLICT (term_id, COALESCE(batch, ''), COALESCE(section, ''), COALESCE(category, '')) DO UPDATE SET
+			min_legit = EXCLUDED.min_legit,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, term_id, batch, section, category, min_legit, updated_by, updated_at;
+	`
+	err := r.db.WithContext(ctx).
+		Raw(query, rule.TermID, rule.Batch, rule.Section, rule.Category, rule.MinLegit, rule.UpdatedBy).
+		Scan(rule).Error
+	if err != nil {
+		return fmt.Errorf("upsert quota rule: %w", err)
+	}
+	return nil
+}
+
+// DeleteRule removes a quota rule by ID.
+func (r *quotaRepository) DeleteRule(ctx context.Context, id int) error {
+	res := r.db.WithContext(ctx).Delete(&models.QuotaRule{}, id)
+	if res.Error != nil {
+		return fmt.Errorf("delete quota rule: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrQuotaRuleNotFound
+	}
+	return nil
+}
+
+// GetCompliance measures every present student enrolled during the term against its quota
+// rules, in the section of their latest enrollment within the term; students deleted before
+// the term ended are left out. For each student and category only the most specific matching
+// rule is kept; students without an applicable rule are omitted. Earned counts non-archived
+// LEGIT certificates uploaded within the term.
+func (r *quotaRepository) GetCompliance(ctx context.Context, q ComplianceQuery) ([]ComplianceRow, error) {
+	var rows []ComplianceRow
+	query := `
+		WITH roster AS (
+			SELECT m.register_number, s.name, m.section, s.batch, s.faculty_email
+			FROM (` + enrollmentsDuring("from", "to") + `) m
+			JOIN students s ON s.id = m.student_id
+			WHERE s.is_present AND (s.deleted_at IS NULL OR s.deleted_at >= @to)
+				AND (@section = '' OR m.section = @section)
+				AND (@batch = '' OR s.batch = @batch)
+		),
+		applicable AS (
+			SELECT DISTINCT ON (s.register_number, COALESCE(q.category, ''))
+				s.register_number, q.id AS rule_id, q.category, q.min_legit
+			FROM roster s
+			JOIN quota_rules q
+				ON q.term_id = @term_id
+				AND (q.batch IS NULL OR q.batch = s.batch)
+				AND (q.section IS NULL OR q.section = s.section)
+			ORDER BY s.register_number, COALESCE(q.category, ''),
+				(q.section IS NOT NULL)::int * 2 + (q.batch IS NOT NULL)::int DESC
+		),
+		earned AS (
+			SELECT reg_no, category, COUNT(*) AS legit_count
+			FROM certificates
+			WHERE archived = false AND faculty_status = 'LEGIT'
+				AND uploaded_at >= @from AND uploaded_at < @to
+				AND reg_no IN (SELECT register_number FROM roster)
+			GROUP BY reg_no, category
+		)
+		SELECT
+			s.register_number,
+			s.name AS student_name,
+			s.section,
+			s.batch,
+			s.faculty_email,
+			a.rule_id,
+			a.category,
+			a.min_legit AS required,
+			COALESCE((
+				SELECT SUM(e.legit_count) FROM earned e
+				WHERE e.reg_no = s.register_number AND (a.category IS NULL OR e.category = a.category)
+			), 0) AS earned
+		FROM roster s
+		JOIN applicable a ON a.register_number = s.register_number
+		ORDER BY s.section, s.register_number, a.category NULLS FIRST;
+	`
+	args := map[string]interface{}{
+		"term_id": q.TermID,
+		"from":    q.From,
+		"to":      q.To,
+		"section": q.Section,
+		"batch":   q.Batch,
+	}
+	if err := r.db.WithContext(ctx).Raw(query, args).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("query quota compliance: %w", err)
+	}
+	return rows, nil
+}
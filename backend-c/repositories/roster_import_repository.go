@@ -0,0 +1,248 @@
+package repositories
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"department-eduvault-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrRosterImportNotFound is returned when a roster import lookup fails.
+	ErrRosterImportNotFound = errors.New("roster import not found")
+	// ErrRosterImportApplied is returned when confirming an import that was already applied.
+	ErrRosterImportApplied = errors.New("roster import has already been applied")
+	// ErrRosterChanged is returned when the roster changed after an import was previewed.
+	ErrRosterChanged = errors.New("the roster has changed since this import was previewed; upload it again")
+)
+
+// RosterUpdate is one roster student changed by an import, as previewed.
+type RosterUpdate struct {
+	Before models.Student
+	After  models.Student
+}
+
+// RosterApplyPlan is the set of roster writes a confirmed import makes. Remove is only
+// populated when RemoveMissing was requested.
+type RosterApplyPlan struct {
+	Add           []models.Student
+	Update        []RosterUpdate
+	Remove        []models.Student
+	RemoveMissing bool
+}
+
+// RosterImportRepository persists roster import previews and applies them to the students
+// table.
+type RosterImportRepository interface {
+	// RosterVersion fingerprints the active roster; it changes with every student write.
+	RosterVersion(ctx context.Context) (string, error)
+	Create(ctx context.Context, rosterImport *models.RosterImport) error
+	GetByID(ctx context.Context, id string) (*models.RosterImport, error)
+	List(ctx context.Context, limit int) ([]models.RosterImport, error)
+	ListChanges(ctx context.Context, importID string) ([]models.RosterChange, error)
+	Apply(ctx context.Context, importID, appliedBy string, plan RosterApplyPlan) (*models.RosterImport, error)
+	// OnMutation registers a listener run after every applied import.
+	OnMutation(listener MutationListener)
+}
+
+type rosterImportRepository struct {
+	db *gorm.DB
+	mutationListeners
+}
+
+// NewRosterImportRepository constructs a RosterImportRepository.
+func NewRosterImportRepository(db *gorm.DB) RosterImportRepository {
+	return &rosterImportRepository{db: db}
+}
+
+// RosterVersion hashes the ID and last update time of every active student.
+func (r *rosterImportRepository) RosterVersion(ctx context.Context) (string, error) {
+	return rosterVersion(r.db.WithContext(ctx))
+}
+
+func rosterVersion(db *gorm.DB) (string, error) {
+	var version string
+	query := `
+		SELECT COALESCE(md5(string_agg(id::text || ':' || extract(epoch FROM updated_at)::text, ',' ORDER BY id)), '')
+		FROM students
+		WHERE deleted_at IS NULL;
+	`
+	if err := db.Raw(query).Scan(&version).Error; err != nil {
+		return "", fmt.Errorf("query roster version: %w", err)
+	}
+	return version, nil
+}
+
+// Create stores a roster import preview.
+func (r *rosterImportRepository) Create(ctx context.Context, rosterImport *models.RosterImport) error {
+	if err := r.db.WithContext(ctx).Create(rosterImport).Error; err != nil {
+		return fmt.Errorf("insert roster import: %w", err)
+	}
+	return nil
+}
+
+// GetByID fetches a roster import by ID.
+func (r *rosterImportRepository) GetByID(ctx context.Context, id string) (*models.RosterImport, error) {
+	var rosterImport models.RosterImport
+	if err := r.db.WithContext(ctx).First(&rosterImport, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRosterImportNotFound
+		}
+		return nil, fmt.Errorf("get roster import: %w", err)
+	}
+	return &rosterImport, nil
+}
+
+// List returns the latest roster imports, newest first, without their diffs.
+func (r *rosterImportRepository) List(ctx context.Context, limit int) ([]models.RosterImport, error) {
+	var imports []models.RosterImport
+	if err := r.db.WithContext(ctx).
+		Omit("diff").
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&imports).Error; err != nil {
+		return nil, fmt.Errorf("query roster imports: %w", err)
+	}
+	return imports, nil
+}
+
+// ListChanges returns the student changes recorded when an import was applied.
+func (r *rosterImportRepository) ListChanges(ctx context.Context, importID string) ([]models.RosterChange, error) {
+	var changes []models.RosterChange
+	if err := r.db.WithContext(ctx).
+		Where("import_id = ?", importID).
+		Order("id ASC").
+		Find(&changes).Error; err != nil {
+		return nil, fmt.Errorf("query roster changes: %w", err)
+	}
+	return changes, nil
+}
+
+// Apply makes the writes of plan and records them as the import's history in one transaction.
+// The students table is locked against concurrent writes and must still match the version
+// the import was previewed against, otherwise nothing is applied and ErrRosterChanged is
+// returned.
+func (r *rosterImportRepository) Apply(ctx context.Context, importID, appliedBy string, plan RosterApplyPlan) (*models.RosterImport, error) {
+	var rosterImport models.RosterImport
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&rosterImport, "id = ?", importID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrRosterImportNotFound
+			}
+			return fmt.Errorf("lock roster import: %w", err)
+		}
+		if rosterImport.Status != models.RosterImportPending {
+			return ErrRosterImportApplied
+		}
+
+		if err := tx.Exec("LOCK TABLE students IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+			return fmt.Errorf("lock students: %w", err)
+		}
+		version, err := rosterVersion(tx)
+		if err != nil {
+			return err
+		}
+		if version != rosterImport.RosterVersion {
+			return ErrRosterChanged
+		}
+
+		now := time.Now().UTC()
+		changes := make([]models.RosterChange, 0, len(plan.Add)+len(plan.Update)+len(plan.Remove))
+		record := func(regNo string, action models.RosterChangeAction, before, after *models.Student) error {
+			change := models.RosterChange{
+				ImportID:       rosterImport.ID,
+				RegisterNumber: regNo,
+				Action:         action,
+				CreatedAt:      now,
+			}
+			var err error
+			if before != nil {
+				if change.Before, err = json.Marshal(before); err != nil {
+					return fmt.Errorf("encode roster change: %w", err)
+				}
+			}
+			if after != nil {
+				if change.After, err = json.Marshal(after); err != nil {
+					return fmt.Errorf("encode roster change: %w", err)
+				}
+			}
+			changes = append(changes, change)
+			return nil
+		}
+
+		for i := range plan.Add {
+			student := plan.Add[i]
+			if err := insertOrRestoreStudent(tx, &student); err != nil {
+				if errors.Is(err, ErrStudentExists) {
+					return ErrRosterChanged
+				}
+				return err
+			}
+			if err := record(student.RegisterNumber, models.RosterChangeAdded, nil, &student); err != nil {
+				return err
+			}
+		}
+		for i := range plan.Update {
+			before, after := plan.Update[i].Before, plan.Update[i].After
+			res := tx.Model(&after).Select("*").Omit("id", "created_at", "deleted_at").Updates(&after)
+			if res.Error != nil {
+				return fmt.Errorf("update student: %w", res.Error)
+			}
+			if res.RowsAffected == 0 {
+				return ErrRosterChanged
+			}
+			if err := syncEnrollment(tx, &after, now); err != nil {
+				return err
+			}
+			if err := record(after.RegisterNumber, models.RosterChangeUpdated, &before, &after); err != nil {
+				return err
+			}
+		}
+		for i := range plan.Remove {
+			student := plan.Remove[i]
+			res := tx.Where("register_number = ?", student.RegisterNumber).Delete(&models.Student{})
+			if res.Error != nil {
+				return fmt.Errorf("delete student: %w", res.Error)
+			}
+			if res.RowsAffected == 0 {
+				return ErrRosterChanged
+			}
+			if err := endEnrollment(tx, student.RegisterNumber, now); err != nil {
+				return err
+			}
+			if err := record(student.RegisterNumber, models.RosterChangeRemoved, &student, nil); err != nil {
+				return err
+			}
+		}
+
+		if len(changes) > 0 {
+			if err := tx.CreateInBatches(&changes, 500).Error; err != nil {
+				return fmt.Errorf("insert roster changes: %w", err)
+			}
+		}
+
+		rosterImport.Status = models.RosterImportApplied
+		rosterImport.RemoveMissing = plan.RemoveMissing
+		rosterImport.AppliedBy = &appliedBy
+		rosterImport.AppliedAt = &now
+		if err := tx.Model(&rosterImport).Updates(map[string]interface{}{
+			"status":         rosterImport.Status,
+			"remove_missing": rosterImport.RemoveMissing,
+			"applied_by":     appliedBy,
+			"applied_at":     now,
+		}).Error; err != nil {
+			return fmt.Errorf("mark roster import applied: %w", err)
+		}
+		return nil
+	})
+	if err := r.committed(err); err != nil {
+		return nil, err
+	}
+	return &rosterImport, nil
+}
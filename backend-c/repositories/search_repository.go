@@ -0,0 +1,145 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SearchScope restricts search results to what the caller may see.
+// An empty FacultyEmail means department-wide (HOD) access.
+type SearchScope struct {
+	FacultyEmail string
+}
+
+// StudentSearchRow is a roster student matched by name or register number.
+type StudentSearchRow struct {
+	RegisterNumber string
+	StudentName    string
+	Section        string
+	Score          float64
+}
+
+// CertificateSearchRow is a certificate matched by student, register number, title, issuer or drive file ID.
+type CertificateSearchRow struct {
+	ID             string
+	RegisterNumber string
+	StudentName    string
+	Section        string
+	Title          string
+	Issuer         string
+	DriveLink      string
+	DriveFileID    string
+	MLStatus       string
+	FacultyStatus  string
+	UploadedAt     time.Time
+	Score          float64
+}
+
+// SearchRepository runs ranked full-text and trigram queries.
+type SearchRepository interface {
+	SearchStudents(ctx context.Context, q string, scope SearchScope, limit int) ([]StudentSearchRow, error)
+	SearchCertificates(ctx context.Context, q string, scope SearchScope, limit int) ([]CertificateSearchRow, error)
+}
+
+type searchRepository struct {
+	db *gorm.DB
+}
+
+// NewSearchRepository creates a search repository instance.
+func NewSearchRepository(db *gorm.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// SearchStudents matches roster students using trigram word similarity, so partial names
+// and register number fragments still hit the GIN indexes.
+func (r *searchRepository) SearchStudents(ctx context.Context, q string, scope SearchScope, limit int) ([]StudentSearchRow, error) {
+	var rows []StudentSearchRow
+	query := `
+		SELECT
+			s.register_number,
+			s.name AS student_name,
+			s.section,
+			GREATEST(word_similarity(@q, s.name), word_similarity(@q, s.register_number)) AS score
+		FROM students s
+		WHERE (@q <% s.name OR @q <% s.register_number OR s.register_number ILIKE @like)
+			AND s.deleted_at IS NULL
+			AND (@email = '' OR s.faculty_email = @email)
+		ORDER BY score DESC, s.register_number
+		LIMIT @limit;
+	`
+
+	if err := r.db.WithContext(ctx).Raw(query, searchArgs(q, scope, limit)).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("search students: %w", err)
+	}
+	return rows, nil
+}
+
+// SearchCertificates ranks certificates by the better of full-text rank and trigram similarity.
+func (r *searchRepository) SearchCertificates(ctx context.Context, q string, scope SearchScope, limit int) ([]CertificateSearchRow, error) {
+	var rows []CertificateSearchRow
+	query := `
+		SELECT
+			c.id,
+			c.reg_no AS register_number,
+			c.student_name,
+			c.section,
+			c.title,
+			c.issuer,
+			c.drive_link,
+			COALESCE(c.drive_file_id, '') AS drive_file_id,
+			c.ml_status,
+			c.faculty_status,
+			c.uploaded_at,
+			GREATEST(
+				ts_rank(c.search_vector, websearch_to_tsquery('simple', @q)),
+				word_similarity(@q, c.student_name),
+				word_similarity(@q, c.reg_no),
+				word_similarity(@q, c.title),
+				word_similarity(@q, c.issuer),
+				word_similarity(@q, COALESCE(c.drive_file_id, ''))
+			) AS score
+		FROM certificates c
+		WHERE c.archived = false
+			AND (
+				c.search_vector @@ websearch_to_tsquery('simple', @q)
+				OR @q <% c.student_name
+				OR @q <% c.title
+				OR @q <% c.issuer
+				OR c.reg_no ILIKE @like
+				OR c.drive_file_id ILIKE @like
+			)
+			AND (
+				@email = ''
+				OR c.faculty_id = @email
+				OR EXISTS (
+					SELECT 1 FROM students s
+					WHERE s.register_number = c.reg_no AND s.faculty_email = @email AND s.deleted_at IS NULL
+				)
+			)
+		ORDER BY score DESC, c.uploaded_at DESC
+		LIMIT @limit;
+	`
+
+	if err := r.db.WithContext(ctx).Raw(query, searchArgs(q, scope, limit)).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("search certificates: %w", err)
+	}
+	return rows, nil
+}
+
+func searchArgs(q string, scope SearchScope, limit int) map[string]interface{} {
+	return map[string]interface{}{
+		"q":     q,
+		"like":  "%" + escapeLike(q) + "%",
+		"email": scope.FacultyEmail,
+		"limit": limit,
+	}
+}
+
+// escapeLike neutralizes LIKE wildcards in user input.
+func escapeLike(val string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(val)
+}
@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"department-eduvault-backend/models"
+
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrSLAPolicyNotFound is returned when an SLA policy lookup fails.
+	ErrSLAPolicyNotFound = errors.New("sla policy not found")
+)
+
+// slaQueueSource selects the faculty review queue with each certificate's waiting time and the
+// threshold of its most specific SLA policy (section+category, section, category, global).
+// It expects the named argument @default_hours for certificates no policy covers.
+const slaQueueSource = `
+	SELECT
+		c.*,
+		EXTRACT(EPOCH FROM (NOW() - COALESCE(c.ml_verified_at, c.uploaded_at))) / 3600.0 AS waiting_hours,
+		COALESCE(p.threshold_hours, @default_hours) AS sla_hours
+	FROM certificates c
+	LEFT JOIN LATERAL (
+		SELECT threshold_hours
+		FROM review_sla_policies p
+		WHERE (p.section IS NULL OR p.section = c.section)
+			AND (p.category IS NULL OR p.category = c.category)
+		ORDER BY (p.section IS NOT NULL) DESC, (p.category IS NOT NULL) DESC
+		LIMIT 1
+	) p ON true
+	WHERE c.ml_status = 'VERIFIED' AND c.faculty_status = 'PENDING' AND c.archived = false
+`
+
+// ReviewAgeingRow summarizes how long a section's review queue has been waiting.
+type ReviewAgeingRow struct {
+	Section            string
+	Pending            int64
+	UnderOneDay        int64
+	OneToThreeDays     int64
+	ThreeToSevenDays   int64
+	OverSevenDays      int64
+	OverSLA            int64
+	Escalated          int64
+	OldestWaitingHours float64
+}
+
+// EscalatedCertificateRow is a certificate sitting in the HOD escalation queue.
+type EscalatedCertificateRow struct {
+	ID               string
+	RegisterNumber   string
+	StudentName      string
+	Section          string
+	Category         string
+	Title            string
+	DriveLink        string
+	UploadedBy       string
+	EscalatedAt      time.Time
+	EscalationReason string
+	WaitingHours     float64
+	SLAHours         int
+}
+
+// ReviewSLARepository stores SLA policies and runs queue ageing and escalation queries.
+type ReviewSLARepository interface {
+	ListPolicies(ctx context.Context) ([]models.ReviewSLAPolicy, error)
+	UpsertPolicy(ctx context.Context, policy *models.ReviewSLAPolicy) error
+	DeletePolicy(ctx context.Context, id int) error
+	GetAgeingReport(ctx context.Context, defaultHours int) ([]ReviewAgeingRow, error)
+	ListEscalated(ctx context.Context, defaultHours int) ([]EscalatedCertificateRow, error)
+	EscalateOverdue(ctx context.Context, defaultHours int) (int64, error)
+}
+
+type reviewSLARepository struct {
+	db *gorm.DB
+}
+
+// NewReviewSLARepository constructs a ReviewSLARepository.
+func NewReviewSLARepository(db *gorm.DB) ReviewSLARepository {
+	return &reviewSLARepository{db: db}
+}
+
+// ListPolicies returns all SLA policies, general ones first.
+func (r *reviewSLARepository) ListPolicies(ctx context.Context) ([]models.ReviewSLAPolicy, error) {
+	var policies []models.ReviewSLAPolicy
+	if err := r.db.WithContext(ctx).
+		Order("section NULLS FIRST").Order("category NULLS FIRST").
+		Find(&policies).Error; err != nil {
+		return nil, fmt.Errorf("query sla policies: %w", err)
+	}
+	return policies, nil
+}
+
+// UpsertPolicy creates the policy for its (section, category) scope or replaces its threshold.
+func (r *reviewSLARepository) UpsertPolicy(ctx context.Context, policy *models.ReviewSLAPolicy) error {
+	query := `
+		INSERT INTO review_sla_policies (section, category, threshold_hours, updated_by, updated_at)
+		VALUES (?, ?, ?, ?, NOW())
+		ON CONFLICT (COALESCE(section, ''), COALESCE(category, '')) DO UPDATE SET
+			threshold_hours = EXCLUDED.threshold_hours,
+			updated_by = EXCLUDED.updated_by,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id, section, category, threshold_hours, updated_by, updated_at;
+	`
+	err := r.db.WithContext(ctx).
+		Raw(query, policy.Section, policy.Category, policy.ThresholdHours, policy.UpdatedBy).
+		Scan(policy).Error
+	if err != nil {
+		return fmt.Errorf("upsert sla policy: %w", err)
+	}
+	return nil
+}
+
+// DeletePolicy removes an SLA policy by ID.
+func (r *reviewSLARepository) DeletePolicy(ctx context.Context, id int) error {
+	res := r.db.WithContext(ctx).Delete(&models.ReviewSLAPolicy{}, id)
+	if res.Error != nil {
+		return fmt.Errorf("delete sla policy: %w", res.Error)
+	}
+	if res.RowsAffected == 0 {
+		return ErrSLAPolicyNotFound
+	}
+	return nil
+}
+
+// GetAgeingReport buckets the pending review queue per section by time waited.
+func (r *reviewSLARepository) GetAgeingReport(ctx context.Context, defaultHours int) ([]ReviewAgeingRow, error) {
+	var rows []ReviewAgeingRow
+	query := `
+		WITH queue AS (` + slaQueueSource + `)
+		SELECT
+			section,
+			COUNT(*) AS pending,
+			COUNT(*) FILTER (WHERE waiting_hours < 24) AS under_one_day,
+			COUNT(*) FILTER (WHERE waiting_hours >= 24 AND waiting_hours < 72) AS one_to_three_days,
+			COUNT(*) FILTER (WHERE waiting_hours >= 72 AND waiting_hours < 168) AS three_to_seven_days,
+			COUNT(*) FILTER (WHERE waiting_hours >= 168) AS over_seven_days,
+			COUNT(*) FILTER (WHERE waiting_hours > sla_hours) AS over_sla,
+			COUNT(*) FILTER (WHERE escalated_at IS NOT NULL) AS escalated,
+			COALESCE(MAX(waiting_hours), 0) AS oldest_waiting_hours
+		FROM queue
+		GROUP BY section
+		ORDER BY section;
+	`
+	if err := r.db.WithContext(ctx).Raw(query, map[string]interface{}{"default_hours": defaultHours}).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("query review ageing: %w", err)
+	}
+	return rows, nil
+}
+
+// ListEscalated returns pending certificates that were escalated to the HOD, oldest first.
+func (r *reviewSLARepository) ListEscalated(ctx context.Context, defaultHours int) ([]EscalatedCertificateRow, error) {
+	var rows []EscalatedCertificateRow
+	query := `
+		WITH queue AS (` + slaQueueSource + `)
+		SELECT
+			id,
+			reg_no AS register_number,
+			student_name,
+			section,
+			category,
+			title,
+			drive_link,
+			faculty_id AS uploaded_by,
+			escalated_at,
+			escalation_reason,
+			waiting_hours,
+			sla_hours
+		FROM queue
+		WHERE escalated_at IS NOT NULL
+		ORDER BY escalated_at ASC, id ASC;
+	`
+	if err := r.db.WithContext(ctx).Raw(query, map[string]interface{}{"default_hours": defaultHours}).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("query escalated certificates: %w", err)
+	}
+	return rows, nil
+}
+
+// EscalateOverdue marks every pending certificate past its SLA as escalated and returns how
+// many were newly escalated. Already escalated certificates are left untouched.
+func (r *reviewSLARepository) EscalateOverdue(ctx context.Context, defaultHours int) (int64, error) {
+	query := `
+		WITH due AS (
+			SELECT id, waiting_hours, sla_hours
+			FROM (` + slaQueueSource + `) queue
+			WHERE escalated_at IS NULL AND waiting_hours > sla_hours
+		)
+		UPDATE certificates c
+		SET escalated_at = NOW(),
+			escalation_reason = format('waited %s hours for faculty review (SLA %s hours)', floor(due.waiting_hours)::int, due.sla_hours)
+		FROM due
+		WHERE c.id = due.id;
+	`
+	res := r.db.WithContext(ctx).Exec(query, map[string]interface{}{"default_hours": defaultHours})
+	if res.Error != nil {
+		return 0, fmt.Errorf("escalate overdue certificates: %w", res.Error)
+	}
+	return res.RowsAffected, nil
+}
@@ -0,0 +1,367 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	StudentStatisticsTable = "student_statistics"
+	SectionStatisticsTable = "section_statistics"
+)
+
+// StatsCounts are the counters kept in the statistics tables.
+type StatsCounts struct {
+	TotalUploaded   int64 `json:"total_uploaded"`
+	PendingCount    int64 `json:"pending_count"`
+	LegitCount      int64 `json:"legit_count"`
+	NotLegitCount   int64 `json:"not_legit_count"`
+	MLVerifiedCount int64 `json:"ml_verified_count"`
+}
+
+// StatsDrift is a statistics row whose counters, including unfolded deltas, disagree with
+// the certificates table. Stored is nil when the row is missing altogether.
+type StatsDrift struct {
+	Table  string       `json:"table"`
+	Key    string       `json:"key"`
+	Stored *StatsCounts `json:"stored"`
+	Actual StatsCounts  `json:"actual"`
+}
+
+// StatsReconcileResult summarizes a reconciliation run.
+type StatsReconcileResult struct {
+	DryRun   bool         `json:"dry_run"`
+	Drift    []StatsDrift `json:"drift"`
+	Inserted int64        `json:"inserted"`
+	Updated  int64        `json:"updated"`
+}
+
+// StatsRepository folds statistics deltas into student_statistics and section_statistics and
+// recomputes both tables from certificates.
+type StatsRepository interface {
+	FoldDeltas(ctx context.Context, batchSize int) (int64, error)
+	Diff(ctx context.Context) ([]StatsDrift, error)
+	Reconcile(ctx context.Context, dryRun bool) (StatsReconcileResult, error)
+}
+
+type statsRepository struct {
+	db *gorm.DB
+}
+
+// NewStatsRepository constructs a StatsRepository.
+func NewStatsRepository(db *gorm.DB) StatsRepository {
+	return &statsRepository{db: db}
+}
+
+// statsTarget describes one statistics table, its key column and the certificate column
+// counted under that key. Sections are the enrolled section of attributedCertificates, the
+// same attribution the dashboards use, so a certificate stays in the section its student was
+// enrolled in at upload even when a different section was typed or the student later moved.
+// Student rows also carry the name and section of the student's latest upload.
+type statsTarget struct {
+	table        string
+	keyColumn    string
+	certColumn   string
+	withIdentity bool
+}
+
+var statsTargets = []statsTarget{
+	{table: StudentStatisticsTable, keyColumn: "reg_no", certColumn: "reg_no", withIdentity: true},
+	{table: SectionStatisticsTable, keyColumn: "section", certColumn: "enrolled_section"},
+}
+
+// statsCounters are the counter columns shared by both tables.
+var statsCounters = []string{"total_uploaded", "pending_count", "legit_count", "not_legit_count", "ml_verified_count"}
+
+// actualStatsCTE aggregates active certificates per key; archived certificates are not
+// counted, matching the dashboards.
+func actualStatsCTE(t statsTarget) string {
+	identity := ""
+	if t.withIdentity {
+		identity = `
+				(array_agg(student_name ORDER BY uploaded_at DESC))[1] AS student_name,
+				(array_agg(enrolled_section ORDER BY uploaded_at DESC))[1] AS section,`
+	}
+	return `
+		actual AS (
+			SELECT
+				` + t.certColumn + ` AS key,` + identity + `
+				COUNT(*) AS total_uploaded,
+				COUNT(*) FILTER (WHERE faculty_status = 'PENDING') AS pending_count,
+				COUNT(*) FILTER (WHERE faculty_status = 'LEGIT') AS legit_count,
+				COUNT(*) FILTER (WHERE faculty_status = 'NOT_LEGIT') AS not_legit_count,
+				COUNT(*) FILTER (WHERE ml_status = 'VERIFIED') AS ml_verified_count
+			FROM ` + attributedCertificates + `
+			WHERE archived = false
+			GROUP BY ` + t.certColumn + `
+		)`
+}
+
+// countersDiffer is a predicate that is true when any counter of s differs from a.
+func countersDiffer() string {
+	conds := make([]string, 0, len(statsCounters))
+	for _, col := range statsCounters {
+		conds = append(conds, "s."+col+" IS DISTINCT FROM COALESCE(a."+col+", 0)")
+	}
+	return "(" + strings.Join(conds, " OR ") + ")"
+}
+
+// storedStatsCTE is each key's effective stored counters: the statistics row plus its
+// deltas that have not been folded yet. Reading both in one statement keeps the sum consistent
+// with the certificates the same statement sees.
+func storedStatsCTE(t statsTarget) string {
+	sums := make([]string, 0, len(statsCounters))
+	totals := make([]string, 0, len(statsCounters))
+	for _, col := range statsCounters {
+		sums = append(sums, "SUM("+col+") AS "+col)
+		totals = append(totals, "COALESCE(t."+col+", 0) + COALESCE(d."+col+", 0) AS "+col)
+	}
+	return `
+		unfolded AS (
+			SELECT ` + t.keyColumn + ` AS key, ` + strings.Join(sums, ", ") + `
+			FROM stats_deltas
+			GROUP BY ` + t.keyColumn + `
+		),
+		stored AS (
+			SELECT COALESCE(t.` + t.keyColumn + `, d.key) AS key, ` + strings.Join(totals, ", ") + `
+			FROM ` + t.table + ` t
+			FULL OUTER JOIN unfolded d ON d.key = t.` + t.keyColumn + `
+		)`
+}
+
+// FoldDeltas moves up to batchSize of the oldest statistics deltas into the statistics tables
+// in a single statement and returns how many were folded. Concurrent folders skip each
+// other's rows, and statistics rows are upserted in key order to avoid deadlocks between them.
+func (r *statsRepository) FoldDeltas(ctx context.Context, batchSize int) (int64, error) {
+	upsertCounters := func(alias string) string {
+		sets := make([]string, 0, len(statsCounters))
+		for _, col := range statsCounters {
+			sets = append(sets, col+" = "+alias+"."+col+" + EXCLUDED."+col)
+		}
+		return strings.Join(sets, ",\n\t\t\t\t")
+	}
+	sums := make([]string, 0, len(statsCounters))
+	for _, col := range statsCounters {
+		sums = append(sums, "SUM("+col+")")
+	}
+	counters := strings.Join(statsCounters, ", ")
+
+	query := `
+		WITH folded AS (
+			DELETE FROM stats_deltas
+			WHERE id IN (
+				SELECT id FROM stats_deltas ORDER BY id LIMIT ? FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		),
+		students AS (
+			INSERT INTO student_statistics AS s (reg_no, student_name, section, ` + counters + `, last_updated)
+			SELECT
+				reg_no,
+				COALESCE((array_agg(student_name ORDER BY id DESC) FILTER (WHERE total_uploaded > 0))[1], ''),
+				COALESCE((array_agg(section ORDER BY id DESC) FILTER (WHERE total_uploaded > 0))[1], ''),
+				` + strings.Join(sums, ", ") + `,
+				NOW()
+			FROM folded
+			GROUP BY reg_no
+			ORDER BY reg_no
+			ON CONFLICT (reg_no) DO UPDATE SET
+				student_name = CASE WHEN EXCLUDED.student_name <> '' THEN EXCLUDED.student_name ELSE s.student_name END,
+				section = CASE WHEN EXCLUDED.section <> '' THEN EXCLUDED.section ELSE s.section END,
+				` + upsertCounters("s") + `,
+				last_updated = NOW()
+			RETURNING 1
+		),
+		sections AS (
+			INSERT INTO section_statistics AS s (section, ` + counters + `, last_updated)
+			SELECT section, ` + strings.Join(sums, ", ") + `, NOW()
+			FROM folded
+			GROUP BY section
+			ORDER BY section
+			ON CONFLICT (section) DO UPDATE SET
+				` + upsertCounters("s") + `,
+				last_updated = NOW()
+			RETURNING 1
+		)
+		SELECT COUNT(*) FROM folded;
+	`
+	var folded int64
+	if err := r.db.WithContext(ctx).Raw(query, batchSize).Scan(&folded).Error; err != nil {
+		return 0, fmt.Errorf("fold statistics deltas: %w", err)
+	}
+	return folded, nil
+}
+
+// Diff compares both statistics tables with the certificates table without changing anything.
+func (r *statsRepository) Diff(ctx context.Context) ([]StatsDrift, error) {
+	return r.diff(r.db.WithContext(ctx))
+}
+
+// Reconcile rebuilds both statistics tables from certificates in one transaction and returns
+// the rows that differed. New deltas are blocked for the duration, and every delta already
+// committed is discarded because the rebuild accounts for its certificate; uploads and
+// decisions committed after the run keep their deltas, so nothing is lost or double counted.
+// With dryRun the differences are reported and nothing is written.
+func (r *statsRepository) Reconcile(ctx context.Context, dryRun bool) (StatsReconcileResult, error) {
+	result := StatsReconcileResult{DryRun: dryRun}
+	if dryRun {
+		drift, err := r.Diff(ctx)
+		result.Drift = drift
+		return result, err
+	}
+
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("LOCK TABLE stats_deltas IN EXCLUSIVE MODE").Error; err != nil {
+			return fmt.Errorf("lock statistics deltas: %w", err)
+		}
+
+		drift, err := r.diff(tx)
+		if err != nil {
+			return err
+		}
+		result.Drift = drift
+
+		if err := tx.Exec("DELETE FROM stats_deltas").Error; err != nil {
+			return fmt.Errorf("discard statistics deltas: %w", err)
+		}
+
+		for _, t := range statsTargets {
+			updated, inserted, err := r.rebuild(tx, t)
+			if err != nil {
+				return err
+			}
+			result.Updated += updated
+			result.Inserted += inserted
+		}
+		return nil
+	})
+	if err != nil {
+		return StatsReconcileResult{DryRun: dryRun}, err
+	}
+	return result, nil
+}
+
+func (r *statsRepository) diff(db *gorm.DB) ([]StatsDrift, error) {
+	var drift []StatsDrift
+	for _, t := range statsTargets {
+		var rows []struct {
+			Key                   string
+			Missing               bool
+			StoredTotalUploaded   int64
+			StoredPendingCount    int64
+			StoredLegitCount      int64
+			StoredNotLegitCount   int64
+			StoredMLVerifiedCount int64 `gorm:"column:stored_ml_verified_count"`
+			TotalUploaded         int64
+			PendingCount          int64
+			LegitCount            int64
+			NotLegitCount         int64
+			MLVerifiedCount       int64 `gorm:"column:ml_verified_count"`
+		}
+		query := `
+			WITH ` + actualStatsCTE(t) + `,` + storedStatsCTE(t) + `
+			SELECT
+				COALESCE(a.key, s.key) AS key,
+				s.key IS NULL AS missing,
+				COALESCE(s.total_uploaded, 0) AS stored_total_uploaded,
+				COALESCE(s.pending_count, 0) AS stored_pending_count,
+				COALESCE(s.legit_count, 0) AS stored_legit_count,
+				COALESCE(s.not_legit_count, 0) AS stored_not_legit_count,
+				COALESCE(s.ml_verified_count, 0) AS stored_ml_verified_count,
+				COALESCE(a.total_uploaded, 0) AS total_uploaded,
+				COALESCE(a.pending_count, 0) AS pending_count,
+				COALESCE(a.legit_count, 0) AS legit_count,
+				COALESCE(a.not_legit_count, 0) AS not_legit_count,
+				COALESCE(a.ml_verified_count, 0) AS ml_verified_count
+			FROM actual a
+			FULL OUTER JOIN stored s ON s.key = a.key
+			WHERE s.key IS NULL OR ` + countersDiffer() + `
+			ORDER BY 1;
+		`
+		if err := db.Raw(query).Scan(&rows).Error; err != nil {
+			return nil, fmt.Errorf("diff %s: %w", t.table, err)
+		}
+
+		for _, row := range rows {
+			d := StatsDrift{
+				Table: t.table,
+				Key:   row.Key,
+				Actual: StatsCounts{
+					TotalUploaded:   row.TotalUploaded,
+					PendingCount:    row.PendingCount,
+					LegitCount:      row.LegitCount,
+					NotLegitCount:   row.NotLegitCount,
+					MLVerifiedCount: row.MLVerifiedCount,
+				},
+			}
+			if !row.Missing {
+				d.Stored = &StatsCounts{
+					TotalUploaded:   row.StoredTotalUploaded,
+					PendingCount:    row.StoredPendingCount,
+					LegitCount:      row.StoredLegitCount,
+					NotLegitCount:   row.StoredNotLegitCount,
+					MLVerifiedCount: row.StoredMLVerifiedCount,
+				}
+			}
+			drift = append(drift, d)
+		}
+	}
+	return drift, nil
+}
+
+// rebuild corrects drifted rows (zeroing rows with no certificates left) and inserts rows
+// for keys that have certificates but no statistics row.
+func (r *statsRepository) rebuild(tx *gorm.DB, t statsTarget) (updated, inserted int64, err error) {
+	sets := make([]string, 0, len(statsCounters)+2)
+	for _, col := range statsCounters {
+		sets = append(sets, col+" = COALESCE(a."+col+", 0)")
+	}
+	columns := append([]string{t.keyColumn}, statsCounters...)
+	values := append([]string{"a.key"}, prefixed("a.", statsCounters)...)
+	changed := countersDiffer()
+	if t.withIdentity {
+		sets = append(sets, "student_name = COALESCE(a.student_name, s.student_name)", "section = COALESCE(a.section, s.section)")
+		columns = append(columns, "student_name", "section")
+		values = append(values, "a.student_name", "a.section")
+		changed = "(" + changed + " OR s.student_name IS DISTINCT FROM COALESCE(a.student_name, s.student_name)" +
+			" OR s.section IS DISTINCT FROM COALESCE(a.section, s.section))"
+	}
+
+	update := `
+		WITH ` + actualStatsCTE(t) + `
+		UPDATE ` + t.table + ` s
+		SET ` + strings.Join(sets, ", ") + `, last_updated = NOW()
+		FROM ` + t.table + ` s2
+		LEFT JOIN actual a ON a.key = s2.` + t.keyColumn + `
+		WHERE s.ctid = s2.ctid AND ` + changed + `;
+	`
+	res := tx.Exec(update)
+	if res.Error != nil {
+		return 0, 0, fmt.Errorf("update %s: %w", t.table, res.Error)
+	}
+	updated = res.RowsAffected
+
+	insert := `
+		WITH ` + actualStatsCTE(t) + `
+		INSERT INTO ` + t.table + ` (` + strings.Join(columns, ", ") + `, last_updated)
+		SELECT ` + strings.Join(values, ", ") + `, NOW()
+		FROM actual a
+		WHERE NOT EXISTS (SELECT 1 FROM ` + t.table + ` s WHERE s.` + t.keyColumn + ` = a.key);
+	`
+	res = tx.Exec(insert)
+	if res.Error != nil {
+		return 0, 0, fmt.Errorf("insert %s: %w", t.table, res.Error)
+	}
+	return updated, res.RowsAffected, nil
+}
+
+func prefixed(prefix string, columns []string) []string {
+	out := make([]string, len(columns))
+	for i, col := range columns {
+		out[i] = prefix + col
+	}
+	return out
+}
@@ -0,0 +1,240 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"department-eduvault-backend/internal/db"
+	"department-eduvault-backend/models"
+	"department-eduvault-backend/repositories"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	loadStudents = 40
+	loadUploads  = 10
+	loadWorkers  = 32
+)
+
+// TestStatsFoldUnderConcurrentWrites uploads, ML-verifies and reviews certificates from many
+// workers in one synthetic section while deltas are folded in the background, then checks the
+// folded statistics rows against a recount of the certificates table. It needs a migrated
+// database in TEST_DATABASE_URL and removes its synthetic rows afterwards.
+func TestStatsFoldUnderConcurrentWrites(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	database, err := db.Connect(dsn)
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+
+	ctx := context.Background()
+	certRepo := repositories.NewCertificateRepository(database)
+	statsRepo := repositories.NewStatsRepository(database)
+
+	runID := uuid.NewString()[:8]
+	section := "LOADTEST-" + runID
+	t.Cleanup(func() { cleanupStats(t, database, section) })
+
+	regNos := make([]string, loadStudents)
+	for i := range regNos {
+		regNos[i] = fmt.Sprintf("LT%s%04d", runID, i)
+	}
+	type job struct {
+		regNo string
+		seq   int
+	}
+	jobs := make(chan job)
+	go func() {
+		for i := 0; i < loadUploads; i++ {
+			for _, regNo := range regNos {
+				jobs <- job{regNo: regNo, seq: i}
+			}
+		}
+		close(jobs)
+	}()
+
+	// Fold continuously so folding races with writers, as it does in production.
+	foldCtx, stopFolding := context.WithCancel(ctx)
+	foldDone := make(chan struct{})
+	go func() {
+		defer close(foldDone)
+		for foldCtx.Err() == nil {
+			if _, err := statsRepo.FoldDeltas(foldCtx, 500); err != nil && !errors.Is(err, context.Canceled) {
+				t.Errorf("fold: %v", err)
+			}
+			time.Sleep(50 * time.Millisecond)
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for w := 0; w < loadWorkers; w++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			rng := rand.New(rand.NewSource(seed))
+			for j := range jobs {
+				if err := runStatsJob(ctx, certRepo, rng, section, j.regNo, j.seq); err != nil {
+					t.Errorf("%s #%d: %v", j.regNo, j.seq, err)
+				}
+			}
+		}(int64(w))
+	}
+	wg.Wait()
+
+	stopFolding()
+	<-foldDone
+	for {
+		folded, err := statsRepo.FoldDeltas(ctx, 5000)
+		if err != nil {
+			t.Fatalf("final fold: %v", err)
+		}
+		if folded == 0 {
+			break
+		}
+	}
+
+	want := recountStudents(t, database, section)
+	if len(want) != loadStudents {
+		t.Fatalf("recounted %d students, want %d", len(want), loadStudents)
+	}
+
+	var studentRows []models.StudentStatistics
+	if err := database.WithContext(ctx).Where("section = ?", section).Find(&studentRows).Error; err != nil {
+		t.Fatalf("load student statistics: %v", err)
+	}
+	if len(studentRows) != len(want) {
+		t.Errorf("student statistics rows = %d, want %d", len(studentRows), len(want))
+	}
+	var sectionWant repositories.StatsCounts
+	for _, c := range want {
+		addCounts(&sectionWant, c)
+	}
+	for _, row := range studentRows {
+		got := repositories.StatsCounts{
+			TotalUploaded:   int64(row.TotalCertificates),
+			PendingCount:    int64(row.PendingCertificates),
+			LegitCount:      int64(row.LegitCertificates),
+			NotLegitCount:   int64(row.NotLegitCertificates),
+			MLVerifiedCount: int64(row.MlVerifiedCertificates),
+		}
+		if got != want[row.RegisterNumber] {
+			t.Errorf("student %s: folded %+v, recounted %+v", row.RegisterNumber, got, want[row.RegisterNumber])
+		}
+	}
+
+	var sectionRow models.SectionStatistics
+	if err := database.WithContext(ctx).Where("section = ?", section).First(&sectionRow).Error; err != nil {
+		t.Fatalf("load section statistics: %v", err)
+	}
+	got := repositories.StatsCounts{
+		TotalUploaded:   int64(sectionRow.TotalCertificates),
+		PendingCount:    int64(sectionRow.PendingCertificates),
+		LegitCount:      int64(sectionRow.LegitCertificates),
+		NotLegitCount:   int64(sectionRow.NotLegitCertificates),
+		MLVerifiedCount: int64(sectionRow.MlVerifiedCertificates),
+	}
+	if got != sectionWant {
+		t.Errorf("section %s: folded %+v, recounted %+v", section, got, sectionWant)
+	}
+}
+
+// runStatsJob uploads one certificate, ML-verifies most of them and reviews some.
+func runStatsJob(ctx context.Context, repo repositories.CertificateRepository, rng *rand.Rand, section, regNo string, seq int) error {
+	cert := models.Certificate{
+		ID:             uuid.NewString(),
+		DriveLink:      fmt.Sprintf("https://drive.google.com/file/d/loadtest-%s-%d/view", regNo, seq),
+		RegisterNumber: regNo,
+		Section:        section,
+		StudentName:    "Load Test " + regNo,
+		Title:          fmt.Sprintf("Load test certificate %d", seq),
+		Category:       models.DefaultCertificateCategory,
+		UploadedBy:     "loadtest@citchennai.net",
+		UploadedAt:     time.Now().UTC(),
+		MLStatus:       models.MLStatusPending,
+		FacultyStatus:  models.FacultyStatusPending,
+	}
+	if err := repo.CreateCertificates(ctx, []models.Certificate{cert}); err != nil {
+		return fmt.Errorf("upload: %w", err)
+	}
+	if rng.Intn(10) < 8 {
+		if err := repo.UpdateMLStatus(ctx, cert.ID, models.MLStatusVerified, nil, nil); err != nil {
+			return fmt.Errorf("ml verify: %w", err)
+		}
+	}
+	switch rng.Intn(3) {
+	case 0:
+		if err := repo.UpdateFacultyDecision(ctx, cert.ID, "loadtest@citchennai.net", models.FacultyStatusLegit, true); err != nil {
+			return fmt.Errorf("review: %w", err)
+		}
+	case 1:
+		if err := repo.UpdateFacultyDecision(ctx, cert.ID, "loadtest@citchennai.net", models.FacultyStatusNotLegit, false); err != nil {
+			return fmt.Errorf("review: %w", err)
+		}
+	}
+	return nil
+}
+
+// recountStudents counts the certificates attributed to the section per student straight
+// from the certificates table.
+func recountStudents(t *testing.T, database *gorm.DB, section string) map[string]repositories.StatsCounts {
+	t.Helper()
+	var rows []struct {
+		RegNo string
+		repositories.StatsCounts
+	}
+	query := `
+		SELECT
+			c.reg_no,
+			COUNT(*) AS total_uploaded,
+			COUNT(*) FILTER (WHERE c.faculty_status = 'PENDING') AS pending_count,
+			COUNT(*) FILTER (WHERE c.faculty_status = 'LEGIT') AS legit_count,
+			COUNT(*) FILTER (WHERE c.faculty_status = 'NOT_LEGIT') AS not_legit_count,
+			COUNT(*) FILTER (WHERE c.ml_status = 'VERIFIED') AS ml_verified_count
+		FROM certificates c
+		LEFT JOIN student_enrollments e ON e.id = c.enrollment_id
+		WHERE c.archived = false AND COALESCE(e.section, c.section) = ?
+		GROUP BY c.reg_no;
+	`
+	if err := database.Raw(query, section).Scan(&rows).Error; err != nil {
+		t.Fatalf("recount certificates: %v", err)
+	}
+	counts := make(map[string]repositories.StatsCounts, len(rows))
+	for _, row := range rows {
+		counts[row.RegNo] = row.StatsCounts
+	}
+	return counts
+}
+
+func addCounts(dst *repositories.StatsCounts, c repositories.StatsCounts) {
+	dst.TotalUploaded += c.TotalUploaded
+	dst.PendingCount += c.PendingCount
+	dst.LegitCount += c.LegitCount
+	dst.NotLegitCount += c.NotLegitCount
+	dst.MLVerifiedCount += c.MLVerifiedCount
+}
+
+// cleanupStats removes the synthetic certificates, their statistics rows and leftover deltas.
+func cleanupStats(t *testing.T, database *gorm.DB, section string) {
+	statements := []string{
+		"DELETE FROM stats_deltas WHERE section = ?",
+		"DELETE FROM certificates WHERE section = ?",
+		"DELETE FROM student_statistics WHERE section = ?",
+		"DELETE FROM section_statistics WHERE section = ?",
+	}
+	for _, stmt := range statements {
+		if err := database.Exec(stmt, section).Error; err != nil {
+			t.Logf("cleanup: %v", err)
+		}
+	}
+}
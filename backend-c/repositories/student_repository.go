@@ -0,0 +1,417 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"department-eduvault-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	// ErrStudentNotFound is returned when no roster student has the requested register number.
+	ErrStudentNotFound = errors.New("student not found")
+	// ErrStudentExists is returned when creating a student whose register number is on the roster.
+	ErrStudentExists = errors.New("student already exists")
+)
+
+// StudentListFilter narrows the roster listing; zero-valued fields are not applied.
+type StudentListFilter struct {
+	Section      string
+	Batch        string
+	FacultyEmail string
+	Present      *bool
+}
+
+// StudentStatusTotals counts a student's active certificates by pipeline state.
+type StudentStatusTotals struct {
+	Total          int64
+	MLPending      int64
+	MLVerified     int64
+	MLDuplicate    int64
+	FacultyPending int64
+	Legit          int64
+	NotLegit       int64
+	LastUploadedAt *time.Time
+	LastReviewedAt *time.Time
+}
+
+// StudentCategoryRow counts a student's active certificates of one category.
+type StudentCategoryRow struct {
+	Category string
+	Total    int64
+	Legit    int64
+	NotLegit int64
+	Pending  int64
+}
+
+// StudentTermRow counts a student's active certificates uploaded within one academic term.
+type StudentTermRow struct {
+	Term     string
+	StartsOn time.Time
+	EndsOn   time.Time
+	Total    int64
+	Legit    int64
+	NotLegit int64
+}
+
+// SectionStanding compares a student's LEGIT count with the roster of their section. Average
+// includes roster students without any LEGIT certificate; Rank is nil when the student is not
+// on that section's roster.
+type SectionStanding struct {
+	Students   int64
+	Average    float64
+	LegitCount int64
+	Rank       *int64
+}
+
+// RosterStudentRow is a roster student with counts of their non-archived certificates.
+type RosterStudentRow struct {
+	models.Student
+	Submissions    int64
+	LegitCount     int64
+	LastUploadedAt *time.Time
+}
+
+// EnrollmentRow is one enrollment of a student with the name of the term it started in.
+type EnrollmentRow struct {
+	models.StudentEnrollment
+	Term *string
+}
+
+// StudentRepository reads the student roster and per-student certificate aggregates.
+type StudentRepository interface {
+	GetByRegisterNumber(ctx context.Context, regNo string) (*models.Student, error)
+	GetByRegisterNumbers(ctx context.Context, regNos []string) ([]models.Student, error)
+	List(ctx context.Context, filter StudentListFilter) ([]models.Student, error)
+	Create(ctx context.Context, student *models.Student) error
+	Update(ctx context.Context, student *models.Student) error
+	Delete(ctx context.Context, regNo string) error
+	MoveSection(ctx context.Context, regNo, section string) (*models.Student, error)
+	ListEnrollments(ctx context.Context, regNo string) ([]EnrollmentRow, error)
+	GetStatusTotals(ctx context.Context, regNo string) (StudentStatusTotals, error)
+	GetCategoryTotals(ctx context.Context, regNo string) ([]StudentCategoryRow, error)
+	GetTermTotals(ctx context.Context, regNo string) ([]StudentTermRow, error)
+	ListCertificates(ctx context.Context, regNo string, status models.FacultyStatus, limit int) ([]models.Certificate, error)
+	GetSectionStanding(ctx context.Context, regNo, section string) (SectionStanding, error)
+	ListSectionRoster(ctx context.Context, section, facultyEmail string) ([]RosterStudentRow, error)
+	// OnMutation registers a listener run after every committed roster change.
+	OnMutation(listener MutationListener)
+}
+
+type studentRepository struct {
+	db *gorm.DB
+	mutationListeners
+}
+
+// NewStudentRepository constructs a StudentRepository.
+func NewStudentRepository(db *gorm.DB) StudentRepository {
+	return &studentRepository{db: db}
+}
+
+// GetByRegisterNumber fetches a roster student.
+func (r *studentRepository) GetByRegisterNumber(ctx context.Context, regNo string) (*models.Student, error) {
+	var student models.Student
+	if err := r.db.WithContext(ctx).Where("register_number = ?", regNo).First(&student).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrStudentNotFound
+		}
+		return nil, fmt.Errorf("get student: %w", err)
+	}
+	return &student, nil
+}
+
+// GetByRegisterNumbers fetches the active roster students among regNos; unknown register
+// numbers are skipped.
+func (r *studentRepository) GetByRegisterNumbers(ctx context.Context, regNos []string) ([]models.Student, error) {
+	if len(regNos) == 0 {
+		return nil, nil
+	}
+	var students []models.Student
+	if err := r.db.WithContext(ctx).Where("register_number IN ?", regNos).Find(&students).Error; err != nil {
+		return nil, fmt.Errorf("query students: %w", err)
+	}
+	return students, nil
+}
+
+// List returns the active roster students matching filter, ordered by section and register
+// number.
+func (r *studentRepository) List(ctx context.Context, filter StudentListFilter) ([]models.Student, error) {
+	query := r.db.WithContext(ctx)
+	if filter.Section != "" {
+		query = query.Where("section = ?", filter.Section)
+	}
+	if filter.Batch != "" {
+		query = query.Where("batch = ?", filter.Batch)
+	}
+	if filter.FacultyEmail != "" {
+		query = query.Where("lower(faculty_email) = lower(?)", filter.FacultyEmail)
+	}
+	if filter.Present != nil {
+		query = query.Where("is_present = ?", *filter.Present)
+	}
+
+	var students []models.Student
+	if err := query.Order("section").Order("register_number").Find(&students).Error; err != nil {
+		return nil, fmt.Errorf("query students: %w", err)
+	}
+	return students, nil
+}
+
+// Create adds a student to the roster. A soft-deleted student with the same register number
+// is restored with the new details; an active one fails with ErrStudentExists.
+func (r *studentRepository) Create(ctx context.Context, student *models.Student) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return insertOrRestoreStudent(tx, student)
+	})
+	return r.committed(err)
+}
+
+// insertOrRestoreStudent inserts student within tx, restoring a soft-deleted row with the same
+// register number, and opens its enrollment. An active row fails with ErrStudentExists.
+func insertOrRestoreStudent(tx *gorm.DB, student *models.Student) error {
+	var existing models.Student
+	err := tx.Unscoped().Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("register_number = ?", student.RegisterNumber).
+		First(&existing).Error
+	switch {
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		if err := tx.Create(student).Error; err != nil {
+			return fmt.Errorf("insert student: %w", err)
+		}
+	case err != nil:
+		return fmt.Errorf("fetch student: %w", err)
+	case existing.DeletedAt.Valid:
+		student.ID = existing.ID
+		student.CreatedAt = existing.CreatedAt
+		student.DeletedAt = gorm.DeletedAt{}
+		if err := tx.Unscoped().Save(student).Error; err != nil {
+			return fmt.Errorf("restore student: %w", err)
+		}
+	default:
+		return ErrStudentExists
+	}
+	return syncEnrollment(tx, student, time.Now().UTC())
+}
+
+// Update stores every field of an active student loaded by GetByRegisterNumber. A changed
+// section or semester starts a new enrollment.
+func (r *studentRepository) Update(ctx context.Context, student *models.Student) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(student).Select("*").Omit("id", "created_at", "deleted_at").Updates(student)
+		if res.Error != nil {
+			return fmt.Errorf("update student: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return ErrStudentNotFound
+		}
+		return syncEnrollment(tx, student, time.Now().UTC())
+	})
+	return r.committed(err)
+}
+
+// Delete soft-deletes an active student and ends their enrollment.
+func (r *studentRepository) Delete(ctx context.Context, regNo string) error {
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("register_number = ?", regNo).Delete(&models.Student{})
+		if res.Error != nil {
+			return fmt.Errorf("delete student: %w", res.Error)
+		}
+		if res.RowsAffected == 0 {
+			return ErrStudentNotFound
+		}
+		return endEnrollment(tx, regNo, time.Now().UTC())
+	})
+	return r.committed(err)
+}
+
+// MoveSection reassigns an active student to section, starting a new enrollment, and returns
+// the updated student.
+func (r *studentRepository) MoveSection(ctx context.Context, regNo, section string) (*models.Student, error) {
+	var students []models.Student
+	err := r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now().UTC()
+		res := tx.Model(&students).
+			Clauses(clause.Returning{}).
+			Where("register_number = ?", regNo).
+			Updates(map[string]interface{}{"section": section, "updated_at": now})
+		if res.Error != nil {
+			return fmt.Errorf("move student section: %w", res.Error)
+		}
+		if len(students) == 0 {
+			return ErrStudentNotFound
+		}
+		return syncEnrollment(tx, &students[0], now)
+	})
+	if err := r.committed(err); err != nil {
+		return nil, err
+	}
+	return &students[0], nil
+}
+
+// ListEnrollments returns a student's enrollment history, latest first, with the name of the
+// term each enrollment started in.
+func (r *studentRepository) ListEnrollments(ctx context.Context, regNo string) ([]EnrollmentRow, error) {
+	var rows []EnrollmentRow
+	query := `
+		SELECT e.*, t.name AS term
+		FROM student_enrollments e
+		LEFT JOIN academic_terms t ON t.id = e.term_id
+		WHERE e.register_number = ?
+		ORDER BY e.started_at DESC, e.id DESC;
+	`
+	if err := r.db.WithContext(ctx).Raw(query, regNo).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("query student enrollments: %w", err)
+	}
+	return rows, nil
+}
+
+// GetStatusTotals counts the student's non-archived certificates per state, with the time of
+// the latest upload and decision.
+func (r *studentRepository) GetStatusTotals(ctx context.Context, regNo string) (StudentStatusTotals, error) {
+	var totals StudentStatusTotals
+	query := `
+		SELECT
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE ml_status = 'PENDING') AS ml_pending,
+			COUNT(*) FILTER (WHERE ml_status = 'VERIFIED') AS ml_verified,
+			COUNT(*) FILTER (WHERE ml_status = 'DUPLICATE') AS ml_duplicate,
+			COUNT(*) FILTER (WHERE ml_status = 'VERIFIED' AND faculty_status = 'PENDING') AS faculty_pending,
+			COUNT(*) FILTER (WHERE faculty_status = 'LEGIT') AS legit,
+			COUNT(*) FILTER (WHERE faculty_status = 'NOT_LEGIT') AS not_legit,
+			MAX(uploaded_at) AS last_uploaded_at,
+			MAX(reviewed_at) AS last_reviewed_at
+		FROM certificates
+		WHERE reg_no = ? AND archived = false;
+	`
+	if err := r.db.WithContext(ctx).Raw(query, regNo).Scan(&totals).Error; err != nil {
+		return StudentStatusTotals{}, fmt.Errorf("query student totals: %w", err)
+	}
+	return totals, nil
+}
+
+// GetCategoryTotals counts the student's non-archived certificates per category.
+func (r *studentRepository) GetCategoryTotals(ctx context.Context, regNo string) ([]StudentCategoryRow, error) {
+	var rows []StudentCategoryRow
+	query := `
+		SELECT
+			category,
+			COUNT(*) AS total,
+			COUNT(*) FILTER (WHERE faculty_status = 'LEGIT') AS legit,
+			COUNT(*) FILTER (WHERE faculty_status = 'NOT_LEGIT') AS not_legit,
+			COUNT(*) FILTER (WHERE faculty_status = 'PENDING') AS pending
+		FROM certificates
+		WHERE reg_no = ? AND archived = false
+		GROUP BY category
+		ORDER BY category;
+	`
+	if err := r.db.WithContext(ctx).Raw(query, regNo).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("query student categories: %w", err)
+	}
+	return rows, nil
+}
+
+// GetTermTotals counts the student's non-archived certificates per academic term, latest term
+// first. Every term is listed, including those without uploads. Term dates are taken in UTC.
+func (r *studentRepository) GetTermTotals(ctx context.Context, regNo string) ([]StudentTermRow, error) {
+	var rows []StudentTermRow
+	query := `
+		SELECT
+			t.name AS term,
+			t.starts_on,
+			t.ends_on,
+			COUNT(c.id) AS total,
+			COUNT(c.id) FILTER (WHERE c.faculty_status = 'LEGIT') AS legit,
+			COUNT(c.id) FILTER (WHERE c.faculty_status = 'NOT_LEGIT') AS not_legit
+		FROM academic_terms t
+		LEFT JOIN certificates c
+			ON c.reg_no = ?
+			AND c.archived = false
+			AND c.uploaded_at >= t.starts_on::timestamp AT TIME ZONE 'UTC'
+			AND c.uploaded_at < (t.ends_on + 1)::timestamp AT TIME ZONE 'UTC'
+		GROUP BY t.id, t.name, t.starts_on, t.ends_on
+		ORDER BY t.starts_on DESC;
+	`
+	if err := r.db.WithContext(ctx).Raw(query, regNo).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("query student terms: %w", err)
+	}
+	return rows, nil
+}
+
+// ListCertificates returns the student's non-archived certificates, most recently uploaded
+// first. An empty status lists every faculty status; a non-positive limit is unbounded.
+func (r *studentRepository) ListCertificates(ctx context.Context, regNo string, status models.FacultyStatus, limit int) ([]models.Certificate, error) {
+	query := r.db.WithContext(ctx).
+		Where("reg_no = ? AND archived = ?", regNo, false).
+		Order("uploaded_at DESC").Order("id DESC")
+	if status != "" {
+		query = query.Where("faculty_status = ?", status)
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
+	var certs []models.Certificate
+	if err := query.Find(&certs).Error; err != nil {
+		return nil, fmt.Errorf("query student certificates: %w", err)
+	}
+	return certs, nil
+}
+
+// GetSectionStanding ranks the student's LEGIT count among the roster students of section.
+func (r *studentRepository) GetSectionStanding(ctx context.Context, regNo, section string) (SectionStanding, error) {
+	var standing SectionStanding
+	query := `
+		WITH counts AS (
+			SELECT s.register_number, COUNT(c.id) AS legit_count
+			FROM students s
+			LEFT JOIN certificates c
+				ON c.reg_no = s.register_number AND c.archived = false AND c.faculty_status = 'LEGIT'
+			WHERE s.section = @section AND s.deleted_at IS NULL
+			GROUP BY s.register_number
+		),
+		ranked AS (
+			SELECT register_number, legit_count, RANK() OVER (ORDER BY legit_count DESC) AS rank
+			FROM counts
+		)
+		SELECT
+			(SELECT COUNT(*) FROM counts) AS students,
+			COALESCE((SELECT AVG(legit_count) FROM counts), 0)::float8 AS average,
+			COALESCE((SELECT legit_count FROM ranked WHERE register_number = @reg_no), 0) AS legit_count,
+			(SELECT rank FROM ranked WHERE register_number = @reg_no) AS rank;
+	`
+	args := map[string]interface{}{"reg_no": regNo, "section": section}
+	if err := r.db.WithContext(ctx).Raw(query, args).Scan(&standing).Error; err != nil {
+		return SectionStanding{}, fmt.Errorf("query section standing: %w", err)
+	}
+	return standing, nil
+}
+
+// ListSectionRoster returns the roster of section ordered by register number, each student
+// with their certificate counts. A non-empty facultyEmail keeps only the students assigned to
+// that faculty.
+func (r *studentRepository) ListSectionRoster(ctx context.Context, section, facultyEmail string) ([]RosterStudentRow, error) {
+	var rows []RosterStudentRow
+	query := `
+		SELECT
+			s.*,
+			COUNT(c.id) AS submissions,
+			COUNT(c.id) FILTER (WHERE c.faculty_status = 'LEGIT') AS legit_count,
+			MAX(c.uploaded_at) AS last_uploaded_at
+		FROM students s
+		LEFT JOIN certificates c ON c.reg_no = s.register_number AND c.archived = false
+		WHERE s.section = @section AND s.deleted_at IS NULL
+			AND (@email = '' OR lower(s.faculty_email) = lower(@email))
+		GROUP BY s.id
+		ORDER BY s.register_number;
+	`
+	args := map[string]interface{}{"section": section, "email": facultyEmail}
+	if err := r.db.WithContext(ctx).Raw(query, args).Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("query section roster: %w", err)
+	}
+	return rows, nil
+}
@@ -0,0 +1,52 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"department-eduvault-backend/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// VerificationSignalRepository stores the results of automated certificate checks.
+type VerificationSignalRepository interface {
+	Upsert(ctx context.Context, signal *models.CertificateVerificationSignal) error
+	ListForCertificate(ctx context.Context, certificateID string) ([]models.CertificateVerificationSignal, error)
+}
+
+type verificationSignalRepository struct {
+	db *gorm.DB
+}
+
+// NewVerificationSignalRepository constructs a VerificationSignalRepository.
+func NewVerificationSignalRepository(db *gorm.DB) VerificationSignalRepository {
+	return &verificationSignalRepository{db: db}
+}
+
+// Upsert stores the signal, replacing an earlier result of the same kind.
+func (r *verificationSignalRepository) Upsert(ctx context.Context, signal *models.CertificateVerificationSignal) error {
+	err := r.db.WithContext(ctx).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "certificate_id"}, {Name: "kind"}},
+			DoUpdates: clause.AssignmentColumns([]string{"confidence", "details", "created_by", "updated_at"}),
+		}).
+		Create(signal).Error
+	if err != nil {
+		return fmt.Errorf("upsert verification signal: %w", err)
+	}
+	return nil
+}
+
+// ListForCertificate returns every signal recorded for a certificate.
+func (r *verificationSignalRepository) ListForCertificate(ctx context.Context, certificateID string) ([]models.CertificateVerificationSignal, error) {
+	var signals []models.CertificateVerificationSignal
+	if err := r.db.WithContext(ctx).
+		Where("certificate_id = ?", certificateID).
+		Order("kind").
+		Find(&signals).Error; err != nil {
+		return nil, fmt.Errorf("query verification signals: %w", err)
+	}
+	return signals, nil
+}
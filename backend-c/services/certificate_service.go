@@ -2,74 +2,197 @@ package services
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"net/http"
 	"regexp"
+	"strings"
 	"time"
 
 	"department-eduvault-backend/models"
 	"department-eduvault-backend/repositories"
+
+	"github.com/google/uuid"
 )
 
 var (
-	ErrInvalidDriveLink            = errors.New("drive link must be a valid Google Drive URL")
-	ErrUploadLimitExceeded         = errors.New("cannot upload more than 10 certificates in one request")
-	ErrInvalidMLTransition         = errors.New("ml status transition is not allowed")
-	ErrInvalidFacultyState         = errors.New("faculty decision is only allowed after ML verification and while pending")
-	ErrCertificateArchived         = errors.New("archived certificates cannot be modified")
-	driveLinkPattern               = regexp.MustCompile(`^https://drive\.google\.com/`)
-	defaultMLScore         float64 = 95.0
+	ErrInvalidDriveLink              = errors.New("drive link must be a valid Google Drive URL")
+	ErrUploadLimitExceeded           = errors.New("cannot upload more than 10 certificates in one request")
+	ErrInvalidMLTransition           = errors.New("ml status transition is not allowed")
+	ErrInvalidFacultyState           = errors.New("faculty decision is only allowed after ML verification and while pending")
+	ErrCertificateArchived           = errors.New("archived certificates cannot be modified")
+	ErrInvalidCursor                 = errors.New("cursor is malformed")
+	ErrInvalidReviewFilter           = errors.New("pending review filter is invalid")
+	ErrIdempotencyKeyReused          = errors.New("idempotency key was already used with a different request body")
+	ErrIdempotencyInProgress         = errors.New("a request with this idempotency key is still being processed")
+	ErrHODReviewRequired             = errors.New("certificate was flagged by the ML policy and requires an HOD decision")
+	driveLinkPattern                 = regexp.MustCompile(`^https://drive\.google\.com/`)
+	defaultMLScore           float64 = 95.0
 )
 
+const defaultReviewLease = 15 * time.Minute
+
 // CertificateInput represents an upload payload.
 type CertificateInput struct {
 	DriveLink      string
 	RegisterNumber string
 	Section        string
 	StudentName    string
+	Title          string
+	Issuer         string
+	Category       string
+	Department     string
 	UploadedBy     string
 	UploadedAt     time.Time
 }
 
+// UploadResult lists the certificates created by an upload and the roster validation result
+// of every item. Replayed is set when the result was served from a stored Idempotency-Key
+// record instead of a fresh insert.
+type UploadResult struct {
+	CertificateIDs []string           `json:"certificate_ids"`
+	Items          []UploadItemResult `json:"items"`
+	Replayed       bool               `json:"-"`
+}
+
+// UploadIdempotency scopes an Idempotency-Key to the user who sent it.
+type UploadIdempotency struct {
+	Key   string
+	Actor string
+}
+
+// ReviewCaller identifies who is claiming or deciding a certificate.
+type ReviewCaller struct {
+	Email string
+	Role  string
+}
+
+func (c ReviewCaller) isHOD() bool {
+	return strings.EqualFold(c.Role, "hod")
+}
+
+// PendingReviewQuery carries the optional filters, sort order and cursor for the review queue.
+type PendingReviewQuery struct {
+	Reviewer     string
+	Section      string
+	UploadedBy   string
+	UploadedFrom *time.Time
+	UploadedTo   *time.Time
+	MinMLScore   *float64
+	MaxMLScore   *float64
+	Sort         string
+	Cursor       string
+	Limit        int
+}
+
+// PendingReviewPage is one page of the review queue; NextCursor is empty on the last page.
+type PendingReviewPage struct {
+	Certificates []models.Certificate
+	NextCursor   string
+}
+
 // CertificateService describes business operations for certificates.
 type CertificateService interface {
-	UploadCertificates(ctx context.Context, inputs []CertificateInput) error
+	UploadCertificates(ctx context.Context, inputs []CertificateInput) (UploadResult, error)
+	UploadCertificatesIdempotent(ctx context.Context, idem UploadIdempotency, inputs []CertificateInput) (UploadResult, error)
 	TriggerMockMLVerification(ctx context.Context, certificateID string) error
-	GetPendingFacultyReview(ctx context.Context, limit int) ([]models.Certificate, error)
-	SubmitFacultyDecision(ctx context.Context, certificateID string, status models.FacultyStatus, isLegit bool) error
+	GetPendingFacultyReview(ctx context.Context, query PendingReviewQuery) (PendingReviewPage, error)
+	SubmitFacultyDecision(ctx context.Context, certificateID string, reviewer ReviewCaller, status models.FacultyStatus, isLegit bool) error
+	ClaimForReview(ctx context.Context, certificateID string, reviewer ReviewCaller) (*models.CertificateClaim, error)
+	ReleaseReviewClaim(ctx context.Context, certificateID, reviewer string) error
 }
 
 type certificateService struct {
-	repo repositories.CertificateRepository
+	repo          repositories.CertificateRepository
+	idempotency   repositories.IdempotencyRepository
+	policies      repositories.MLPolicyRepository
+	roster        repositories.StudentRepository
+	sectionPolicy SectionMismatchPolicy
+	leaseTTL      time.Duration
+}
+
+// NewCertificateService constructs a CertificateService. policies supplies the score-based
+// auto-decision policy applied after ML verification; uploads are checked against roster,
+// with sectionPolicy (default SectionMismatchCorrect) deciding section mismatches; leaseTTL
+// bounds how long a review claim stays valid without being renewed.
+func NewCertificateService(repo repositories.CertificateRepository, idempotency repositories.IdempotencyRepository, policies repositories.MLPolicyRepository, roster repositories.StudentRepository, sectionPolicy SectionMismatchPolicy, leaseTTL time.Duration) CertificateService {
+	if leaseTTL <= 0 {
+		leaseTTL = defaultReviewLease
+	}
+	if sectionPolicy != SectionMismatchReject {
+		sectionPolicy = SectionMismatchCorrect
+	}
+	return &certificateService{
+		repo:          repo,
+		idempotency:   idempotency,
+		policies:      policies,
+		roster:        roster,
+		sectionPolicy: sectionPolicy,
+		leaseTTL:      leaseTTL,
+	}
 }
 
-// NewCertificateService constructs a CertificateService.
-func NewCertificateService(repo repositories.CertificateRepository) CertificateService {
-	return &certificateService{repo: repo}
+// UploadCertificates validates input against the student roster and creates the accepted
+// certificates; kicks off mock ML verification. Items with an unknown register number, or a
+// mismatched section under SectionMismatchReject, are rejected without failing the others;
+// every item's outcome is in result.Items.
+func (s *certificateService) UploadCertificates(ctx context.Context, inputs []CertificateInput) (UploadResult, error) {
+	return s.uploadCertificates(ctx, inputs, nil)
 }
 
-// UploadCertificates validates input and delegates creation; kicks off mock ML verification.
-func (s *certificateService) UploadCertificates(ctx context.Context, inputs []CertificateInput) error {
+// uploadCertificates implements UploadCertificates. With a reservation, the certificates are
+// inserted only while it is held, and it is marked committed in the same transaction.
+func (s *certificateService) uploadCertificates(ctx context.Context, inputs []CertificateInput, reservation *models.IdempotencyKey) (UploadResult, error) {
+	result := UploadResult{CertificateIDs: []string{}, Items: []UploadItemResult{}}
 	if len(inputs) == 0 {
-		return nil
+		return result, nil
 	}
 	if len(inputs) > 10 {
-		return ErrUploadLimitExceeded
+		return result, ErrUploadLimitExceeded
 	}
 
-	certs := make([]models.Certificate, 0, len(inputs))
+	regNos := make([]string, 0, len(inputs))
 	for _, in := range inputs {
 		if !driveLinkPattern.MatchString(in.DriveLink) {
-			return ErrInvalidDriveLink
+			return result, ErrInvalidDriveLink
+		}
+		regNos = append(regNos, strings.ToUpper(strings.TrimSpace(in.RegisterNumber)))
+	}
+	students, err := s.roster.GetByRegisterNumbers(ctx, regNos)
+	if err != nil {
+		return result, err
+	}
+	byRegNo := make(map[string]*models.Student, len(students))
+	for i := range students {
+		byRegNo[students[i].RegisterNumber] = &students[i]
+	}
+
+	certs := make([]models.Certificate, 0, len(inputs))
+	accepted := make([]int, 0, len(inputs))
+	for i, in := range inputs {
+		item, student := checkAgainstRoster(i, in, byRegNo[regNos[i]], s.sectionPolicy)
+		result.Items = append(result.Items, item)
+		if student == nil {
+			continue
 		}
 		uploadedAt := in.UploadedAt
 		if uploadedAt.IsZero() {
 			uploadedAt = time.Now().UTC()
 		}
+		accepted = append(accepted, i)
 		certs = append(certs, models.Certificate{
 			DriveLink:      in.DriveLink,
-			RegisterNumber: in.RegisterNumber,
-			Section:        in.Section,
-			StudentName:    in.StudentName,
+			RegisterNumber: student.RegisterNumber,
+			Section:        student.Section,
+			StudentName:    student.Name,
+			Title:          strings.TrimSpace(in.Title),
+			Issuer:         strings.TrimSpace(in.Issuer),
+			Category:       normalizeCategory(in.Category),
+			Department:     normalizeDepartment(in.Department),
 			UploadedBy:     in.UploadedBy,
 			UploadedAt:     uploadedAt,
 			MLStatus:       models.MLStatusPending,
@@ -78,18 +201,80 @@ func (s *certificateService) UploadCertificates(ctx context.Context, inputs []Ce
 		})
 	}
 
-	if err := s.repo.CreateCertificates(ctx, certs); err != nil {
-		return err
+	if len(certs) == 0 {
+		return result, nil
+	}
+	if reservation != nil {
+		err = s.repo.CreateCertificatesIdempotent(ctx, certs, *reservation)
+	} else {
+		err = s.repo.CreateCertificates(ctx, certs)
+	}
+	if err != nil {
+		return result, err
 	}
 
 	// Trigger mock async ML verification.
-	for _, cert := range certs {
+	for i, cert := range certs {
 		certID := cert.ID
+		result.CertificateIDs = append(result.CertificateIDs, certID)
+		result.Items[accepted[i]].CertificateID = certID
 		go func(id string) {
 			_ = s.TriggerMockMLVerification(context.Background(), id)
 		}(certID)
 	}
-	return nil
+	return result, nil
+}
+
+// UploadCertificatesIdempotent performs an upload at most once per (actor, key). Retrying
+// with the same payload replays the stored result without inserting again; reusing the
+// key for a different payload is rejected.
+func (s *certificateService) UploadCertificatesIdempotent(ctx context.Context, idem UploadIdempotency, inputs []CertificateInput) (UploadResult, error) {
+	fingerprint, err := fingerprintInputs(inputs)
+	if err != nil {
+		return UploadResult{}, err
+	}
+
+	rec, reserved, err := s.idempotency.Reserve(ctx, idem.Actor, idem.Key, fingerprint)
+	if err != nil {
+		return UploadResult{}, err
+	}
+	if !reserved {
+		if rec.RequestHash != fingerprint {
+			return UploadResult{}, ErrIdempotencyKeyReused
+		}
+		if rec.CompletedAt == nil || rec.ResponseBody == nil {
+			return UploadResult{}, ErrIdempotencyInProgress
+		}
+		var replay UploadResult
+		if err := json.Unmarshal([]byte(*rec.ResponseBody), &replay); err != nil {
+			return UploadResult{}, err
+		}
+		replay.Replayed = true
+		return replay, nil
+	}
+
+	result, err := s.uploadCertificates(ctx, inputs, rec)
+	if errors.Is(err, repositories.ErrIdempotencyKeyNotFound) {
+		// The reservation went stale and another request with this key took it over.
+		return UploadResult{}, ErrIdempotencyInProgress
+	}
+	if err != nil {
+		// Free the key so the client can retry once the cause is fixed.
+		_ = s.idempotency.Release(context.Background(), idem.Actor, idem.Key)
+		return UploadResult{}, err
+	}
+	if len(result.CertificateIDs) == 0 {
+		// Nothing was stored, so a retry after fixing the roster must not be replayed.
+		_ = s.idempotency.Release(context.Background(), idem.Actor, idem.Key)
+		return result, nil
+	}
+
+	body, _ := json.Marshal(result)
+	// The reservation was marked committed with the certificates. If storing the response
+	// fails it is never taken over or swept as stale, so a retry is refused as in-progress
+	// rather than inserted twice.
+	_ = s.idempotency.Complete(context.Background(), idem.Actor, idem.Key, http.StatusAccepted, string(body))
+	return result, nil
 }
 
 // TriggerMockMLVerification simulates an asynchronous ML process by marking verified.
@@ -105,16 +290,68 @@ func (s *certificateService) TriggerMockMLVerification(ctx context.Context, cert
 		return ErrInvalidMLTransition
 	}
 
-	return s.repo.UpdateMLStatus(ctx, certificateID, models.MLStatusVerified, &defaultMLScore)
+	score := defaultMLScore
+	routing, err := s.mlRouting(ctx, score)
+	if err != nil {
+		return err
+	}
+	return s.repo.UpdateMLStatus(ctx, certificateID, models.MLStatusVerified, &score, routing)
+}
+
+// mlRouting decides where a freshly verified certificate goes by score: auto-approval at or
+// above the upper threshold, mandatory HOD review below the lower one, otherwise the faculty
+// queue (nil). Without an enabled policy every certificate goes to the faculty queue.
+func (s *certificateService) mlRouting(ctx context.Context, score float64) (*repositories.MLRouting, error) {
+	policy, err := s.policies.GetActive(ctx)
+	if err != nil {
+		if errors.Is(err, repositories.ErrMLPolicyNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if !policy.Enabled {
+		return nil, nil
+	}
+
+	switch {
+	case score >= policy.AutoApproveThreshold:
+		return &repositories.MLRouting{PolicyVersion: policy.Version, AutoApprove: true}, nil
+	case score < policy.HodReviewThreshold:
+		reason := fmt.Sprintf("ml score %.2f below hod review threshold %.2f (policy v%d)", score, policy.HodReviewThreshold, policy.Version)
+		return &repositories.MLRouting{PolicyVersion: policy.Version, HODReviewReason: reason}, nil
+	default:
+		return nil, nil
+	}
 }
 
-// GetPendingFacultyReview fetches ML-verified certificates pending faculty action.
-func (s *certificateService) GetPendingFacultyReview(ctx context.Context, limit int) ([]models.Certificate, error) {
-	return s.repo.GetCertificatesPendingFacultyReview(ctx, limit)
+// GetPendingFacultyReview fetches one page of ML-verified certificates pending faculty action.
+func (s *certificateService) GetPendingFacultyReview(ctx context.Context, query PendingReviewQuery) (PendingReviewPage, error) {
+	filter, err := buildPendingReviewFilter(query)
+	if err != nil {
+		return PendingReviewPage{}, err
+	}
+
+	// Fetch one extra row to learn whether another page exists.
+	limit := filter.Limit
+	filter.Limit = limit + 1
+	certs, err := s.repo.GetCertificatesPendingFacultyReview(ctx, filter)
+	if err != nil {
+		return PendingReviewPage{}, err
+	}
+
+	page := PendingReviewPage{Certificates: certs}
+	if len(certs) > limit {
+		page.Certificates = certs[:limit]
+		last := page.Certificates[limit-1]
+		page.NextCursor = encodePendingReviewCursor(filter.Sort, repositories.PendingReviewCursor{UploadedAt: last.UploadedAt, ID: last.ID})
+	}
+	return page, nil
 }
 
-// SubmitFacultyDecision records a faculty decision with state validation.
-func (s *certificateService) SubmitFacultyDecision(ctx context.Context, certificateID string, status models.FacultyStatus, isLegit bool) error {
+// SubmitFacultyDecision records a faculty decision with state validation. A live claim
+// held by another reviewer blocks the decision, and certificates flagged by the ML policy
+// may only be decided by the HOD.
+func (s *certificateService) SubmitFacultyDecision(ctx context.Context, certificateID string, reviewer ReviewCaller, status models.FacultyStatus, isLegit bool) error {
 	if status != models.FacultyStatusLegit && status != models.FacultyStatusNotLegit {
 		return ErrInvalidFacultyState
 	}
@@ -129,8 +366,35 @@ func (s *certificateService) SubmitFacultyDecision(ctx context.Context, certific
 	if cert.MLStatus != models.MLStatusVerified || cert.FacultyStatus != models.FacultyStatusPending {
 		return ErrInvalidFacultyState
 	}
+	if cert.RequiresHOD && !reviewer.isHOD() {
+		return ErrHODReviewRequired
+	}
+
+	return s.repo.UpdateFacultyDecision(ctx, certificateID, reviewer.Email, status, isLegit)
+}
+
+// ClaimForReview grants the reviewer a time-limited lease on a certificate awaiting review.
+// Claiming again while the lease is live renews it.
+func (s *certificateService) ClaimForReview(ctx context.Context, certificateID string, reviewer ReviewCaller) (*models.CertificateClaim, error) {
+	cert, err := s.repo.GetByID(ctx, certificateID)
+	if err != nil {
+		return nil, err
+	}
+	if cert.Archived {
+		return nil, ErrCertificateArchived
+	}
+	if cert.MLStatus != models.MLStatusVerified || cert.FacultyStatus != models.FacultyStatusPending {
+		return nil, ErrInvalidFacultyState
+	}
+	if cert.RequiresHOD && !reviewer.isHOD() {
+		return nil, ErrHODReviewRequired
+	}
+	return s.repo.ClaimCertificate(ctx, certificateID, reviewer.Email, s.leaseTTL)
+}
 
-	return s.repo.UpdateFacultyDecision(ctx, certificateID, status, isLegit)
+// ReleaseReviewClaim gives up the reviewer's lease before it expires.
+func (s *certificateService) ReleaseReviewClaim(ctx context.Context, certificateID, reviewer string) error {
+	return s.repo.ReleaseClaim(ctx, certificateID, reviewer)
 }
 
 // Helpers (kept unexported) ---------------------------------------------------
@@ -154,3 +418,122 @@ func (s *certificateService) validateInputs(inputs []CertificateInput) error {
 	}
 	return nil
 }
+
+// normalizeCategory upper-cases categories so filters and SLA policies match consistently.
+func normalizeCategory(category string) string {
+	category = strings.ToUpper(strings.TrimSpace(category))
+	if category == "" {
+		return models.DefaultCertificateCategory
+	}
+	return category
+}
+
+// normalizeDepartment upper-cases department codes; an empty department stays empty.
+func normalizeDepartment(department string) string {
+	return strings.ToUpper(strings.TrimSpace(department))
+}
+
+// fingerprintInputs hashes the upload payload, normalized the way UploadCertificates stores
+// it, so retries that differ only in whitespace or case compare equal.
+func fingerprintInputs(inputs []CertificateInput) (string, error) {
+	normalized := make([]CertificateInput, len(inputs))
+	for i, in := range inputs {
+		normalized[i] = CertificateInput{
+			DriveLink:      in.DriveLink,
+			RegisterNumber: strings.ToUpper(strings.TrimSpace(in.RegisterNumber)),
+			Section:        strings.ToUpper(strings.TrimSpace(in.Section)),
+			StudentName:    strings.TrimSpace(in.StudentName),
+			Title:          strings.TrimSpace(in.Title),
+			Issuer:         strings.TrimSpace(in.Issuer),
+			Category:       normalizeCategory(in.Category),
+			Department:     normalizeDepartment(in.Department),
+			UploadedBy:     in.UploadedBy,
+			UploadedAt:     in.UploadedAt.UTC(),
+		}
+	}
+	raw, err := json.Marshal(normalized)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+func buildPendingReviewFilter(query PendingReviewQuery) (repositories.PendingReviewFilter, error) {
+	filter := repositories.PendingReviewFilter{
+		Reviewer:     query.Reviewer,
+		Section:      strings.TrimSpace(query.Section),
+		UploadedBy:   strings.TrimSpace(query.UploadedBy),
+		UploadedFrom: query.UploadedFrom,
+		UploadedTo:   query.UploadedTo,
+		MinMLScore:   query.MinMLScore,
+		MaxMLScore:   query.MaxMLScore,
+		Limit:        query.Limit,
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 50
+	}
+
+	switch repositories.PendingReviewSort(strings.ToLower(query.Sort)) {
+	case "", repositories.PendingReviewOldestFirst:
+		filter.Sort = repositories.PendingReviewOldestFirst
+	case repositories.PendingReviewNewestFirst:
+		filter.Sort = repositories.PendingReviewNewestFirst
+	default:
+		return filter, ErrInvalidReviewFilter
+	}
+
+	if filter.UploadedFrom != nil && filter.UploadedTo != nil && !filter.UploadedFrom.Before(*filter.UploadedTo) {
+		return filter, ErrInvalidReviewFilter
+	}
+	for _, score := range []*float64{filter.MinMLScore, filter.MaxMLScore} {
+		if score != nil && (*score < 0 || *score > 100) {
+			return filter, ErrInvalidReviewFilter
+		}
+	}
+	if filter.MinMLScore != nil && filter.MaxMLScore != nil && *filter.MinMLScore > *filter.MaxMLScore {
+		return filter, ErrInvalidReviewFilter
+	}
+
+	if query.Cursor != "" {
+		sort, cursor, err := decodePendingReviewCursor(query.Cursor)
+		if err != nil {
+			return filter, err
+		}
+		// A cursor marks a position in one ordering; continuing it in the other would skip rows.
+		if sort != filter.Sort {
+			return filter, ErrInvalidCursor
+		}
+		filter.After = &cursor
+	}
+	return filter, nil
+}
+
+// Cursors are opaque to clients: base64("<sort>|<uploaded_at RFC3339Nano>|<id>").
+func encodePendingReviewCursor(sort repositories.PendingReviewSort, cursor repositories.PendingReviewCursor) string {
+	raw := string(sort) + "|" + cursor.UploadedAt.UTC().Format(time.RFC3339Nano) + "|" + cursor.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodePendingReviewCursor(val string) (repositories.PendingReviewSort, repositories.PendingReviewCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(val)
+	if err != nil {
+		return "", repositories.PendingReviewCursor{}, ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), "|", 3)
+	if len(parts) != 3 {
+		return "", repositories.PendingReviewCursor{}, ErrInvalidCursor
+	}
+	sort := repositories.PendingReviewSort(parts[0])
+	if sort != repositories.PendingReviewOldestFirst && sort != repositories.PendingReviewNewestFirst {
+		return "", repositories.PendingReviewCursor{}, ErrInvalidCursor
+	}
+	if _, err := uuid.Parse(parts[2]); err != nil {
+		return "", repositories.PendingReviewCursor{}, ErrInvalidCursor
+	}
+	uploadedAt, err := time.Parse(time.RFC3339Nano, parts[1])
+	if err != nil {
+		return "", repositories.PendingReviewCursor{}, ErrInvalidCursor
+	}
+	return sort, repositories.PendingReviewCursor{UploadedAt: uploadedAt, ID: parts[2]}, nil
+}
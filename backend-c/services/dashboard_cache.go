@@ -0,0 +1,116 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+// CacheValidators identify a version of a cached dashboard response. ETag is a strong,
+// quoted entity tag derived from the response body; LastModified is when that body was
+// first produced and only moves when the body changes.
+type CacheValidators struct {
+	ETag         string
+	LastModified time.Time
+}
+
+// maxDashboardCacheEntries bounds the cache, since every filter combination has its own key.
+const maxDashboardCacheEntries = 256
+
+type dashboardCacheEntry struct {
+	value      interface{}
+	validators CacheValidators
+	expiresAt  time.Time
+}
+
+// dashboardCache holds dashboard responses for a TTL. invalidate expires every entry at once;
+// a load that started before an invalidation is returned to its caller but not stored, so a
+// mutation committed mid-load cannot be masked for a whole TTL.
+type dashboardCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	generation uint64
+	entries    map[dashboardCacheKey]*dashboardCacheEntry
+}
+
+// dashboardCacheKey identifies a cached response by tile and resolved filter. Each field is
+// kept separately and upload bounds as Unix nanoseconds with a presence flag, so distinct
+// filters never share a key.
+type dashboardCacheKey struct {
+	tile       string
+	category   string
+	uploadedBy string
+	department string
+	hasFrom    bool
+	from       int64
+	hasTo      bool
+	to         int64
+}
+
+func newDashboardCache(ttl time.Duration) *dashboardCache {
+	return &dashboardCache{ttl: ttl, entries: make(map[dashboardCacheKey]*dashboardCacheEntry)}
+}
+
+// get returns a live entry for key, or the current generation to pass to put after loading.
+func (c *dashboardCache) get(key dashboardCacheKey, now time.Time) (*dashboardCacheEntry, uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if e, ok := c.entries[key]; ok && now.Before(e.expiresAt) {
+		return e, c.generation
+	}
+	return nil, c.generation
+}
+
+// put stores value under key and returns its validators. The previous Last-Modified is kept
+// when the body has not changed, so polling clients keep getting 304s across refreshes.
+func (c *dashboardCache) put(key dashboardCacheKey, generation uint64, value interface{}, now time.Time) (CacheValidators, error) {
+	body, err := json.Marshal(value)
+	if err != nil {
+		return CacheValidators{}, err
+	}
+	sum := sha256.Sum256(body)
+	validators := CacheValidators{
+		ETag:         `"` + hex.EncodeToString(sum[:16]) + `"`,
+		LastModified: now.UTC().Truncate(time.Second),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if prev, ok := c.entries[key]; ok && prev.validators.ETag == validators.ETag {
+		validators.LastModified = prev.validators.LastModified
+	}
+	if c.ttl > 0 && generation == c.generation && c.makeRoom(key, now) {
+		c.entries[key] = &dashboardCacheEntry{
+			value:      value,
+			validators: validators,
+			expiresAt:  now.Add(c.ttl),
+		}
+	}
+	return validators, nil
+}
+
+// makeRoom reports whether key can be stored, dropping expired entries when the cache is full.
+func (c *dashboardCache) makeRoom(key dashboardCacheKey, now time.Time) bool {
+	if _, ok := c.entries[key]; ok || len(c.entries) < maxDashboardCacheEntries {
+		return true
+	}
+	for k, e := range c.entries {
+		if !now.Before(e.expiresAt) {
+			delete(c.entries, k)
+		}
+	}
+	return len(c.entries) < maxDashboardCacheEntries
+}
+
+// invalidate expires every entry. Entries are kept (expired) so their validators can carry
+// Last-Modified over to an identical reload.
+func (c *dashboardCache) invalidate() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.generation++
+	for _, e := range c.entries {
+		e.expiresAt = time.Time{}
+	}
+}
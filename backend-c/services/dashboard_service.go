@@ -2,10 +2,42 @@ package services
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
 
 	"department-eduvault-backend/repositories"
 )
 
+var (
+	ErrInvalidDashboardRange = errors.New("uploaded_from must be before uploaded_to and within the selected term")
+	ErrInvalidTrendBucket    = errors.New("bucket must be one of day, week or month")
+	ErrInvalidTrendRange     = errors.New("from must be before to")
+	ErrTrendRangeTooLarge    = errors.New("date range has too many buckets; use a larger bucket or a shorter range")
+)
+
+// Trend buckets accepted by GetTrends, named after their date_trunc units.
+const (
+	TrendBucketDay   = "day"
+	TrendBucketWeek  = "week"
+	TrendBucketMonth = "month"
+)
+
+// maxTrendBuckets bounds the number of points per series (a year of daily buckets).
+const maxTrendBuckets = 366
+
+// DashboardFilter slices every dashboard tile by the certificates it counts. Term names an
+// academic term bounding upload dates; UploadedFrom/UploadedTo (To exclusive) narrow it
+// further. Zero-valued fields are not applied.
+type DashboardFilter struct {
+	Term         string
+	UploadedFrom *time.Time
+	UploadedTo   *time.Time
+	Category     string
+	UploadedBy   string
+	Department   string
+}
+
 type DashboardOverviewDTO struct {
 	TotalStudents     int64 `json:"total_students"`
 	TotalCertificates int64 `json:"total_certificates"`
@@ -14,46 +46,224 @@ type DashboardOverviewDTO struct {
 	PendingCount      int64 `json:"pending_count"`
 }
 
+// SectionStatsDTO is one row of GET /dashboard/sections. Roster figures come from the
+// students table; ParticipationRate is the share of present students with at least one LEGIT
+// certificate, and NonSubmitters counts present students without any certificate.
 type SectionStatsDTO struct {
-	Section           string  `json:"section"`
-	TotalCertificates int64   `json:"total_certificates"`
-	VerifiedCount     int64   `json:"verified_count"`
-	RejectedCount     int64   `json:"rejected_count"`
-	PendingCount      int64   `json:"pending_count"`
-	VerificationRate  float64 `json:"verification_rate"`
+	Section               string  `json:"section"`
+	TotalCertificates     int64   `json:"total_certificates"`
+	VerifiedCount         int64   `json:"verified_count"`
+	RejectedCount         int64   `json:"rejected_count"`
+	PendingCount          int64   `json:"pending_count"`
+	VerificationRate      float64 `json:"verification_rate"`
+	TotalStudents         int64   `json:"total_students"`
+	PresentStudents       int64   `json:"present_students"`
+	AbsentStudents        int64   `json:"absent_students"`
+	ParticipatingStudents int64   `json:"participating_students"`
+	ParticipationRate     float64 `json:"participation_rate"`
+	NonSubmitters         int64   `json:"non_submitters"`
+}
+
+// TrendQuery selects the range and granularity of GetTrends. Zero From/To default to a
+// window ending now: 30 days, 12 weeks or 12 months depending on the bucket. Filter selects
+// the certificates whose events are counted.
+type TrendQuery struct {
+	Bucket  string
+	From    *time.Time
+	To      *time.Time
+	Section string
+	Filter  DashboardFilter
+}
+
+// TrendPointDTO counts pipeline events within one bucket.
+type TrendPointDTO struct {
+	PeriodStart string `json:"period_start"`
+	Uploaded    int64  `json:"uploaded"`
+	MLVerified  int64  `json:"ml_verified"`
+	Legit       int64  `json:"legit"`
+	NotLegit    int64  `json:"not_legit"`
+}
+
+// SectionTrendDTO is one section's series, with a point for every bucket in the range.
+type SectionTrendDTO struct {
+	Section string          `json:"section"`
+	Points  []TrendPointDTO `json:"points"`
 }
 
+// TrendsDTO is the response of GET /dashboard/trends. Totals sums every section.
+type TrendsDTO struct {
+	Bucket   string            `json:"bucket"`
+	From     time.Time         `json:"from"`
+	To       time.Time         `json:"to"`
+	Periods  []string          `json:"periods"`
+	Totals   []TrendPointDTO   `json:"totals"`
+	Sections []SectionTrendDTO `json:"sections"`
+}
+
+// Funnel stages in pipeline order.
+const (
+	FunnelStageUploaded       = "uploaded"
+	FunnelStageMLPending      = "ml_pending"
+	FunnelStageMLVerified     = "ml_verified"
+	FunnelStageMLDuplicate    = "ml_duplicate"
+	FunnelStageFacultyPending = "faculty_pending"
+	FunnelStageLegit          = "legit"
+	FunnelStageNotLegit       = "not_legit"
+	FunnelStageArchived       = "archived"
+	// funnelDecided is the denominator of the decision stages: LEGIT plus NOT_LEGIT.
+	funnelDecided = "decided"
+)
+
+// FunnelStageDTO is one stage of the pipeline funnel. Rate is Count divided by the count of
+// the Of stage, or 0 when that is empty.
+type FunnelStageDTO struct {
+	Stage string  `json:"stage"`
+	Count int64   `json:"count"`
+	Of    string  `json:"of,omitempty"`
+	Rate  float64 `json:"rate"`
+}
+
+// SectionFunnelDTO is one section's funnel.
+type SectionFunnelDTO struct {
+	Section string           `json:"section"`
+	Stages  []FunnelStageDTO `json:"stages"`
+}
+
+// FunnelDTO is the response of GET /dashboard/funnel.
+//
+// ML stages and archived partition uploaded: every certificate is either archived or in exactly
+// one ML state. faculty_pending is the share of ML-verified certificates awaiting review, and
+// legit/not_legit split the decisions made.
+type FunnelDTO struct {
+	Department []FunnelStageDTO   `json:"department"`
+	Sections   []SectionFunnelDTO `json:"sections"`
+}
+
+// Cache keys of the cached dashboard responses.
+const (
+	dashboardCacheOverview = "overview"
+	dashboardCacheSections = "sections"
+)
+
+// DashboardService serves the dashboard aggregates. Overview and section statistics are
+// cached per filter for a TTL and dropped by Invalidate, which is wired to certificate
+// mutations. Unknown terms fail with repositories.ErrAcademicTermNotFound.
 type DashboardService interface {
-	GetOverview(ctx context.Context) (DashboardOverviewDTO, error)
-	GetSectionStats(ctx context.Context) ([]SectionStatsDTO, error)
+	GetOverview(ctx context.Context, filter DashboardFilter) (DashboardOverviewDTO, CacheValidators, error)
+	GetSectionStats(ctx context.Context, filter DashboardFilter) ([]SectionStatsDTO, CacheValidators, error)
+	GetTrends(ctx context.Context, q TrendQuery) (TrendsDTO, error)
+	GetFunnel(ctx context.Context, filter DashboardFilter) (FunnelDTO, error)
+	Invalidate()
 }
 
 type dashboardService struct {
-	repo repositories.DashboardRepository
+	repo  repositories.DashboardRepository
+	terms repositories.LeaderboardRepository
+	cache *dashboardCache
 }
 
-func NewDashboardService(repo repositories.DashboardRepository) DashboardService {
-	return &dashboardService{repo: repo}
+// NewDashboardService constructs a DashboardService caching responses for cacheTTL. A
+// non-positive cacheTTL disables caching; validators are still computed. terms resolves
+// the academic terms named by filters.
+func NewDashboardService(repo repositories.DashboardRepository, terms repositories.LeaderboardRepository, cacheTTL time.Duration) DashboardService {
+	return &dashboardService{repo: repo, terms: terms, cache: newDashboardCache(cacheTTL)}
 }
 
-func (s *dashboardService) GetOverview(ctx context.Context) (DashboardOverviewDTO, error) {
-	ov, err := s.repo.GetOverview(ctx)
+// resolveFilter validates f and turns its term into an upload range, intersected with any
+// explicit range.
+func (s *dashboardService) resolveFilter(ctx context.Context, f DashboardFilter) (repositories.DashboardFilter, error) {
+	filter := repositories.DashboardFilter{
+		UploadedFrom: f.UploadedFrom,
+		UploadedTo:   f.UploadedTo,
+		UploadedBy:   strings.TrimSpace(f.UploadedBy),
+		Department:   normalizeDepartment(f.Department),
+	}
+	if strings.TrimSpace(f.Category) != "" {
+		filter.Category = normalizeCategory(f.Category)
+	}
+	if name := strings.TrimSpace(f.Term); name != "" {
+		term, err := s.terms.GetTermByName(ctx, name)
+		if err != nil {
+			return repositories.DashboardFilter{}, err
+		}
+		from, to := term.StartsOn, term.EndsBefore()
+		if filter.UploadedFrom == nil || filter.UploadedFrom.Before(from) {
+			filter.UploadedFrom = &from
+		}
+		if filter.UploadedTo == nil || filter.UploadedTo.After(to) {
+			filter.UploadedTo = &to
+		}
+	}
+	if filter.UploadedFrom != nil && filter.UploadedTo != nil && !filter.UploadedFrom.Before(*filter.UploadedTo) {
+		return repositories.DashboardFilter{}, ErrInvalidDashboardRange
+	}
+	return filter, nil
+}
+
+// cacheKey builds the cache key of a tile for a resolved filter.
+func cacheKey(tile string, f repositories.DashboardFilter) dashboardCacheKey {
+	key := dashboardCacheKey{
+		tile:       tile,
+		category:   f.Category,
+		uploadedBy: f.UploadedBy,
+		department: f.Department,
+	}
+	if f.UploadedFrom != nil {
+		key.hasFrom, key.from = true, f.UploadedFrom.UnixNano()
+	}
+	if f.UploadedTo != nil {
+		key.hasTo, key.to = true, f.UploadedTo.UnixNano()
+	}
+	return key
+}
+
+// Invalidate drops every cached response so the next request reloads it.
+func (s *dashboardService) Invalidate() {
+	s.cache.invalidate()
+}
+
+func (s *dashboardService) GetOverview(ctx context.Context, f DashboardFilter) (DashboardOverviewDTO, CacheValidators, error) {
+	filter, err := s.resolveFilter(ctx, f)
+	if err != nil {
+		return DashboardOverviewDTO{}, CacheValidators{}, err
+	}
+	key := cacheKey(dashboardCacheOverview, filter)
+	now := time.Now()
+	entry, generation := s.cache.get(key, now)
+	if entry != nil {
+		return entry.value.(DashboardOverviewDTO), entry.validators, nil
+	}
+
+	ov, err := s.repo.GetOverview(ctx, filter)
 	if err != nil {
-		return DashboardOverviewDTO{}, err
+		return DashboardOverviewDTO{}, CacheValidators{}, err
 	}
-	return DashboardOverviewDTO{
+	result := DashboardOverviewDTO{
 		TotalStudents:     ov.TotalStudents,
 		TotalCertificates: ov.TotalCertificates,
 		VerifiedCount:     ov.VerifiedCertificates,
 		RejectedCount:     ov.RejectedCertificates,
 		PendingCount:      ov.PendingCertificates,
-	}, nil
+	}
+	validators, err := s.cache.put(key, generation, result, now)
+	return result, validators, err
 }
 
-func (s *dashboardService) GetSectionStats(ctx context.Context) ([]SectionStatsDTO, error) {
-	rows, err := s.repo.GetSectionStats(ctx)
+func (s *dashboardService) GetSectionStats(ctx context.Context, f DashboardFilter) ([]SectionStatsDTO, CacheValidators, error) {
+	filter, err := s.resolveFilter(ctx, f)
+	if err != nil {
+		return nil, CacheValidators{}, err
+	}
+	key := cacheKey(dashboardCacheSections, filter)
+	now := time.Now()
+	entry, generation := s.cache.get(key, now)
+	if entry != nil {
+		return entry.value.([]SectionStatsDTO), entry.validators, nil
+	}
+
+	rows, err := s.repo.GetSectionStats(ctx, filter)
 	if err != nil {
-		return nil, err
+		return nil, CacheValidators{}, err
 	}
 	result := make([]SectionStatsDTO, 0, len(rows))
 	for _, r := range rows {
@@ -62,14 +272,185 @@ func (s *dashboardService) GetSectionStats(ctx context.Context) ([]SectionStatsD
 		if total > 0 {
 			rate = float64(r.VerifiedCertificates) / float64(total)
 		}
+		var participation float64
+		if r.PresentStudents > 0 {
+			participation = float64(r.ParticipatingStudents) / float64(r.PresentStudents)
+		}
 		result = append(result, SectionStatsDTO{
-			Section:           r.Section,
-			TotalCertificates: total,
-			VerifiedCount:     r.VerifiedCertificates,
-			RejectedCount:     r.RejectedCertificates,
-			PendingCount:      r.PendingCertificates,
-			VerificationRate:  rate,
+			Section:               r.Section,
+			TotalCertificates:     total,
+			VerifiedCount:         r.VerifiedCertificates,
+			RejectedCount:         r.RejectedCertificates,
+			PendingCount:          r.PendingCertificates,
+			VerificationRate:      rate,
+			TotalStudents:         r.TotalStudents,
+			PresentStudents:       r.PresentStudents,
+			AbsentStudents:        r.AbsentStudents,
+			ParticipatingStudents: r.ParticipatingStudents,
+			ParticipationRate:     participation,
+			NonSubmitters:         r.NonSubmitters,
 		})
 	}
-	return result, nil
+	validators, err := s.cache.put(key, generation, result, now)
+	return result, validators, err
+}
+
+// GetTrends returns per-section counts of uploads, ML verifications and decisions per bucket.
+// Buckets without events are filled with zeros so every series has the same periods.
+func (s *dashboardService) GetTrends(ctx context.Context, q TrendQuery) (TrendsDTO, error) {
+	bucket := q.Bucket
+	if bucket == "" {
+		bucket = TrendBucketWeek
+	}
+	if bucket != TrendBucketDay && bucket != TrendBucketWeek && bucket != TrendBucketMonth {
+		return TrendsDTO{}, ErrInvalidTrendBucket
+	}
+	filter, err := s.resolveFilter(ctx, q.Filter)
+	if err != nil {
+		return TrendsDTO{}, err
+	}
+
+	to := time.Now().UTC()
+	if q.To != nil {
+		to = q.To.UTC()
+	}
+	var from time.Time
+	switch {
+	case q.From != nil:
+		from = q.From.UTC()
+	case bucket == TrendBucketDay:
+		from = truncateToBucket(to, bucket).AddDate(0, 0, -29)
+	case bucket == TrendBucketWeek:
+		from = truncateToBucket(to, bucket).AddDate(0, 0, -7*11)
+	default:
+		from = truncateToBucket(to, bucket).AddDate(0, -11, 0)
+	}
+	if !from.Before(to) {
+		return TrendsDTO{}, ErrInvalidTrendRange
+	}
+
+	var periods []string
+	for start := truncateToBucket(from, bucket); start.Before(to); start = nextBucket(start, bucket) {
+		if len(periods) == maxTrendBuckets {
+			return TrendsDTO{}, ErrTrendRangeTooLarge
+		}
+		periods = append(periods, start.Format("2006-01-02"))
+	}
+
+	rows, err := s.repo.GetTrends(ctx, repositories.TrendQuery{Bucket: bucket, From: from, To: to, Section: q.Section, Filter: filter})
+	if err != nil {
+		return TrendsDTO{}, err
+	}
+
+	index := make(map[string]int, len(periods))
+	for i, p := range periods {
+		index[p] = i
+	}
+	newSeries := func() []TrendPointDTO {
+		points := make([]TrendPointDTO, len(periods))
+		for i, p := range periods {
+			points[i].PeriodStart = p
+		}
+		return points
+	}
+
+	totals := newSeries()
+	sections := make([]SectionTrendDTO, 0)
+	for _, row := range rows {
+		i, ok := index[row.BucketStart.Format("2006-01-02")]
+		if !ok {
+			continue
+		}
+		if len(sections) == 0 || sections[len(sections)-1].Section != row.Section {
+			sections = append(sections, SectionTrendDTO{Section: row.Section, Points: newSeries()})
+		}
+		for _, p := range []*TrendPointDTO{&sections[len(sections)-1].Points[i], &totals[i]} {
+			p.Uploaded += row.Uploaded
+			p.MLVerified += row.MLVerified
+			p.Legit += row.Legit
+			p.NotLegit += row.NotLegit
+		}
+	}
+
+	return TrendsDTO{
+		Bucket:   bucket,
+		From:     from,
+		To:       to,
+		Periods:  periods,
+		Totals:   totals,
+		Sections: sections,
+	}, nil
+}
+
+// truncateToBucket mirrors Postgres date_trunc in UTC; weeks start on Monday.
+func truncateToBucket(t time.Time, bucket string) time.Time {
+	t = t.UTC()
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	switch bucket {
+	case TrendBucketWeek:
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case TrendBucketMonth:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return day
+	}
+}
+
+func nextBucket(start time.Time, bucket string) time.Time {
+	switch bucket {
+	case TrendBucketWeek:
+		return start.AddDate(0, 0, 7)
+	case TrendBucketMonth:
+		return start.AddDate(0, 1, 0)
+	default:
+		return start.AddDate(0, 0, 1)
+	}
+}
+
+// GetFunnel returns stage counts and conversion rates for the department and each section.
+func (s *dashboardService) GetFunnel(ctx context.Context, f DashboardFilter) (FunnelDTO, error) {
+	filter, err := s.resolveFilter(ctx, f)
+	if err != nil {
+		return FunnelDTO{}, err
+	}
+	rows, err := s.repo.GetFunnel(ctx, filter)
+	if err != nil {
+		return FunnelDTO{}, err
+	}
+
+	var department repositories.FunnelRow
+	sections := make([]SectionFunnelDTO, 0, len(rows))
+	for _, r := range rows {
+		department.Uploaded += r.Uploaded
+		department.MLPending += r.MLPending
+		department.MLVerified += r.MLVerified
+		department.MLDuplicate += r.MLDuplicate
+		department.FacultyPending += r.FacultyPending
+		department.Legit += r.Legit
+		department.NotLegit += r.NotLegit
+		department.Archived += r.Archived
+		sections = append(sections, SectionFunnelDTO{Section: r.Section, Stages: funnelStages(r)})
+	}
+	return FunnelDTO{Department: funnelStages(department), Sections: sections}, nil
+}
+
+func funnelStages(r repositories.FunnelRow) []FunnelStageDTO {
+	decided := r.Legit + r.NotLegit
+	stage := func(name string, count int64, of string, base int64) FunnelStageDTO {
+		var rate float64
+		if base > 0 {
+			rate = float64(count) / float64(base)
+		}
+		return FunnelStageDTO{Stage: name, Count: count, Of: of, Rate: rate}
+	}
+	return []FunnelStageDTO{
+		stage(FunnelStageUploaded, r.Uploaded, "", r.Uploaded),
+		stage(FunnelStageMLPending, r.MLPending, FunnelStageUploaded, r.Uploaded),
+		stage(FunnelStageMLVerified, r.MLVerified, FunnelStageUploaded, r.Uploaded),
+		stage(FunnelStageMLDuplicate, r.MLDuplicate, FunnelStageUploaded, r.Uploaded),
+		stage(FunnelStageFacultyPending, r.FacultyPending, FunnelStageMLVerified, r.MLVerified),
+		stage(FunnelStageLegit, r.Legit, funnelDecided, decided),
+		stage(FunnelStageNotLegit, r.NotLegit, funnelDecided, decided),
+		stage(FunnelStageArchived, r.Archived, FunnelStageUploaded, r.Uploaded),
+	}
 }
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -22,9 +23,26 @@ type StudentStatsDTO struct {
 	Pending        int64  `json:"pending_count"`
 }
 
+// FacultyStatsDTO is one faculty member's upload and review performance. Ratios are over
+// reviewed certificates; decision times are hours from ML verification to decision.
+type FacultyStatsDTO struct {
+	Faculty             string   `json:"faculty"`
+	Uploaded            int64    `json:"uploaded_count"`
+	Reviewed            int64    `json:"reviewed_count"`
+	Legit               int64    `json:"legit_count"`
+	NotLegit            int64    `json:"not_legit_count"`
+	LegitRatio          float64  `json:"legit_ratio"`
+	MedianDecisionHours *float64 `json:"median_decision_hours"`
+	P90DecisionHours    *float64 `json:"p90_decision_hours"`
+	MLDisagreements     int64    `json:"ml_disagreement_count"`
+	MLDisagreementRate  float64  `json:"ml_disagreement_rate"`
+}
+
 // HodService defines HOD-facing operations.
 type HodService interface {
 	GetStudentStatsByFaculty(ctx context.Context, facultyID string) ([]StudentStatsDTO, error)
+	GetFacultyStats(ctx context.Context, from, to *time.Time) ([]FacultyStatsDTO, error)
+	ExportFacultyStats(ctx context.Context, from, to *time.Time) (string, []byte, error)
 	ListStudentCertificates(ctx context.Context, regNo string) ([]models.Certificate, error)
 	ExportCertificatesBySection(ctx context.Context, section string) (string, []byte, error)
 	ExportCertificatesByStudent(ctx context.Context, regNo string) (string, []byte, error)
@@ -86,6 +104,78 @@ func (s *hodService) ExportCertificatesByStudent(ctx context.Context, regNo stri
 	return filename, bytes, err
 }
 
+// GetFacultyStats returns per-faculty upload and review metrics within [from, to).
+func (s *hodService) GetFacultyStats(ctx context.Context, from, to *time.Time) ([]FacultyStatsDTO, error) {
+	rows, err := s.repo.GetFacultyPerformance(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]FacultyStatsDTO, 0, len(rows))
+	for _, r := range rows {
+		dto := FacultyStatsDTO{
+			Faculty:             r.Faculty,
+			Uploaded:            r.Uploaded,
+			Reviewed:            r.Reviewed,
+			Legit:               r.Legit,
+			NotLegit:            r.NotLegit,
+			MedianDecisionHours: roundHours(r.MedianDecisionHours),
+			P90DecisionHours:    roundHours(r.P90DecisionHours),
+			MLDisagreements:     r.MLDisagreements,
+		}
+		if r.Reviewed > 0 {
+			dto.LegitRatio = float64(r.Legit) / float64(r.Reviewed)
+			dto.MLDisagreementRate = float64(r.MLDisagreements) / float64(r.Reviewed)
+		}
+		stats = append(stats, dto)
+	}
+	return stats, nil
+}
+
+func (s *hodService) ExportFacultyStats(ctx context.Context, from, to *time.Time) (string, []byte, error) {
+	stats, err := s.GetFacultyStats(ctx, from, to)
+	if err != nil {
+		return "", nil, err
+	}
+	rows := make([]excel.FacultyStatsRow, 0, len(stats))
+	for _, st := range stats {
+		rows = append(rows, excel.FacultyStatsRow{
+			Faculty:             st.Faculty,
+			Uploaded:            st.Uploaded,
+			Reviewed:            st.Reviewed,
+			Legit:               st.Legit,
+			NotLegit:            st.NotLegit,
+			LegitRatio:          st.LegitRatio,
+			MedianDecisionHours: st.MedianDecisionHours,
+			P90DecisionHours:    st.P90DecisionHours,
+			MLDisagreements:     st.MLDisagreements,
+			MLDisagreementRate:  st.MLDisagreementRate,
+		})
+	}
+
+	period := "all time"
+	switch {
+	case from != nil && to != nil:
+		period = fmt.Sprintf("%s to %s", from.Format(time.RFC3339), to.Format(time.RFC3339))
+	case from != nil:
+		period = "from " + from.Format(time.RFC3339)
+	case to != nil:
+		period = "until " + to.Format(time.RFC3339)
+	}
+	filename := fmt.Sprintf("faculty_stats_%d.xlsx", time.Now().Unix())
+	bytes, err := excel.BuildFacultyStatsWorkbook(rows, period)
+	return filename, bytes, err
+}
+
+// roundHours keeps two decimals of a duration in hours.
+func roundHours(h *float64) *float64 {
+	if h == nil {
+		return nil
+	}
+	rounded := math.Round(*h*100) / 100
+	return &rounded
+}
+
 // sanitizeForFilename is a minimal helper to keep filenames readable.
 func sanitizeForFilename(val string) string {
 	if val == "" {
@@ -0,0 +1,52 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"department-eduvault-backend/repositories"
+
+	"go.uber.org/zap"
+)
+
+// IdempotencyService expires stored Idempotency-Key records.
+type IdempotencyService interface {
+	DeleteExpired(ctx context.Context) (int64, error)
+	RunExpirySweep(ctx context.Context, interval time.Duration, logger *zap.Logger)
+}
+
+type idempotencyService struct {
+	repo repositories.IdempotencyRepository
+	ttl  time.Duration
+}
+
+// NewIdempotencyService constructs an IdempotencyService keeping completed responses for ttl.
+func NewIdempotencyService(repo repositories.IdempotencyRepository, ttl time.Duration) IdempotencyService {
+	return &idempotencyService{repo: repo, ttl: ttl}
+}
+
+// DeleteExpired deletes completed keys older than the TTL and abandoned reservations.
+func (s *idempotencyService) DeleteExpired(ctx context.Context) (int64, error) {
+	return s.repo.DeleteExpired(ctx, s.ttl)
+}
+
+// RunExpirySweep deletes expired keys immediately and then on every interval until ctx is
+// cancelled. Failures are logged and retried on the next tick.
+func (s *idempotencyService) RunExpirySweep(ctx context.Context, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if deleted, err := s.DeleteExpired(ctx); err != nil {
+			logger.Error("idempotency key sweep failed", zap.Error(err))
+		} else if deleted > 0 {
+			logger.Debug("deleted expired idempotency keys", zap.Int64("count", deleted))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
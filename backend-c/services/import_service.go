@@ -0,0 +1,303 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"department-eduvault-backend/internal/excel"
+	"department-eduvault-backend/models"
+	"department-eduvault-backend/repositories"
+)
+
+var (
+	ErrImportEmpty        = errors.New("import file has no data rows")
+	ErrImportTooLarge     = errors.New("import file has too many rows")
+	ErrImportJobForbidden = errors.New("import job belongs to another user")
+)
+
+const (
+	maxImportRows   = 5000
+	importChunkSize = 100
+)
+
+// Date layouts accepted in the "Uploaded At" column: ISO 8601 and day-first DD/MM/YYYY.
+// Month-first dates are not accepted, so 03/04/2025 always means 3 April.
+var importDateLayouts = []string{time.RFC3339, "2006-01-02", "02/01/2006"}
+
+// ImportCaller identifies who is starting or polling an import.
+type ImportCaller struct {
+	Email string
+	Role  string
+}
+
+// ImportService runs spreadsheet imports as background jobs.
+type ImportService interface {
+	StartCertificateImport(ctx context.Context, caller ImportCaller, filename string, data []byte) (*models.ImportJob, error)
+	GetJob(ctx context.Context, caller ImportCaller, jobID string) (*models.ImportJob, error)
+	ErrorReport(ctx context.Context, caller ImportCaller, jobID string) (string, []byte, error)
+	CertificateTemplate() (string, []byte, error)
+}
+
+type importService struct {
+	jobs          repositories.ImportJobRepository
+	certRepo      repositories.CertificateRepository
+	certs         CertificateService
+	roster        repositories.StudentRepository
+	sectionPolicy SectionMismatchPolicy
+}
+
+// NewImportService constructs an ImportService. The certificate service is used to kick off
+// ML verification for imported rows, as regular uploads do; rows are checked against roster
+// under sectionPolicy like uploads are.
+func NewImportService(jobs repositories.ImportJobRepository, certRepo repositories.CertificateRepository, certs CertificateService, roster repositories.StudentRepository, sectionPolicy SectionMismatchPolicy) ImportService {
+	if sectionPolicy != SectionMismatchReject {
+		sectionPolicy = SectionMismatchCorrect
+	}
+	return &importService{jobs: jobs, certRepo: certRepo, certs: certs, roster: roster, sectionPolicy: sectionPolicy}
+}
+
+// StartCertificateImport validates every row up front, including against the student roster,
+// records the job with its row errors, and inserts the valid rows in the background in
+// chunked transactions.
+func (s *importService) StartCertificateImport(ctx context.Context, caller ImportCaller, filename string, data []byte) (*models.ImportJob, error) {
+	rows, err := excel.ParseCertificateImport(filename, data)
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) == 0 {
+		return nil, ErrImportEmpty
+	}
+	if len(rows) > maxImportRows {
+		return nil, ErrImportTooLarge
+	}
+
+	certs, certRows, rowErrors := validateCertificateImportRows(rows, caller.Email)
+	certs, rosterErrors, err := s.checkImportRoster(ctx, certs, certRows)
+	if err != nil {
+		return nil, err
+	}
+	rowErrors = append(rowErrors, rosterErrors...)
+	job := &models.ImportJob{
+		Kind:        models.ImportJobKindCertificates,
+		Status:      models.ImportJobQueued,
+		FileName:    filename,
+		CreatedBy:   caller.Email,
+		TotalRows:   len(rows),
+		ValidRows:   len(certs),
+		InvalidRows: len(rows) - len(certs),
+		CreatedAt:   time.Now().UTC(),
+	}
+	if len(certs) == 0 {
+		now := time.Now().UTC()
+		job.Status = models.ImportJobCompleted
+		job.FinishedAt = &now
+	}
+	if err := s.jobs.Create(ctx, job, rowErrors); err != nil {
+		return nil, err
+	}
+
+	if len(certs) > 0 {
+		go s.runCertificateImport(job.ID, certs)
+	}
+	return job, nil
+}
+
+// runCertificateImport inserts certificates chunk by chunk. A failing chunk stops the job;
+// earlier chunks stay committed and are reflected in processed_rows.
+func (s *importService) runCertificateImport(jobID string, certs []models.Certificate) {
+	ctx := context.Background()
+	if err := s.jobs.MarkRunning(ctx, jobID); err != nil {
+		_ = s.jobs.Finish(ctx, jobID, models.ImportJobFailed, err.Error())
+		return
+	}
+
+	for start := 0; start < len(certs); start += importChunkSize {
+		end := start + importChunkSize
+		if end > len(certs) {
+			end = len(certs)
+		}
+		chunk := certs[start:end]
+		if err := s.certRepo.CreateCertificatesBulk(ctx, chunk); err != nil {
+			_ = s.jobs.Finish(ctx, jobID, models.ImportJobFailed, fmt.Sprintf("rows %d-%d: %v", start+1, end, err))
+			return
+		}
+		_ = s.jobs.AddProgress(ctx, jobID, len(chunk))
+
+		for _, cert := range chunk {
+			_ = s.certs.TriggerMockMLVerification(ctx, cert.ID)
+		}
+	}
+
+	_ = s.jobs.Finish(ctx, jobID, models.ImportJobCompleted, "")
+}
+
+// GetJob returns a job for polling; faculty may only see their own jobs.
+func (s *importService) GetJob(ctx context.Context, caller ImportCaller, jobID string) (*models.ImportJob, error) {
+	job, err := s.jobs.GetByID(ctx, strings.TrimSpace(jobID))
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(caller.Role, "hod") && job.CreatedBy != caller.Email {
+		return nil, ErrImportJobForbidden
+	}
+	return job, nil
+}
+
+// ErrorReport renders the job's row errors and warnings as a downloadable workbook.
+func (s *importService) ErrorReport(ctx context.Context, caller ImportCaller, jobID string) (string, []byte, error) {
+	job, err := s.GetJob(ctx, caller, jobID)
+	if err != nil {
+		return "", nil, err
+	}
+	rowErrors, err := s.jobs.ListErrors(ctx, job.ID)
+	if err != nil {
+		return "", nil, err
+	}
+	filename := fmt.Sprintf("import_errors_%s.xlsx", job.ID)
+	content, err := excel.BuildImportErrorReport(rowErrors)
+	return filename, content, err
+}
+
+// CertificateTemplate returns the blank import workbook.
+func (s *importService) CertificateTemplate() (string, []byte, error) {
+	content, err := excel.BuildCertificateImportTemplate()
+	return "certificate_import_template.xlsx", content, err
+}
+
+// checkImportRoster validates certificates, from the spreadsheet rows numbered certRows,
+// against the student roster as checkAgainstRoster does for uploads. Accepted certificates are
+// returned under the roster's register number, section and name; each rejection becomes a
+// row error, and each upload warning (corrected section, name mismatch) a row warning.
+func (s *importService) checkImportRoster(ctx context.Context, certs []models.Certificate, certRows []int) ([]models.Certificate, []models.ImportJobError, error) {
+	if len(certs) == 0 {
+		return certs, nil, nil
+	}
+	regNos := make([]string, 0, len(certs))
+	seen := make(map[string]bool, len(certs))
+	for _, cert := range certs {
+		regNo := strings.ToUpper(strings.TrimSpace(cert.RegisterNumber))
+		if !seen[regNo] {
+			seen[regNo] = true
+			regNos = append(regNos, regNo)
+		}
+	}
+	students, err := s.roster.GetByRegisterNumbers(ctx, regNos)
+	if err != nil {
+		return nil, nil, err
+	}
+	byRegNo := make(map[string]*models.Student, len(students))
+	for i := range students {
+		byRegNo[students[i].RegisterNumber] = &students[i]
+	}
+
+	accepted := make([]models.Certificate, 0, len(certs))
+	var rowErrors []models.ImportJobError
+	for i, cert := range certs {
+		in := CertificateInput{RegisterNumber: cert.RegisterNumber, Section: cert.Section, StudentName: cert.StudentName}
+		item, student := checkAgainstRoster(i, in, byRegNo[strings.ToUpper(strings.TrimSpace(cert.RegisterNumber))], s.sectionPolicy)
+		if student == nil {
+			for _, issue := range item.Errors {
+				field := "Register Number"
+				if issue.Code == UploadIssueSectionMismatch {
+					field = "Section"
+				}
+				rowErrors = append(rowErrors, models.ImportJobError{RowNumber: certRows[i], Field: field, Message: issue.Message})
+			}
+			continue
+		}
+		for _, issue := range item.Warnings {
+			field := "Section"
+			if issue.Code == UploadIssueNameMismatch {
+				field = "Student Name"
+			}
+			rowErrors = append(rowErrors, models.ImportJobError{RowNumber: certRows[i], Field: field, Message: issue.Message, Warning: true})
+		}
+		cert.RegisterNumber = student.RegisterNumber
+		cert.Section = student.Section
+		cert.StudentName = student.Name
+		accepted = append(accepted, cert)
+	}
+	return accepted, rowErrors, nil
+}
+
+// validateCertificateImportRows converts valid rows to certificates, returned with their row
+// numbers, and collects one error per failing field for the rest.
+func validateCertificateImportRows(rows []excel.CertificateImportRow, uploadedBy string) ([]models.Certificate, []int, []models.ImportJobError) {
+	now := time.Now().UTC()
+	seenLinks := make(map[string]int, len(rows))
+	certs := make([]models.Certificate, 0, len(rows))
+	certRows := make([]int, 0, len(rows))
+	var rowErrors []models.ImportJobError
+
+	for _, row := range rows {
+		var errs []models.ImportJobError
+		fail := func(field, msg string) {
+			errs = append(errs, models.ImportJobError{RowNumber: row.RowNumber, Field: field, Message: msg})
+		}
+
+		if row.RegisterNumber == "" {
+			fail("Register Number", "register number is required")
+		}
+		if row.StudentName == "" {
+			fail("Student Name", "student name is required")
+		}
+		if row.Section == "" {
+			fail("Section", "section is required")
+		}
+		switch {
+		case row.DriveLink == "":
+			fail("Drive Link", "drive link is required")
+		case !driveLinkPattern.MatchString(row.DriveLink):
+			fail("Drive Link", ErrInvalidDriveLink.Error())
+		default:
+			if first, dup := seenLinks[row.DriveLink]; dup {
+				fail("Drive Link", fmt.Sprintf("duplicate of row %d", first))
+			} else {
+				seenLinks[row.DriveLink] = row.RowNumber
+			}
+		}
+
+		uploadedAt := now
+		if row.UploadedAt != "" {
+			parsed, ok := parseImportDate(row.UploadedAt)
+			if !ok {
+				fail("Uploaded At", "uploaded at must be a date (YYYY-MM-DD or DD/MM/YYYY) or RFC3339 timestamp")
+			} else {
+				uploadedAt = parsed
+			}
+		}
+
+		if len(errs) > 0 {
+			rowErrors = append(rowErrors, errs...)
+			continue
+		}
+		certRows = append(certRows, row.RowNumber)
+		certs = append(certs, models.Certificate{
+			DriveLink:      row.DriveLink,
+			RegisterNumber: row.RegisterNumber,
+			Section:        row.Section,
+			StudentName:    row.StudentName,
+			Title:          row.Title,
+			Issuer:         row.Issuer,
+			Category:       normalizeCategory(row.Category),
+			UploadedBy:     uploadedBy,
+			UploadedAt:     uploadedAt,
+			MLStatus:       models.MLStatusPending,
+			FacultyStatus:  models.FacultyStatusPending,
+			Archived:       false,
+		})
+	}
+	return certs, certRows, rowErrors
+}
+
+func parseImportDate(val string) (time.Time, bool) {
+	for _, layout := range importDateLayouts {
+		if t, err := time.Parse(layout, val); err == nil {
+			return t.UTC(), true
+		}
+	}
+	return time.Time{}, false
+}
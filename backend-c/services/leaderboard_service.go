@@ -0,0 +1,251 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"department-eduvault-backend/internal/excel"
+	"department-eduvault-backend/models"
+	"department-eduvault-backend/repositories"
+)
+
+var (
+	ErrInvalidLeaderboardScope  = errors.New("scope must be department or section")
+	ErrInvalidLeaderboardMetric = errors.New("metric must be legit, points or category")
+	ErrLeaderboardCategory      = errors.New("category is required for the category metric")
+	ErrInvalidLeaderboardLimit  = errors.New("top must be between 1 and 500")
+	ErrInvalidAcademicTerm      = errors.New("term name, starts_on and ends_on are required and ends_on must not precede starts_on")
+	ErrInvalidCategoryPoints    = errors.New("category is required and points must not be negative")
+)
+
+// Leaderboard scopes.
+const (
+	LeaderboardScopeDepartment = "department"
+	LeaderboardScopeSection    = "section"
+)
+
+const (
+	defaultLeaderboardLimit = 10
+	maxLeaderboardLimit     = 500
+)
+
+// LeaderboardQuery is the validated input of GET /dashboard/leaderboard. Section narrows the
+// section scope to one section; Term names an academic term bounding upload dates.
+type LeaderboardQuery struct {
+	Scope    string
+	Section  string
+	Metric   string
+	Category string
+	Term     string
+	Top      int
+}
+
+// LeaderboardEntryDTO is one ranked student.
+type LeaderboardEntryDTO struct {
+	Rank           int64   `json:"rank"`
+	Percentile     float64 `json:"percentile"`
+	RegisterNumber string  `json:"register_number"`
+	StudentName    string  `json:"student_name"`
+	Section        string  `json:"section"`
+	LegitCount     int64   `json:"legit_count"`
+	Points         float64 `json:"points"`
+	Score          float64 `json:"score"`
+}
+
+// LeaderboardDTO is the response of GET /dashboard/leaderboard.
+type LeaderboardDTO struct {
+	Scope    string                `json:"scope"`
+	Section  string                `json:"section,omitempty"`
+	Metric   string                `json:"metric"`
+	Category string                `json:"category,omitempty"`
+	Term     *models.AcademicTerm  `json:"term,omitempty"`
+	Top      int                   `json:"top"`
+	Entries  []LeaderboardEntryDTO `json:"entries"`
+}
+
+// AcademicTermInput creates an academic term; dates are inclusive.
+type AcademicTermInput struct {
+	Name      string
+	StartsOn  time.Time
+	EndsOn    time.Time
+	CreatedBy string
+}
+
+// LeaderboardService ranks students by verified certificates and manages the academic terms
+// and category weights the rankings use.
+type LeaderboardService interface {
+	GetLeaderboard(ctx context.Context, q LeaderboardQuery) (LeaderboardDTO, error)
+	ExportLeaderboard(ctx context.Context, q LeaderboardQuery) (string, []byte, error)
+	ListTerms(ctx context.Context) ([]models.AcademicTerm, error)
+	CreateTerm(ctx context.Context, input AcademicTermInput) (*models.AcademicTerm, error)
+	ListCategoryPoints(ctx context.Context) ([]models.CategoryPoints, error)
+	SetCategoryPoints(ctx context.Context, category string, points float64, updatedBy string) (*models.CategoryPoints, error)
+}
+
+type leaderboardService struct {
+	repo repositories.LeaderboardRepository
+}
+
+// NewLeaderboardService constructs a LeaderboardService.
+func NewLeaderboardService(repo repositories.LeaderboardRepository) LeaderboardService {
+	return &leaderboardService{repo: repo}
+}
+
+// GetLeaderboard validates q and returns the ranked students. Unknown terms fail with
+// repositories.ErrAcademicTermNotFound.
+func (s *leaderboardService) GetLeaderboard(ctx context.Context, q LeaderboardQuery) (LeaderboardDTO, error) {
+	result := LeaderboardDTO{
+		Scope:   strings.ToLower(strings.TrimSpace(q.Scope)),
+		Section: strings.TrimSpace(q.Section),
+		Metric:  strings.ToLower(strings.TrimSpace(q.Metric)),
+		Top:     q.Top,
+	}
+	if result.Scope == "" {
+		result.Scope = LeaderboardScopeDepartment
+	}
+	if result.Scope != LeaderboardScopeDepartment && result.Scope != LeaderboardScopeSection {
+		return LeaderboardDTO{}, ErrInvalidLeaderboardScope
+	}
+	if result.Scope == LeaderboardScopeDepartment {
+		result.Section = ""
+	}
+	if result.Metric == "" {
+		result.Metric = repositories.LeaderboardMetricLegit
+	}
+	switch result.Metric {
+	case repositories.LeaderboardMetricLegit, repositories.LeaderboardMetricPoints:
+	case repositories.LeaderboardMetricCategory:
+		if strings.TrimSpace(q.Category) == "" {
+			return LeaderboardDTO{}, ErrLeaderboardCategory
+		}
+		result.Category = normalizeCategory(q.Category)
+	default:
+		return LeaderboardDTO{}, ErrInvalidLeaderboardMetric
+	}
+	if result.Top == 0 {
+		result.Top = defaultLeaderboardLimit
+	}
+	if result.Top < 1 || result.Top > maxLeaderboardLimit {
+		return LeaderboardDTO{}, ErrInvalidLeaderboardLimit
+	}
+
+	query := repositories.LeaderboardQuery{
+		Metric:     result.Metric,
+		Category:   result.Category,
+		PerSection: result.Scope == LeaderboardScopeSection,
+		Section:    result.Section,
+		Limit:      result.Top,
+	}
+	if name := strings.TrimSpace(q.Term); name != "" {
+		term, err := s.repo.GetTermByName(ctx, name)
+		if err != nil {
+			return LeaderboardDTO{}, err
+		}
+		from, to := term.StartsOn, term.EndsBefore()
+		query.From, query.To = &from, &to
+		result.Term = term
+	}
+
+	rows, err := s.repo.GetLeaderboard(ctx, query)
+	if err != nil {
+		return LeaderboardDTO{}, err
+	}
+	result.Entries = make([]LeaderboardEntryDTO, 0, len(rows))
+	for _, r := range rows {
+		result.Entries = append(result.Entries, LeaderboardEntryDTO{
+			Rank:           r.Rank,
+			Percentile:     r.Percentile,
+			RegisterNumber: r.RegisterNumber,
+			StudentName:    r.StudentName,
+			Section:        r.Section,
+			LegitCount:     r.LegitCount,
+			Points:         r.Points,
+			Score:          r.Score,
+		})
+	}
+	return result, nil
+}
+
+// ExportLeaderboard renders the same leaderboard as GetLeaderboard into an XLSX file.
+func (s *leaderboardService) ExportLeaderboard(ctx context.Context, q LeaderboardQuery) (string, []byte, error) {
+	board, err := s.GetLeaderboard(ctx, q)
+	if err != nil {
+		return "", nil, err
+	}
+
+	rows := make([]excel.LeaderboardRow, 0, len(board.Entries))
+	for _, e := range board.Entries {
+		rows = append(rows, excel.LeaderboardRow{
+			Rank:           e.Rank,
+			Percentile:     e.Percentile,
+			RegisterNumber: e.RegisterNumber,
+			StudentName:    e.StudentName,
+			Section:        e.Section,
+			LegitCount:     e.LegitCount,
+			Points:         e.Points,
+			Score:          e.Score,
+		})
+	}
+
+	description := fmt.Sprintf("%s leaderboard by %s, top %d", board.Scope, board.Metric, board.Top)
+	if board.Category != "" {
+		description += ", category " + board.Category
+	}
+	if board.Section != "" {
+		description += ", section " + board.Section
+	}
+	termName := "all"
+	if board.Term != nil {
+		termName = board.Term.Name
+		description += ", term " + board.Term.Name
+	}
+	filename := fmt.Sprintf("leaderboard_%s_%s_%s_%d.xlsx",
+		board.Scope, board.Metric, sanitizeForFilename(termName), time.Now().Unix())
+	bytes, err := excel.BuildLeaderboardWorkbook(rows, description)
+	return filename, bytes, err
+}
+
+func (s *leaderboardService) ListTerms(ctx context.Context) ([]models.AcademicTerm, error) {
+	return s.repo.ListTerms(ctx)
+}
+
+// CreateTerm stores a new academic term.
+func (s *leaderboardService) CreateTerm(ctx context.Context, input AcademicTermInput) (*models.AcademicTerm, error) {
+	name := strings.TrimSpace(input.Name)
+	if name == "" || input.StartsOn.IsZero() || input.EndsOn.IsZero() || input.EndsOn.Before(input.StartsOn) {
+		return nil, ErrInvalidAcademicTerm
+	}
+	term := &models.AcademicTerm{
+		Name:      name,
+		StartsOn:  input.StartsOn,
+		EndsOn:    input.EndsOn,
+		CreatedBy: input.CreatedBy,
+	}
+	if err := s.repo.CreateTerm(ctx, term); err != nil {
+		return nil, err
+	}
+	return term, nil
+}
+
+func (s *leaderboardService) ListCategoryPoints(ctx context.Context) ([]models.CategoryPoints, error) {
+	return s.repo.ListCategoryPoints(ctx)
+}
+
+// SetCategoryPoints creates or replaces the points a LEGIT certificate of category earns.
+func (s *leaderboardService) SetCategoryPoints(ctx context.Context, category string, points float64, updatedBy string) (*models.CategoryPoints, error) {
+	if strings.TrimSpace(category) == "" || points < 0 {
+		return nil, ErrInvalidCategoryPoints
+	}
+	row := &models.CategoryPoints{
+		Category:  normalizeCategory(category),
+		Points:    points,
+		UpdatedBy: updatedBy,
+	}
+	if err := s.repo.UpsertCategoryPoints(ctx, row); err != nil {
+		return nil, err
+	}
+	return row, nil
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"department-eduvault-backend/models"
+	"department-eduvault-backend/repositories"
+)
+
+var (
+	ErrInvalidMLPolicy = errors.New("thresholds must be between 0 and 100 with hod_review_threshold <= auto_approve_threshold")
+)
+
+// MLPolicyInput describes a new ML decision policy version.
+type MLPolicyInput struct {
+	AutoApproveThreshold float64
+	HodReviewThreshold   float64
+	Enabled              bool
+	CreatedBy            string
+}
+
+// MLPolicyService manages the versioned score-based auto-decision policy.
+type MLPolicyService interface {
+	GetActive(ctx context.Context) (*models.MLDecisionPolicy, error)
+	ListVersions(ctx context.Context) ([]models.MLDecisionPolicy, error)
+	CreateVersion(ctx context.Context, input MLPolicyInput) (*models.MLDecisionPolicy, error)
+}
+
+type mlPolicyService struct {
+	repo repositories.MLPolicyRepository
+}
+
+// NewMLPolicyService constructs an MLPolicyService.
+func NewMLPolicyService(repo repositories.MLPolicyRepository) MLPolicyService {
+	return &mlPolicyService{repo: repo}
+}
+
+func (s *mlPolicyService) GetActive(ctx context.Context) (*models.MLDecisionPolicy, error) {
+	return s.repo.GetActive(ctx)
+}
+
+func (s *mlPolicyService) ListVersions(ctx context.Context) ([]models.MLDecisionPolicy, error) {
+	return s.repo.ListVersions(ctx)
+}
+
+// CreateVersion validates the thresholds and stores them as the new active version.
+// Certificates already routed keep the version that decided them.
+func (s *mlPolicyService) CreateVersion(ctx context.Context, input MLPolicyInput) (*models.MLDecisionPolicy, error) {
+	if input.HodReviewThreshold < 0 || input.AutoApproveThreshold > 100 ||
+		input.HodReviewThreshold > input.AutoApproveThreshold {
+		return nil, ErrInvalidMLPolicy
+	}
+	policy := &models.MLDecisionPolicy{
+		AutoApproveThreshold: input.AutoApproveThreshold,
+		HodReviewThreshold:   input.HodReviewThreshold,
+		Enabled:              input.Enabled,
+		CreatedBy:            input.CreatedBy,
+		CreatedAt:            time.Now().UTC(),
+	}
+	if err := s.repo.Create(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
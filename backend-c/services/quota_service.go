@@ -0,0 +1,261 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"department-eduvault-backend/internal/excel"
+	"department-eduvault-backend/models"
+	"department-eduvault-backend/repositories"
+)
+
+var (
+	ErrComplianceTermRequired = errors.New("term is required")
+	ErrInvalidQuotaRule       = errors.New("term is required and min_legit must be positive")
+)
+
+// QuotaCategoryAny labels quotas that count LEGIT certificates of every category.
+const QuotaCategoryAny = "ANY"
+
+// QuotaRuleInput creates or replaces the quota for a (term, batch, section, category) scope.
+// Empty Batch, Section and Category match every value.
+type QuotaRuleInput struct {
+	Term      string
+	Batch     string
+	Section   string
+	Category  string
+	MinLegit  int
+	UpdatedBy string
+}
+
+// ComplianceQuery selects the term and students of a compliance report. BehindOnly keeps only
+// students missing at least one quota.
+type ComplianceQuery struct {
+	Term       string
+	Section    string
+	Batch      string
+	BehindOnly bool
+}
+
+// QuotaProgressDTO is a student's progress against one quota.
+type QuotaProgressDTO struct {
+	RuleID   int    `json:"rule_id"`
+	Category string `json:"category"`
+	Required int64  `json:"required"`
+	Earned   int64  `json:"earned"`
+	Deficit  int64  `json:"deficit"`
+}
+
+// StudentComplianceDTO is one student's progress against every quota that applies to them.
+type StudentComplianceDTO struct {
+	RegisterNumber string             `json:"register_number"`
+	StudentName    string             `json:"student_name"`
+	Section        string             `json:"section"`
+	Batch          string             `json:"batch"`
+	FacultyEmail   string             `json:"faculty_email"`
+	Compliant      bool               `json:"compliant"`
+	TotalDeficit   int64              `json:"total_deficit"`
+	Progress       []QuotaProgressDTO `json:"progress"`
+}
+
+// ComplianceReportDTO is the response of GET /hod/compliance. The counts cover every student
+// with an applicable quota, even when BehindOnly trims Students.
+type ComplianceReportDTO struct {
+	Term           models.AcademicTerm    `json:"term"`
+	Section        string                 `json:"section,omitempty"`
+	Batch          string                 `json:"batch,omitempty"`
+	TotalStudents  int                    `json:"total_students"`
+	Compliant      int                    `json:"compliant"`
+	Behind         int                    `json:"behind"`
+	ComplianceRate float64                `json:"compliance_rate"`
+	Students       []StudentComplianceDTO `json:"students"`
+}
+
+// QuotaService manages per-term certificate quotas and reports student compliance.
+type QuotaService interface {
+	ListRules(ctx context.Context, term string) ([]models.QuotaRule, error)
+	SetRule(ctx context.Context, input QuotaRuleInput) (*models.QuotaRule, error)
+	DeleteRule(ctx context.Context, id int) error
+	GetCompliance(ctx context.Context, q ComplianceQuery) (ComplianceReportDTO, error)
+	ExportCompliance(ctx context.Context, q ComplianceQuery) (string, []byte, error)
+}
+
+type quotaService struct {
+	repo  repositories.QuotaRepository
+	terms repositories.LeaderboardRepository
+}
+
+// NewQuotaService constructs a QuotaService. terms resolves academic terms by name.
+func NewQuotaService(repo repositories.QuotaRepository, terms repositories.LeaderboardRepository) QuotaService {
+	return &quotaService{repo: repo, terms: terms}
+}
+
+// ListRules returns the quota rules of the named term, or of every term when term is empty.
+func (s *quotaService) ListRules(ctx context.Context, term string) ([]models.QuotaRule, error) {
+	termID := 0
+	if name := strings.TrimSpace(term); name != "" {
+		t, err := s.terms.GetTermByName(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		termID = t.ID
+	}
+	return s.repo.ListRules(ctx, termID)
+}
+
+// SetRule creates or replaces the quota for the given scope.
+func (s *quotaService) SetRule(ctx context.Context, input QuotaRuleInput) (*models.QuotaRule, error) {
+	name := strings.TrimSpace(input.Term)
+	if name == "" || input.MinLegit <= 0 {
+		return nil, ErrInvalidQuotaRule
+	}
+	term, err := s.terms.GetTermByName(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	rule := &models.QuotaRule{
+		TermID:    term.ID,
+		MinLegit:  input.MinLegit,
+		UpdatedBy: input.UpdatedBy,
+	}
+	if batch := strings.TrimSpace(input.Batch); batch != "" {
+		rule.Batch = &batch
+	}
+	if section := strings.TrimSpace(input.Section); section != "" {
+		rule.Section = &section
+	}
+	if category := strings.TrimSpace(input.Category); category != "" {
+		normalized := normalizeCategory(category)
+		rule.Category = &normalized
+	}
+	if err := s.repo.UpsertRule(ctx, rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *quotaService) DeleteRule(ctx context.Context, id int) error {
+	return s.repo.DeleteRule(ctx, id)
+}
+
+// GetCompliance measures the students of the term's scope against its quotas. Unknown terms
+// fail with repositories.ErrAcademicTermNotFound.
+func (s *quotaService) GetCompliance(ctx context.Context, q ComplianceQuery) (ComplianceReportDTO, error) {
+	name := strings.TrimSpace(q.Term)
+	if name == "" {
+		return ComplianceReportDTO{}, ErrComplianceTermRequired
+	}
+	term, err := s.terms.GetTermByName(ctx, name)
+	if err != nil {
+		return ComplianceReportDTO{}, err
+	}
+
+	report := ComplianceReportDTO{
+		Term:     *term,
+		Section:  strings.TrimSpace(q.Section),
+		Batch:    strings.TrimSpace(q.Batch),
+		Students: make([]StudentComplianceDTO, 0),
+	}
+	rows, err := s.repo.GetCompliance(ctx, repositories.ComplianceQuery{
+		TermID:  term.ID,
+		From:    term.StartsOn,
+		To:      term.EndsBefore(),
+		Section: report.Section,
+		Batch:   report.Batch,
+	})
+	if err != nil {
+		return ComplianceReportDTO{}, err
+	}
+
+	var students []StudentComplianceDTO
+	for _, r := range rows {
+		if len(students) == 0 || students[len(students)-1].RegisterNumber != r.RegisterNumber {
+			students = append(students, StudentComplianceDTO{
+				RegisterNumber: r.RegisterNumber,
+				StudentName:    r.StudentName,
+				Section:        r.Section,
+				Batch:          r.Batch,
+				FacultyEmail:   r.FacultyEmail,
+				Compliant:      true,
+			})
+		}
+		student := &students[len(students)-1]
+		category := QuotaCategoryAny
+		if r.Category != nil {
+			category = *r.Category
+		}
+		var deficit int64
+		if r.Earned < r.Required {
+			deficit = r.Required - r.Earned
+			student.Compliant = false
+		}
+		student.TotalDeficit += deficit
+		student.Progress = append(student.Progress, QuotaProgressDTO{
+			RuleID:   r.RuleID,
+			Category: category,
+			Required: r.Required,
+			Earned:   r.Earned,
+			Deficit:  deficit,
+		})
+	}
+
+	report.TotalStudents = len(students)
+	for _, st := range students {
+		if st.Compliant {
+			report.Compliant++
+		} else {
+			report.Behind++
+		}
+		if !q.BehindOnly || !st.Compliant {
+			report.Students = append(report.Students, st)
+		}
+	}
+	if report.TotalStudents > 0 {
+		report.ComplianceRate = float64(report.Compliant) / float64(report.TotalStudents)
+	}
+	return report, nil
+}
+
+// ExportCompliance renders the same report as GetCompliance into an XLSX file.
+func (s *quotaService) ExportCompliance(ctx context.Context, q ComplianceQuery) (string, []byte, error) {
+	report, err := s.GetCompliance(ctx, q)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var rows []excel.ComplianceRow
+	for _, st := range report.Students {
+		for _, p := range st.Progress {
+			rows = append(rows, excel.ComplianceRow{
+				RegisterNumber: st.RegisterNumber,
+				StudentName:    st.StudentName,
+				Section:        st.Section,
+				Batch:          st.Batch,
+				FacultyEmail:   st.FacultyEmail,
+				Category:       p.Category,
+				Required:       p.Required,
+				Earned:         p.Earned,
+				Deficit:        p.Deficit,
+				Compliant:      st.Compliant,
+			})
+		}
+	}
+
+	description := fmt.Sprintf("Quota compliance for term %s: %d of %d students compliant",
+		report.Term.Name, report.Compliant, report.TotalStudents)
+	if report.Section != "" {
+		description += ", section " + report.Section
+	}
+	if report.Batch != "" {
+		description += ", batch " + report.Batch
+	}
+	if q.BehindOnly {
+		description += " (students behind only)"
+	}
+	filename := fmt.Sprintf("compliance_%s_%d.xlsx", sanitizeForFilename(report.Term.Name), time.Now().Unix())
+	bytes, err := excel.BuildComplianceWorkbook(rows, description)
+	return filename, bytes, err
+}
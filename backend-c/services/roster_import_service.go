@@ -0,0 +1,362 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"department-eduvault-backend/internal/excel"
+	"department-eduvault-backend/models"
+	"department-eduvault-backend/repositories"
+)
+
+var (
+	ErrRosterImportInvalid = errors.New("roster import has invalid rows; fix them and upload the file again")
+
+	sectionNamePattern = regexp.MustCompile(`^[A-Z][A-Z0-9-]{0,9}$`)
+)
+
+// rosterImportListLimit is how many past roster imports the history listing returns.
+const rosterImportListLimit = 50
+
+// RosterImportRowError is a validation failure for one roster spreadsheet row.
+type RosterImportRowError struct {
+	RowNumber int    `json:"row_number"`
+	Field     string `json:"field"`
+	Message   string `json:"message"`
+}
+
+// RosterFieldChange is one field of a roster student that an import would change.
+type RosterFieldChange struct {
+	Field string `json:"field"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+}
+
+// RosterStudentChange is a roster student whose details differ in the imported file.
+type RosterStudentChange struct {
+	RegisterNumber string              `json:"register_number"`
+	Changes        []RosterFieldChange `json:"changes"`
+	Before         models.Student      `json:"before"`
+	After          models.Student      `json:"after"`
+}
+
+// RosterDiff compares an imported roster with the active students: students only in the
+// file, students whose details differ, and active students missing from the file.
+type RosterDiff struct {
+	Added   []models.Student       `json:"added"`
+	Changed []RosterStudentChange  `json:"changed"`
+	Missing []models.Student       `json:"missing"`
+	Errors  []RosterImportRowError `json:"errors"`
+}
+
+// RosterImportDTO is a roster import with its diff and, once applied, the recorded changes.
+type RosterImportDTO struct {
+	models.RosterImport
+	Diff    RosterDiff            `json:"diff"`
+	History []models.RosterChange `json:"history,omitempty"`
+}
+
+// RosterImportService previews roster spreadsheets against the students table and applies
+// them on confirmation.
+type RosterImportService interface {
+	Preview(ctx context.Context, caller StudentCaller, filename string, data []byte) (RosterImportDTO, error)
+	Get(ctx context.Context, id string) (RosterImportDTO, error)
+	List(ctx context.Context) ([]models.RosterImport, error)
+	Confirm(ctx context.Context, caller StudentCaller, id string, removeMissing bool) (RosterImportDTO, error)
+}
+
+type rosterImportService struct {
+	imports  repositories.RosterImportRepository
+	students repositories.StudentRepository
+}
+
+// NewRosterImportService constructs a RosterImportService.
+func NewRosterImportService(imports repositories.RosterImportRepository, students repositories.StudentRepository) RosterImportService {
+	return &rosterImportService{imports: imports, students: students}
+}
+
+// Preview parses and validates a roster file, diffs it against the active students and
+// stores the result. Nothing on the roster changes until the import is confirmed.
+func (s *rosterImportService) Preview(ctx context.Context, caller StudentCaller, filename string, data []byte) (RosterImportDTO, error) {
+	rows, err := excel.ParseRosterImport(filename, data)
+	if err != nil {
+		return RosterImportDTO{}, err
+	}
+	if len(rows) == 0 {
+		return RosterImportDTO{}, ErrImportEmpty
+	}
+	if len(rows) > maxImportRows {
+		return RosterImportDTO{}, ErrImportTooLarge
+	}
+
+	// Read the version first: a write landing in between makes the preview stale rather than
+	// letting it be applied over a roster it was not computed against.
+	version, err := s.imports.RosterVersion(ctx)
+	if err != nil {
+		return RosterImportDTO{}, err
+	}
+	current, err := s.students.List(ctx, repositories.StudentListFilter{})
+	if err != nil {
+		return RosterImportDTO{}, err
+	}
+
+	diff := diffRoster(rows, current)
+	encoded, err := json.Marshal(diff)
+	if err != nil {
+		return RosterImportDTO{}, fmt.Errorf("encode roster diff: %w", err)
+	}
+	invalidRows := make(map[int]struct{}, len(diff.Errors))
+	for _, rowErr := range diff.Errors {
+		invalidRows[rowErr.RowNumber] = struct{}{}
+	}
+
+	rosterImport := models.RosterImport{
+		Status:        models.RosterImportPending,
+		FileName:      filename,
+		CreatedBy:     caller.Email,
+		RosterVersion: version,
+		TotalRows:     len(rows),
+		InvalidRows:   len(invalidRows),
+		Added:         len(diff.Added),
+		Changed:       len(diff.Changed),
+		Missing:       len(diff.Missing),
+		Diff:          encoded,
+		CreatedAt:     time.Now().UTC(),
+	}
+	if err := s.imports.Create(ctx, &rosterImport); err != nil {
+		return RosterImportDTO{}, err
+	}
+	return RosterImportDTO{RosterImport: rosterImport, Diff: diff}, nil
+}
+
+// Get returns a roster import with its diff, and the recorded changes once applied.
+func (s *rosterImportService) Get(ctx context.Context, id string) (RosterImportDTO, error) {
+	rosterImport, err := s.imports.GetByID(ctx, strings.TrimSpace(id))
+	if err != nil {
+		return RosterImportDTO{}, err
+	}
+	return s.importDTO(ctx, rosterImport)
+}
+
+// List returns the latest roster imports, newest first.
+func (s *rosterImportService) List(ctx context.Context) ([]models.RosterImport, error) {
+	return s.imports.List(ctx, rosterImportListLimit)
+}
+
+// Confirm applies a previewed import exactly as previewed. Missing students are soft-deleted
+// only when removeMissing is set. Imports with invalid rows cannot be confirmed, and a roster
+// changed since the preview fails with repositories.ErrRosterChanged.
+func (s *rosterImportService) Confirm(ctx context.Context, caller StudentCaller, id string, removeMissing bool) (RosterImportDTO, error) {
+	rosterImport, err := s.imports.GetByID(ctx, strings.TrimSpace(id))
+	if err != nil {
+		return RosterImportDTO{}, err
+	}
+	if rosterImport.Status != models.RosterImportPending {
+		return RosterImportDTO{}, repositories.ErrRosterImportApplied
+	}
+	var diff RosterDiff
+	if err := json.Unmarshal(rosterImport.Diff, &diff); err != nil {
+		return RosterImportDTO{}, fmt.Errorf("decode roster diff: %w", err)
+	}
+	if len(diff.Errors) > 0 {
+		return RosterImportDTO{}, ErrRosterImportInvalid
+	}
+
+	plan := repositories.RosterApplyPlan{
+		Add:           diff.Added,
+		Update:        make([]repositories.RosterUpdate, 0, len(diff.Changed)),
+		RemoveMissing: removeMissing,
+	}
+	for _, change := range diff.Changed {
+		plan.Update = append(plan.Update, repositories.RosterUpdate{Before: change.Before, After: change.After})
+	}
+	if removeMissing {
+		plan.Remove = diff.Missing
+	}
+
+	applied, err := s.imports.Apply(ctx, rosterImport.ID, caller.Email, plan)
+	if err != nil {
+		return RosterImportDTO{}, err
+	}
+	return s.importDTO(ctx, applied)
+}
+
+func (s *rosterImportService) importDTO(ctx context.Context, rosterImport *models.RosterImport) (RosterImportDTO, error) {
+	dto := RosterImportDTO{RosterImport: *rosterImport}
+	if err := json.Unmarshal(rosterImport.Diff, &dto.Diff); err != nil {
+		return RosterImportDTO{}, fmt.Errorf("decode roster diff: %w", err)
+	}
+	if rosterImport.Status == models.RosterImportApplied {
+		history, err := s.imports.ListChanges(ctx, rosterImport.ID)
+		if err != nil {
+			return RosterImportDTO{}, err
+		}
+		dto.History = history
+	}
+	return dto, nil
+}
+
+// diffRoster validates rows and compares them with the current roster. Blank optional cells
+// keep a student's current value; new students are marked present.
+func diffRoster(rows []excel.RosterImportRow, current []models.Student) RosterDiff {
+	diff := RosterDiff{
+		Added:   []models.Student{},
+		Changed: []RosterStudentChange{},
+		Missing: []models.Student{},
+		Errors:  []RosterImportRowError{},
+	}
+	byRegNo := make(map[string]models.Student, len(current))
+	for _, student := range current {
+		byRegNo[student.RegisterNumber] = student
+	}
+
+	seen := make(map[string]int, len(rows))
+	for _, row := range rows {
+		imported, rowErrors := rosterRowStudent(row)
+		if first, ok := seen[imported.RegisterNumber]; ok && imported.RegisterNumber != "" {
+			rowErrors = append(rowErrors, RosterImportRowError{
+				RowNumber: row.RowNumber,
+				Field:     "register_number",
+				Message:   fmt.Sprintf("duplicate of row %d", first),
+			})
+		} else {
+			seen[imported.RegisterNumber] = row.RowNumber
+		}
+		if len(rowErrors) > 0 {
+			diff.Errors = append(diff.Errors, rowErrors...)
+			continue
+		}
+
+		existing, ok := byRegNo[imported.RegisterNumber]
+		if !ok {
+			imported.IsPresent = true
+			diff.Added = append(diff.Added, imported)
+			continue
+		}
+		after := mergeRosterStudent(existing, imported)
+		if changes := rosterFieldChanges(existing, after); len(changes) > 0 {
+			diff.Changed = append(diff.Changed, RosterStudentChange{
+				RegisterNumber: existing.RegisterNumber,
+				Changes:        changes,
+				Before:         existing,
+				After:          after,
+			})
+		}
+	}
+
+	for _, student := range current {
+		if _, ok := seen[student.RegisterNumber]; !ok {
+			diff.Missing = append(diff.Missing, student)
+		}
+	}
+	return diff
+}
+
+// rosterRowStudent normalizes one row into a student, reporting every invalid field.
+func rosterRowStudent(row excel.RosterImportRow) (models.Student, []RosterImportRowError) {
+	var rowErrors []RosterImportRowError
+	fail := func(field, message string) {
+		rowErrors = append(rowErrors, RosterImportRowError{RowNumber: row.RowNumber, Field: field, Message: message})
+	}
+
+	student := models.Student{
+		RegisterNumber: strings.ToUpper(row.RegisterNumber),
+		Name:           row.StudentName,
+		Section:        strings.ToUpper(row.Section),
+		Batch:          row.Batch,
+		FacultyEmail:   row.FacultyEmail,
+	}
+	if !registerNumberPattern.MatchString(student.RegisterNumber) {
+		fail("register_number", "must be 4-20 letters or digits")
+	}
+	switch {
+	case student.Name == "":
+		fail("name", "is required")
+	case len([]rune(student.Name)) > maxStudentNameLength:
+		fail("name", fmt.Sprintf("must be at most %d characters", maxStudentNameLength))
+	}
+	switch {
+	case student.Section == "":
+		fail("section", "is required")
+	case !sectionNamePattern.MatchString(student.Section):
+		fail("section", "must be a letter followed by up to 9 letters, digits or hyphens")
+	}
+	if !isEmailAddress(student.FacultyEmail) {
+		fail("faculty_email", "must be an email address")
+	}
+	if row.Email != "" {
+		if isEmailAddress(row.Email) {
+			email := row.Email
+			student.Email = &email
+		} else {
+			fail("email", "must be an email address")
+		}
+	}
+	if row.Semester != "" {
+		semester, err := strconv.Atoi(row.Semester)
+		if err != nil || semester < 1 || semester > maxStudentSemester {
+			fail("semester", fmt.Sprintf("must be a number between 1 and %d", maxStudentSemester))
+		} else {
+			student.Semester = &semester
+		}
+	}
+	return student, rowErrors
+}
+
+// mergeRosterStudent applies an imported row to an existing student. Email, semester and
+// batch are only replaced when the file has a value; emails are compared case-insensitively.
+func mergeRosterStudent(existing, imported models.Student) models.Student {
+	after := existing
+	after.Name = imported.Name
+	after.Section = imported.Section
+	if !strings.EqualFold(existing.FacultyEmail, imported.FacultyEmail) {
+		after.FacultyEmail = imported.FacultyEmail
+	}
+	if imported.Email != nil && (existing.Email == nil || !strings.EqualFold(*existing.Email, *imported.Email)) {
+		after.Email = imported.Email
+	}
+	if imported.Semester != nil {
+		after.Semester = imported.Semester
+	}
+	if imported.Batch != "" {
+		after.Batch = imported.Batch
+	}
+	return after
+}
+
+// rosterFieldChanges lists the fields that differ between before and after.
+func rosterFieldChanges(before, after models.Student) []RosterFieldChange {
+	var changes []RosterFieldChange
+	compare := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, RosterFieldChange{Field: field, From: from, To: to})
+		}
+	}
+	compare("name", before.Name, after.Name)
+	compare("section", before.Section, after.Section)
+	compare("faculty_email", before.FacultyEmail, after.FacultyEmail)
+	compare("email", optionalString(before.Email), optionalString(after.Email))
+	compare("semester", optionalInt(before.Semester), optionalInt(after.Semester))
+	compare("batch", before.Batch, after.Batch)
+	return changes
+}
+
+func optionalString(value *string) string {
+	if value == nil {
+		return ""
+	}
+	return *value
+}
+
+func optionalInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.Itoa(*value)
+}
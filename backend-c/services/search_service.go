@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	"department-eduvault-backend/repositories"
+)
+
+var (
+	ErrSearchQueryTooShort = errors.New("search query must be at least 2 characters")
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+)
+
+// SearchCertificateDTO is a matched certificate with its relevance score.
+type SearchCertificateDTO struct {
+	ID            string    `json:"id"`
+	Title         string    `json:"title"`
+	Issuer        string    `json:"issuer"`
+	DriveLink     string    `json:"drive_link"`
+	DriveFileID   string    `json:"drive_file_id"`
+	MLStatus      string    `json:"ml_status"`
+	FacultyStatus string    `json:"faculty_status"`
+	UploadedAt    time.Time `json:"uploaded_at"`
+	Score         float64   `json:"score"`
+}
+
+// SearchStudentGroupDTO groups matched certificates under their student.
+type SearchStudentGroupDTO struct {
+	RegisterNumber string                 `json:"register_number"`
+	StudentName    string                 `json:"student_name"`
+	Section        string                 `json:"section"`
+	Score          float64                `json:"score"`
+	Certificates   []SearchCertificateDTO `json:"certificates"`
+}
+
+// SearchCaller identifies who is searching so results can be scoped.
+type SearchCaller struct {
+	Email string
+	Role  string
+}
+
+// SearchService runs ranked search across students and certificates.
+type SearchService interface {
+	Search(ctx context.Context, caller SearchCaller, q string, limit int) ([]SearchStudentGroupDTO, error)
+}
+
+type searchService struct {
+	repo repositories.SearchRepository
+}
+
+// NewSearchService constructs a SearchService.
+func NewSearchService(repo repositories.SearchRepository) SearchService {
+	return &searchService{repo: repo}
+}
+
+// Search returns up to limit student groups ordered by their best match. HODs see the whole
+// department; faculty see their own uploads and the students assigned to them.
+func (s *searchService) Search(ctx context.Context, caller SearchCaller, q string, limit int) ([]SearchStudentGroupDTO, error) {
+	q = strings.TrimSpace(q)
+	if len([]rune(q)) < 2 {
+		return nil, ErrSearchQueryTooShort
+	}
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	scope := repositories.SearchScope{}
+	if !strings.EqualFold(caller.Role, "hod") {
+		scope.FacultyEmail = caller.Email
+	}
+
+	students, err := s.repo.SearchStudents(ctx, q, scope, limit)
+	if err != nil {
+		return nil, err
+	}
+	// Several certificates usually belong to one student, so over-fetch before grouping.
+	certs, err := s.repo.SearchCertificates(ctx, q, scope, limit*5)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]*SearchStudentGroupDTO)
+	order := make([]string, 0)
+	group := func(regNo, name, section string) *SearchStudentGroupDTO {
+		g, ok := groups[regNo]
+		if !ok {
+			g = &SearchStudentGroupDTO{
+				RegisterNumber: regNo,
+				StudentName:    name,
+				Section:        section,
+				Certificates:   []SearchCertificateDTO{},
+			}
+			groups[regNo] = g
+			order = append(order, regNo)
+		}
+		return g
+	}
+
+	for _, st := range students {
+		g := group(st.RegisterNumber, st.StudentName, st.Section)
+		if st.Score > g.Score {
+			g.Score = st.Score
+		}
+	}
+	for _, c := range certs {
+		g := group(c.RegisterNumber, c.StudentName, c.Section)
+		if c.Score > g.Score {
+			g.Score = c.Score
+		}
+		g.Certificates = append(g.Certificates, SearchCertificateDTO{
+			ID:            c.ID,
+			Title:         c.Title,
+			Issuer:        c.Issuer,
+			DriveLink:     c.DriveLink,
+			DriveFileID:   c.DriveFileID,
+			MLStatus:      c.MLStatus,
+			FacultyStatus: c.FacultyStatus,
+			UploadedAt:    c.UploadedAt,
+			Score:         c.Score,
+		})
+	}
+
+	result := make([]SearchStudentGroupDTO, 0, len(order))
+	for _, regNo := range order {
+		result = append(result, *groups[regNo])
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].Score > result[j].Score
+	})
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	return result, nil
+}
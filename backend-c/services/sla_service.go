@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"department-eduvault-backend/models"
+	"department-eduvault-backend/repositories"
+
+	"go.uber.org/zap"
+)
+
+var (
+	ErrInvalidSLAThreshold = errors.New("threshold_hours must be a positive integer")
+)
+
+// ReviewAgeingDTO is one section of the review ageing report.
+type ReviewAgeingDTO struct {
+	Section            string  `json:"section"`
+	Pending            int64   `json:"pending_count"`
+	UnderOneDay        int64   `json:"under_1_day"`
+	OneToThreeDays     int64   `json:"between_1_and_3_days"`
+	ThreeToSevenDays   int64   `json:"between_3_and_7_days"`
+	OverSevenDays      int64   `json:"over_7_days"`
+	OverSLA            int64   `json:"over_sla_count"`
+	Escalated          int64   `json:"escalated_count"`
+	OldestWaitingHours float64 `json:"oldest_waiting_hours"`
+}
+
+// EscalatedCertificateDTO is an entry in the HOD escalation queue.
+type EscalatedCertificateDTO struct {
+	ID               string    `json:"id"`
+	RegisterNumber   string    `json:"register_number"`
+	StudentName      string    `json:"student_name"`
+	Section          string    `json:"section"`
+	Category         string    `json:"category"`
+	Title            string    `json:"title"`
+	DriveLink        string    `json:"drive_link"`
+	UploadedBy       string    `json:"uploaded_by"`
+	EscalatedAt      time.Time `json:"escalated_at"`
+	EscalationReason string    `json:"escalation_reason"`
+	WaitingHours     float64   `json:"waiting_hours"`
+	SLAHours         int       `json:"sla_hours"`
+}
+
+// SLAPolicyInput sets the threshold for a section and/or category; empty means "any".
+type SLAPolicyInput struct {
+	Section        string
+	Category       string
+	ThresholdHours int
+	UpdatedBy      string
+}
+
+// ReviewSLAService manages review SLAs, ageing reports and escalation to the HOD.
+type ReviewSLAService interface {
+	ListPolicies(ctx context.Context) ([]models.ReviewSLAPolicy, error)
+	SetPolicy(ctx context.Context, input SLAPolicyInput) (*models.ReviewSLAPolicy, error)
+	DeletePolicy(ctx context.Context, id int) error
+	GetAgeingReport(ctx context.Context) ([]ReviewAgeingDTO, error)
+	ListEscalated(ctx context.Context) ([]EscalatedCertificateDTO, error)
+	EscalateOverdue(ctx context.Context) (int64, error)
+	RunEscalationSweep(ctx context.Context, interval time.Duration, logger *zap.Logger)
+}
+
+type reviewSLAService struct {
+	repo         repositories.ReviewSLARepository
+	defaultHours int
+}
+
+// NewReviewSLAService constructs a ReviewSLAService. defaultHours applies to certificates no
+// stored policy covers.
+func NewReviewSLAService(repo repositories.ReviewSLARepository, defaultHours int) ReviewSLAService {
+	return &reviewSLAService{repo: repo, defaultHours: defaultHours}
+}
+
+func (s *reviewSLAService) ListPolicies(ctx context.Context) ([]models.ReviewSLAPolicy, error) {
+	return s.repo.ListPolicies(ctx)
+}
+
+// SetPolicy creates or replaces the SLA for the given scope.
+func (s *reviewSLAService) SetPolicy(ctx context.Context, input SLAPolicyInput) (*models.ReviewSLAPolicy, error) {
+	if input.ThresholdHours <= 0 {
+		return nil, ErrInvalidSLAThreshold
+	}
+	policy := &models.ReviewSLAPolicy{
+		ThresholdHours: input.ThresholdHours,
+		UpdatedBy:      input.UpdatedBy,
+	}
+	if section := strings.TrimSpace(input.Section); section != "" {
+		policy.Section = &section
+	}
+	if category := strings.TrimSpace(input.Category); category != "" {
+		normalized := normalizeCategory(category)
+		policy.Category = &normalized
+	}
+	if err := s.repo.UpsertPolicy(ctx, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+func (s *reviewSLAService) DeletePolicy(ctx context.Context, id int) error {
+	return s.repo.DeletePolicy(ctx, id)
+}
+
+func (s *reviewSLAService) GetAgeingReport(ctx context.Context) ([]ReviewAgeingDTO, error) {
+	rows, err := s.repo.GetAgeingReport(ctx, s.defaultHours)
+	if err != nil {
+		return nil, err
+	}
+	report := make([]ReviewAgeingDTO, 0, len(rows))
+	for _, r := range rows {
+		report = append(report, ReviewAgeingDTO{
+			Section:            r.Section,
+			Pending:            r.Pending,
+			UnderOneDay:        r.UnderOneDay,
+			OneToThreeDays:     r.OneToThreeDays,
+			ThreeToSevenDays:   r.ThreeToSevenDays,
+			OverSevenDays:      r.OverSevenDays,
+			OverSLA:            r.OverSLA,
+			Escalated:          r.Escalated,
+			OldestWaitingHours: r.OldestWaitingHours,
+		})
+	}
+	return report, nil
+}
+
+func (s *reviewSLAService) ListEscalated(ctx context.Context) ([]EscalatedCertificateDTO, error) {
+	rows, err := s.repo.ListEscalated(ctx, s.defaultHours)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]EscalatedCertificateDTO, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, EscalatedCertificateDTO{
+			ID:               r.ID,
+			RegisterNumber:   r.RegisterNumber,
+			StudentName:      r.StudentName,
+			Section:          r.Section,
+			Category:         r.Category,
+			Title:            r.Title,
+			DriveLink:        r.DriveLink,
+			UploadedBy:       r.UploadedBy,
+			EscalatedAt:      r.EscalatedAt,
+			EscalationReason: r.EscalationReason,
+			WaitingHours:     r.WaitingHours,
+			SLAHours:         r.SLAHours,
+		})
+	}
+	return result, nil
+}
+
+// EscalateOverdue escalates every certificate currently past its SLA.
+func (s *reviewSLAService) EscalateOverdue(ctx context.Context) (int64, error) {
+	return s.repo.EscalateOverdue(ctx, s.defaultHours)
+}
+
+// RunEscalationSweep escalates overdue certificates immediately and then on every interval
+// until ctx is cancelled. Failures are logged and retried on the next tick.
+func (s *reviewSLAService) RunEscalationSweep(ctx context.Context, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		escalated, err := s.EscalateOverdue(ctx)
+		if err != nil {
+			logger.Error("review sla sweep failed", zap.Error(err))
+		} else if escalated > 0 {
+			logger.Info("escalated overdue certificates to HOD", zap.Int64("count", escalated))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"department-eduvault-backend/repositories"
+
+	"go.uber.org/zap"
+)
+
+// statsFoldBatchSize bounds how many deltas one fold statement moves.
+const statsFoldBatchSize = 5000
+
+// StatsService keeps student_statistics and section_statistics consistent with certificates.
+type StatsService interface {
+	FoldDeltas(ctx context.Context) (int64, error)
+	Reconcile(ctx context.Context, dryRun bool) (repositories.StatsReconcileResult, error)
+	RunDeltaFold(ctx context.Context, interval time.Duration, logger *zap.Logger)
+	RunDriftCheck(ctx context.Context, interval time.Duration, logger *zap.Logger)
+}
+
+type statsService struct {
+	repo repositories.StatsRepository
+}
+
+// NewStatsService constructs a StatsService.
+func NewStatsService(repo repositories.StatsRepository) StatsService {
+	return &statsService{repo: repo}
+}
+
+// FoldDeltas folds every pending statistics delta, one batch at a time.
+func (s *statsService) FoldDeltas(ctx context.Context) (int64, error) {
+	var total int64
+	for {
+		folded, err := s.repo.FoldDeltas(ctx, statsFoldBatchSize)
+		total += folded
+		if err != nil || folded < statsFoldBatchSize {
+			return total, err
+		}
+	}
+}
+
+// Reconcile rebuilds both statistics tables from certificates, or only reports the
+// differences when dryRun is set.
+func (s *statsService) Reconcile(ctx context.Context, dryRun bool) (repositories.StatsReconcileResult, error) {
+	result, err := s.repo.Reconcile(ctx, dryRun)
+	if result.Drift == nil {
+		result.Drift = []repositories.StatsDrift{}
+	}
+	return result, err
+}
+
+// RunDeltaFold folds pending statistics deltas immediately and then on every interval until
+// ctx is cancelled. Failures are logged and retried on the next tick.
+func (s *statsService) RunDeltaFold(ctx context.Context, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if folded, err := s.FoldDeltas(ctx); err != nil {
+			logger.Error("statistics delta fold failed", zap.Error(err))
+		} else if folded > 0 {
+			logger.Debug("folded statistics deltas", zap.Int64("count", folded))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunDriftCheck compares the statistics tables with certificates immediately and then on every
+// interval until ctx is cancelled, logging each mismatched row. It never writes; drift is fixed
+// by running a reconciliation.
+func (s *statsService) RunDriftCheck(ctx context.Context, interval time.Duration, logger *zap.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		drift, err := s.repo.Diff(ctx)
+		if err != nil {
+			logger.Error("statistics drift check failed", zap.Error(err))
+		} else if len(drift) > 0 {
+			for _, d := range drift {
+				fields := []zap.Field{
+					zap.String("table", d.Table),
+					zap.String("key", d.Key),
+					zap.Any("actual", d.Actual),
+				}
+				if d.Stored == nil {
+					fields = append(fields, zap.Bool("missing_row", true))
+				} else {
+					fields = append(fields, zap.Any("stored", *d.Stored))
+				}
+				logger.Warn("statistics row drifted from certificates", fields...)
+			}
+			logger.Warn("statistics drift detected; run a reconciliation", zap.Int("rows", len(drift)))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,570 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+
+	"department-eduvault-backend/models"
+	"department-eduvault-backend/repositories"
+)
+
+var (
+	ErrStudentAccessDenied   = errors.New("only the HOD, the assigned faculty or the student may view this student")
+	ErrStudentManageDenied   = errors.New("only the HOD or the assigned faculty may change this student")
+	ErrSectionRosterNotFound = errors.New("no roster students found in this section")
+	ErrInvalidStudent        = errors.New("invalid student")
+
+	registerNumberPattern = regexp.MustCompile(`^[A-Z0-9]{4,20}$`)
+)
+
+const (
+	maxStudentNameLength = 200
+	maxStudentSemester   = 12
+)
+
+// recentActivityLimit is how many latest certificates a student summary lists.
+const recentActivityLimit = 5
+
+// StudentCaller identifies who is reading a student record.
+type StudentCaller struct {
+	Email string
+	Role  string
+}
+
+// StudentCertificateDTO is one certificate in a student summary.
+type StudentCertificateDTO struct {
+	ID            string     `json:"id"`
+	Title         string     `json:"title"`
+	Issuer        string     `json:"issuer"`
+	Category      string     `json:"category"`
+	DriveLink     string     `json:"drive_link"`
+	MLStatus      string     `json:"ml_status"`
+	FacultyStatus string     `json:"faculty_status"`
+	UploadedAt    time.Time  `json:"uploaded_at"`
+	ReviewedBy    *string    `json:"reviewed_by,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewed_at,omitempty"`
+}
+
+// StudentTotalsDTO counts a student's active certificates by pipeline state.
+type StudentTotalsDTO struct {
+	Total          int64 `json:"total"`
+	MLPending      int64 `json:"ml_pending"`
+	MLVerified     int64 `json:"ml_verified"`
+	MLDuplicate    int64 `json:"ml_duplicate"`
+	FacultyPending int64 `json:"faculty_pending"`
+	Legit          int64 `json:"legit"`
+	NotLegit       int64 `json:"not_legit"`
+}
+
+// StudentCategoryDTO counts a student's active certificates of one category.
+type StudentCategoryDTO struct {
+	Category string `json:"category"`
+	Total    int64  `json:"total"`
+	Legit    int64  `json:"legit"`
+	NotLegit int64  `json:"not_legit"`
+	Pending  int64  `json:"pending"`
+}
+
+// StudentTermDTO counts a student's active certificates uploaded within one academic term.
+type StudentTermDTO struct {
+	Term     string `json:"term"`
+	StartsOn string `json:"starts_on"`
+	EndsOn   string `json:"ends_on"`
+	Total    int64  `json:"total"`
+	Legit    int64  `json:"legit"`
+	NotLegit int64  `json:"not_legit"`
+}
+
+// StudentActivityDTO is the latest activity on a student's certificates.
+type StudentActivityDTO struct {
+	LastUploadedAt *time.Time              `json:"last_uploaded_at"`
+	LastReviewedAt *time.Time              `json:"last_reviewed_at"`
+	Recent         []StudentCertificateDTO `json:"recent"`
+}
+
+// SectionStandingDTO compares the student's LEGIT count with the section average over the
+// section roster. Rank is shared by ties and omitted when the student is not on the roster.
+type SectionStandingDTO struct {
+	Section           string  `json:"section"`
+	Students          int64   `json:"students"`
+	LegitCount        int64   `json:"legit_count"`
+	SectionAverage    float64 `json:"section_average"`
+	DifferenceFromAvg float64 `json:"difference_from_average"`
+	Rank              *int64  `json:"rank,omitempty"`
+}
+
+// StudentSummaryDTO is the response of GET /students/:reg_no/summary. Archived certificates
+// are not counted anywhere.
+type StudentSummaryDTO struct {
+	Profile               models.Student          `json:"profile"`
+	Totals                StudentTotalsDTO        `json:"totals"`
+	Categories            []StudentCategoryDTO    `json:"categories"`
+	Terms                 []StudentTermDTO        `json:"terms"`
+	LatestActivity        StudentActivityDTO      `json:"latest_activity"`
+	OutstandingRejections []StudentCertificateDTO `json:"outstanding_rejections"`
+	SectionStanding       SectionStandingDTO      `json:"section_standing"`
+}
+
+// RosterStudentDTO is one roster student with their certificate counts.
+type RosterStudentDTO struct {
+	RegisterNumber string     `json:"register_number"`
+	Name           string     `json:"name"`
+	Email          *string    `json:"email"`
+	Semester       *int       `json:"semester"`
+	IsPresent      bool       `json:"is_present"`
+	FacultyEmail   string     `json:"faculty_email"`
+	Submissions    int64      `json:"submissions"`
+	LegitCount     int64      `json:"legit_count"`
+	LastUploadedAt *time.Time `json:"last_uploaded_at"`
+}
+
+// SectionRosterDTO is the response of GET /sections/:section/roster. Participation counts
+// present students with at least one LEGIT certificate; NonSubmitters lists present students
+// without any certificate, the same students the dashboard's non_submitters counts.
+type SectionRosterDTO struct {
+	Section               string             `json:"section"`
+	TotalStudents         int                `json:"total_students"`
+	PresentStudents       int                `json:"present_students"`
+	AbsentStudents        int                `json:"absent_students"`
+	ParticipatingStudents int                `json:"participating_students"`
+	ParticipationRate     float64            `json:"participation_rate"`
+	Students              []RosterStudentDTO `json:"students"`
+	NonSubmitters         []RosterStudentDTO `json:"non_submitters"`
+}
+
+// StudentEnrollmentDTO is one period of a student's enrollment history. EndedAt is omitted
+// for the current enrollment; Term is the academic term the period started in.
+type StudentEnrollmentDTO struct {
+	Term      *string    `json:"term"`
+	Section   string     `json:"section"`
+	Semester  *int       `json:"semester"`
+	StartedAt time.Time  `json:"started_at"`
+	EndedAt   *time.Time `json:"ended_at,omitempty"`
+}
+
+// StudentInput adds a student to the roster. FacultyEmail defaults to the calling faculty and
+// IsPresent to true.
+type StudentInput struct {
+	RegisterNumber string
+	Name           string
+	Email          string
+	Section        string
+	Semester       *int
+	Batch          string
+	IsPresent      *bool
+	FacultyEmail   string
+}
+
+// StudentUpdate changes the non-nil fields of a roster student; an empty Email clears it.
+// Sections change through MoveSection.
+type StudentUpdate struct {
+	Name         *string
+	Email        *string
+	Semester     *int
+	Batch        *string
+	IsPresent    *bool
+	FacultyEmail *string
+}
+
+// StudentService manages the student roster and serves per-student views of it.
+type StudentService interface {
+	List(ctx context.Context, caller StudentCaller, filter repositories.StudentListFilter) ([]models.Student, error)
+	Create(ctx context.Context, caller StudentCaller, input StudentInput) (*models.Student, error)
+	Update(ctx context.Context, caller StudentCaller, regNo string, update StudentUpdate) (*models.Student, error)
+	Delete(ctx context.Context, caller StudentCaller, regNo string) error
+	MoveSection(ctx context.Context, caller StudentCaller, regNo, section string) (*models.Student, error)
+	GetSummary(ctx context.Context, caller StudentCaller, regNo string) (StudentSummaryDTO, error)
+	ListEnrollments(ctx context.Context, caller StudentCaller, regNo string) ([]StudentEnrollmentDTO, error)
+	GetSectionRoster(ctx context.Context, caller StudentCaller, section string) (SectionRosterDTO, error)
+}
+
+type studentService struct {
+	repo repositories.StudentRepository
+}
+
+// NewStudentService constructs a StudentService.
+func NewStudentService(repo repositories.StudentRepository) StudentService {
+	return &studentService{repo: repo}
+}
+
+// List returns active roster students. Faculty only see the students assigned to them.
+func (s *studentService) List(ctx context.Context, caller StudentCaller, filter repositories.StudentListFilter) ([]models.Student, error) {
+	switch strings.ToLower(caller.Role) {
+	case "hod":
+	case "faculty":
+		filter.FacultyEmail = caller.Email
+	default:
+		return nil, ErrStudentAccessDenied
+	}
+	filter.Section = strings.TrimSpace(filter.Section)
+	filter.Batch = strings.TrimSpace(filter.Batch)
+	filter.FacultyEmail = strings.TrimSpace(filter.FacultyEmail)
+	return s.repo.List(ctx, filter)
+}
+
+// Create validates input and adds the student. Faculty may only add students assigned to
+// themselves.
+func (s *studentService) Create(ctx context.Context, caller StudentCaller, input StudentInput) (*models.Student, error) {
+	role := strings.ToLower(caller.Role)
+	if role != "hod" && role != "faculty" {
+		return nil, ErrStudentManageDenied
+	}
+	facultyEmail := strings.TrimSpace(input.FacultyEmail)
+	if facultyEmail == "" && role == "faculty" {
+		facultyEmail = caller.Email
+	}
+
+	student := &models.Student{
+		RegisterNumber: strings.ToUpper(strings.TrimSpace(input.RegisterNumber)),
+		Name:           strings.TrimSpace(input.Name),
+		Section:        strings.TrimSpace(input.Section),
+		Semester:       input.Semester,
+		Batch:          strings.TrimSpace(input.Batch),
+		IsPresent:      input.IsPresent == nil || *input.IsPresent,
+		FacultyEmail:   facultyEmail,
+	}
+	if email := strings.TrimSpace(input.Email); email != "" {
+		student.Email = &email
+	}
+	if err := validateStudent(student); err != nil {
+		return nil, err
+	}
+	if !canManageStudent(caller, student) {
+		return nil, ErrStudentManageDenied
+	}
+	if err := s.repo.Create(ctx, student); err != nil {
+		return nil, err
+	}
+	return student, nil
+}
+
+// Update applies update to an active student. Only the HOD may reassign a student to
+// another faculty.
+func (s *studentService) Update(ctx context.Context, caller StudentCaller, regNo string, update StudentUpdate) (*models.Student, error) {
+	student, err := s.managedStudent(ctx, caller, regNo)
+	if err != nil {
+		return nil, err
+	}
+
+	if update.Name != nil {
+		student.Name = strings.TrimSpace(*update.Name)
+	}
+	if update.Email != nil {
+		student.Email = nil
+		if email := strings.TrimSpace(*update.Email); email != "" {
+			student.Email = &email
+		}
+	}
+	if update.Semester != nil {
+		student.Semester = update.Semester
+	}
+	if update.Batch != nil {
+		student.Batch = strings.TrimSpace(*update.Batch)
+	}
+	if update.IsPresent != nil {
+		student.IsPresent = *update.IsPresent
+	}
+	if update.FacultyEmail != nil {
+		student.FacultyEmail = strings.TrimSpace(*update.FacultyEmail)
+	}
+	if err := validateStudent(student); err != nil {
+		return nil, err
+	}
+	if !canManageStudent(caller, student) {
+		return nil, ErrStudentManageDenied
+	}
+	if err := s.repo.Update(ctx, student); err != nil {
+		return nil, err
+	}
+	return student, nil
+}
+
+// Delete soft-deletes a student; their certificates are kept.
+func (s *studentService) Delete(ctx context.Context, caller StudentCaller, regNo string) error {
+	student, err := s.managedStudent(ctx, caller, regNo)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(ctx, student.RegisterNumber)
+}
+
+// MoveSection reassigns a student to another section, starting a new enrollment. Certificates
+// stay attributed to the enrollment active when they were uploaded.
+func (s *studentService) MoveSection(ctx context.Context, caller StudentCaller, regNo, section string) (*models.Student, error) {
+	section = strings.TrimSpace(section)
+	if section == "" {
+		return nil, fmt.Errorf("%w: section is required", ErrInvalidStudent)
+	}
+	student, err := s.managedStudent(ctx, caller, regNo)
+	if err != nil {
+		return nil, err
+	}
+	if student.Section == section {
+		return student, nil
+	}
+	return s.repo.MoveSection(ctx, student.RegisterNumber, section)
+}
+
+// managedStudent loads an active student the caller may change.
+func (s *studentService) managedStudent(ctx context.Context, caller StudentCaller, regNo string) (*models.Student, error) {
+	student, err := s.repo.GetByRegisterNumber(ctx, strings.ToUpper(strings.TrimSpace(regNo)))
+	if err != nil {
+		return nil, err
+	}
+	if !canManageStudent(caller, student) {
+		return nil, ErrStudentManageDenied
+	}
+	return student, nil
+}
+
+// validateStudent checks a student about to be stored, wrapping ErrInvalidStudent with the
+// first problem found.
+func validateStudent(student *models.Student) error {
+	switch {
+	case !registerNumberPattern.MatchString(student.RegisterNumber):
+		return fmt.Errorf("%w: register_number must be 4-20 letters or digits", ErrInvalidStudent)
+	case student.Name == "":
+		return fmt.Errorf("%w: name is required", ErrInvalidStudent)
+	case len([]rune(student.Name)) > maxStudentNameLength:
+		return fmt.Errorf("%w: name must be at most %d characters", ErrInvalidStudent, maxStudentNameLength)
+	case student.Section == "":
+		return fmt.Errorf("%w: section is required", ErrInvalidStudent)
+	case !isEmailAddress(student.FacultyEmail):
+		return fmt.Errorf("%w: faculty_email must be an email address", ErrInvalidStudent)
+	case student.Email != nil && !isEmailAddress(*student.Email):
+		return fmt.Errorf("%w: email must be an email address", ErrInvalidStudent)
+	case student.Semester != nil && (*student.Semester < 1 || *student.Semester > maxStudentSemester):
+		return fmt.Errorf("%w: semester must be between 1 and %d", ErrInvalidStudent, maxStudentSemester)
+	}
+	return nil
+}
+
+func isEmailAddress(value string) bool {
+	addr, err := mail.ParseAddress(value)
+	return err == nil && addr.Address == value
+}
+
+// canManageStudent reports whether caller may change student: the HOD, or the faculty the
+// student is assigned to.
+func canManageStudent(caller StudentCaller, student *models.Student) bool {
+	switch strings.ToLower(caller.Role) {
+	case "hod":
+		return true
+	case "faculty":
+		return strings.EqualFold(caller.Email, student.FacultyEmail)
+	default:
+		return false
+	}
+}
+
+// ListEnrollments returns a student's section and semester history, latest first, to the
+// callers GetSummary allows.
+func (s *studentService) ListEnrollments(ctx context.Context, caller StudentCaller, regNo string) ([]StudentEnrollmentDTO, error) {
+	student, err := s.repo.GetByRegisterNumber(ctx, strings.ToUpper(strings.TrimSpace(regNo)))
+	if err != nil {
+		return nil, err
+	}
+	if !canViewStudent(caller, student) {
+		return nil, ErrStudentAccessDenied
+	}
+
+	rows, err := s.repo.ListEnrollments(ctx, student.RegisterNumber)
+	if err != nil {
+		return nil, err
+	}
+	enrollments := make([]StudentEnrollmentDTO, 0, len(rows))
+	for _, row := range rows {
+		enrollments = append(enrollments, StudentEnrollmentDTO{
+			Term:      row.Term,
+			Section:   row.Section,
+			Semester:  row.Semester,
+			StartedAt: row.StartedAt,
+			EndedAt:   row.EndedAt,
+		})
+	}
+	return enrollments, nil
+}
+
+// GetSummary returns one student's standing. HODs may read any student, faculty the students
+// assigned to them, and students themselves (matched by roster email). Unknown students fail
+// with repositories.ErrStudentNotFound, others with ErrStudentAccessDenied.
+func (s *studentService) GetSummary(ctx context.Context, caller StudentCaller, regNo string) (StudentSummaryDTO, error) {
+	student, err := s.repo.GetByRegisterNumber(ctx, strings.ToUpper(strings.TrimSpace(regNo)))
+	if err != nil {
+		return StudentSummaryDTO{}, err
+	}
+	if !canViewStudent(caller, student) {
+		return StudentSummaryDTO{}, ErrStudentAccessDenied
+	}
+
+	totals, err := s.repo.GetStatusTotals(ctx, student.RegisterNumber)
+	if err != nil {
+		return StudentSummaryDTO{}, err
+	}
+	categories, err := s.repo.GetCategoryTotals(ctx, student.RegisterNumber)
+	if err != nil {
+		return StudentSummaryDTO{}, err
+	}
+	terms, err := s.repo.GetTermTotals(ctx, student.RegisterNumber)
+	if err != nil {
+		return StudentSummaryDTO{}, err
+	}
+	recent, err := s.repo.ListCertificates(ctx, student.RegisterNumber, "", recentActivityLimit)
+	if err != nil {
+		return StudentSummaryDTO{}, err
+	}
+	rejected, err := s.repo.ListCertificates(ctx, student.RegisterNumber, models.FacultyStatusNotLegit, 0)
+	if err != nil {
+		return StudentSummaryDTO{}, err
+	}
+	standing, err := s.repo.GetSectionStanding(ctx, student.RegisterNumber, student.Section)
+	if err != nil {
+		return StudentSummaryDTO{}, err
+	}
+
+	summary := StudentSummaryDTO{
+		Profile: *student,
+		Totals: StudentTotalsDTO{
+			Total:          totals.Total,
+			MLPending:      totals.MLPending,
+			MLVerified:     totals.MLVerified,
+			MLDuplicate:    totals.MLDuplicate,
+			FacultyPending: totals.FacultyPending,
+			Legit:          totals.Legit,
+			NotLegit:       totals.NotLegit,
+		},
+		Categories: make([]StudentCategoryDTO, 0, len(categories)),
+		Terms:      make([]StudentTermDTO, 0, len(terms)),
+		LatestActivity: StudentActivityDTO{
+			LastUploadedAt: totals.LastUploadedAt,
+			LastReviewedAt: totals.LastReviewedAt,
+			Recent:         studentCertificates(recent),
+		},
+		OutstandingRejections: studentCertificates(rejected),
+		SectionStanding: SectionStandingDTO{
+			Section:           student.Section,
+			Students:          standing.Students,
+			LegitCount:        standing.LegitCount,
+			SectionAverage:    standing.Average,
+			DifferenceFromAvg: float64(standing.LegitCount) - standing.Average,
+			Rank:              standing.Rank,
+		},
+	}
+	for _, c := range categories {
+		summary.Categories = append(summary.Categories, StudentCategoryDTO{
+			Category: c.Category,
+			Total:    c.Total,
+			Legit:    c.Legit,
+			NotLegit: c.NotLegit,
+			Pending:  c.Pending,
+		})
+	}
+	for _, t := range terms {
+		summary.Terms = append(summary.Terms, StudentTermDTO{
+			Term:     t.Term,
+			StartsOn: t.StartsOn.Format("2006-01-02"),
+			EndsOn:   t.EndsOn.Format("2006-01-02"),
+			Total:    t.Total,
+			Legit:    t.Legit,
+			NotLegit: t.NotLegit,
+		})
+	}
+	return summary, nil
+}
+
+// GetSectionRoster returns a section's roster with participation figures. HODs see the whole
+// section and faculty only the students assigned to them; an empty result fails with
+// ErrSectionRosterNotFound.
+func (s *studentService) GetSectionRoster(ctx context.Context, caller StudentCaller, section string) (SectionRosterDTO, error) {
+	section = strings.TrimSpace(section)
+	facultyEmail := ""
+	switch strings.ToLower(caller.Role) {
+	case "hod":
+	case "faculty":
+		facultyEmail = caller.Email
+	default:
+		return SectionRosterDTO{}, ErrStudentAccessDenied
+	}
+
+	rows, err := s.repo.ListSectionRoster(ctx, section, facultyEmail)
+	if err != nil {
+		return SectionRosterDTO{}, err
+	}
+	if len(rows) == 0 {
+		return SectionRosterDTO{}, ErrSectionRosterNotFound
+	}
+
+	roster := SectionRosterDTO{
+		Section:       section,
+		TotalStudents: len(rows),
+		Students:      make([]RosterStudentDTO, 0, len(rows)),
+		NonSubmitters: make([]RosterStudentDTO, 0),
+	}
+	for _, r := range rows {
+		student := RosterStudentDTO{
+			RegisterNumber: r.RegisterNumber,
+			Name:           r.Name,
+			Email:          r.Email,
+			Semester:       r.Semester,
+			IsPresent:      r.IsPresent,
+			FacultyEmail:   r.FacultyEmail,
+			Submissions:    r.Submissions,
+			LegitCount:     r.LegitCount,
+			LastUploadedAt: r.LastUploadedAt,
+		}
+		roster.Students = append(roster.Students, student)
+		if !r.IsPresent {
+			roster.AbsentStudents++
+			continue
+		}
+		roster.PresentStudents++
+		if r.LegitCount > 0 {
+			roster.ParticipatingStudents++
+		}
+		if r.Submissions == 0 {
+			roster.NonSubmitters = append(roster.NonSubmitters, student)
+		}
+	}
+	if roster.PresentStudents > 0 {
+		roster.ParticipationRate = float64(roster.ParticipatingStudents) / float64(roster.PresentStudents)
+	}
+	return roster, nil
+}
+
+// canViewStudent reports whether caller may read student's records.
+func canViewStudent(caller StudentCaller, student *models.Student) bool {
+	switch strings.ToLower(caller.Role) {
+	case "hod":
+		return true
+	case "faculty":
+		return strings.EqualFold(caller.Email, student.FacultyEmail)
+	case "student":
+		return student.Email != nil && strings.EqualFold(caller.Email, *student.Email)
+	default:
+		return false
+	}
+}
+
+func studentCertificates(certs []models.Certificate) []StudentCertificateDTO {
+	result := make([]StudentCertificateDTO, 0, len(certs))
+	for _, c := range certs {
+		result = append(result, StudentCertificateDTO{
+			ID:            c.ID,
+			Title:         c.Title,
+			Issuer:        c.Issuer,
+			Category:      c.Category,
+			DriveLink:     c.DriveLink,
+			MLStatus:      string(c.MLStatus),
+			FacultyStatus: string(c.FacultyStatus),
+			UploadedAt:    c.UploadedAt,
+			ReviewedBy:    c.ReviewedBy,
+			ReviewedAt:    c.ReviewedAt,
+		})
+	}
+	return result
+}
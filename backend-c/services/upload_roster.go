@@ -0,0 +1,103 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"department-eduvault-backend/internal/fuzzy"
+	"department-eduvault-backend/models"
+)
+
+// SectionMismatchPolicy decides what happens to an uploaded certificate whose section differs
+// from the student's roster section.
+type SectionMismatchPolicy string
+
+const (
+	// SectionMismatchCorrect stores the certificate under the roster section with a warning.
+	SectionMismatchCorrect SectionMismatchPolicy = "correct"
+	// SectionMismatchReject refuses the certificate.
+	SectionMismatchReject SectionMismatchPolicy = "reject"
+)
+
+// rosterNameMatchThreshold is the fuzzy.Similarity below which an uploaded student name is
+// reported as not matching the roster name.
+const rosterNameMatchThreshold = 0.85
+
+// Upload item statuses.
+const (
+	UploadItemAccepted = "ACCEPTED"
+	UploadItemRejected = "REJECTED"
+)
+
+// Upload item issue codes.
+const (
+	UploadIssueUnknownStudent   = "UNKNOWN_STUDENT"
+	UploadIssueSectionMismatch  = "SECTION_MISMATCH"
+	UploadIssueSectionCorrected = "SECTION_CORRECTED"
+	UploadIssueNameMismatch     = "NAME_MISMATCH"
+)
+
+// UploadIssue is one roster validation finding for an uploaded certificate.
+type UploadIssue struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// UploadItemResult is the roster validation outcome of one uploaded certificate, in request
+// order. Accepted certificates carry their ID and the register number and section they were
+// stored under; Errors explain rejections and Warnings flag accepted items worth a look.
+type UploadItemResult struct {
+	Index          int           `json:"index"`
+	Status         string        `json:"status"`
+	CertificateID  string        `json:"certificate_id,omitempty"`
+	RegisterNumber string        `json:"register_number"`
+	Section        string        `json:"section"`
+	Errors         []UploadIssue `json:"errors,omitempty"`
+	Warnings       []UploadIssue `json:"warnings,omitempty"`
+}
+
+// checkAgainstRoster validates one upload against the roster student with its register number
+// (nil when there is none). An accepted item is stored under the roster's register number,
+// section and name; the returned student is nil for rejected items.
+func checkAgainstRoster(index int, in CertificateInput, student *models.Student, policy SectionMismatchPolicy) (UploadItemResult, *models.Student) {
+	item := UploadItemResult{
+		Index:          index,
+		Status:         UploadItemRejected,
+		RegisterNumber: strings.TrimSpace(in.RegisterNumber),
+		Section:        strings.TrimSpace(in.Section),
+	}
+	if student == nil {
+		item.Errors = append(item.Errors, UploadIssue{
+			Code:    UploadIssueUnknownStudent,
+			Message: fmt.Sprintf("register number %q is not on the student roster", item.RegisterNumber),
+		})
+		return item, nil
+	}
+	item.RegisterNumber = student.RegisterNumber
+
+	if !strings.EqualFold(item.Section, student.Section) {
+		if policy == SectionMismatchReject {
+			item.Errors = append(item.Errors, UploadIssue{
+				Code:    UploadIssueSectionMismatch,
+				Message: fmt.Sprintf("section %q does not match the roster section %q", item.Section, student.Section),
+			})
+			return item, nil
+		}
+		item.Warnings = append(item.Warnings, UploadIssue{
+			Code:    UploadIssueSectionCorrected,
+			Message: fmt.Sprintf("section %q was corrected to the roster section %q", item.Section, student.Section),
+		})
+	}
+	item.Section = student.Section
+
+	if score := fuzzy.Similarity(in.StudentName, student.Name); score < rosterNameMatchThreshold {
+		item.Warnings = append(item.Warnings, UploadIssue{
+			Code: UploadIssueNameMismatch,
+			Message: fmt.Sprintf("student name %q does not closely match the roster name %q (similarity %.2f)",
+				strings.TrimSpace(in.StudentName), student.Name, roundScore(score)),
+		})
+	}
+
+	item.Status = UploadItemAccepted
+	return item, student
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"image"
+	"math"
+	"time"
+
+	"department-eduvault-backend/internal/drive"
+	"department-eduvault-backend/internal/fuzzy"
+	"department-eduvault-backend/internal/imagehash"
+	"department-eduvault-backend/internal/pdf"
+	"department-eduvault-backend/models"
+	"department-eduvault-backend/repositories"
+)
+
+var (
+	ErrUnsupportedDocument      = errors.New("certificate file must be an unencrypted PDF")
+	ErrUnreadableDocument       = errors.New("certificate PDF is malformed and could not be read")
+	ErrDocumentUnavailable      = errors.New("certificate file could not be downloaded from its drive link")
+	ErrDocumentFetchFailed      = errors.New("drive download failed")
+	ErrNoCertificateImage       = errors.New("certificate file has no image to fingerprint; upload a PNG, JPEG, GIF, BMP, WebP or a PDF with an embedded image")
+	ErrCertificateImageTooLarge = errors.New("certificate image is larger than 8192x8192 pixels")
+)
+
+const (
+	// Weight of the name match when the certificate also declares an issuer.
+	textMatchNameWeight = 0.7
+	// nearDuplicateMaxDistance is the largest Hamming distance flagged as a near-duplicate. It
+	// must stay below imagehash.Bands for the band index to find every such pair.
+	nearDuplicateMaxDistance = imagehash.Bands - 1
+)
+
+// DocumentFetcher downloads the file behind a certificate's drive link.
+type DocumentFetcher interface {
+	Download(ctx context.Context, link string) ([]byte, error)
+}
+
+// VerificationService runs automated checks on certificate files and records the results as
+// verification signals for reviewers.
+type VerificationService interface {
+	VerifyText(ctx context.Context, certificateID, actor string, file []byte) (*models.CertificateVerificationSignal, error)
+	HashImage(ctx context.Context, certificateID, actor string, file []byte) (ImageHashResult, error)
+	ListNearDuplicates(ctx context.Context, limit int) ([]NearDuplicatePairDTO, error)
+}
+
+type verificationService struct {
+	certs   repositories.CertificateRepository
+	signals repositories.VerificationSignalRepository
+	hashes  repositories.ImageHashRepository
+	fetcher DocumentFetcher
+}
+
+// NewVerificationService constructs a VerificationService. fetcher is used when the caller
+// does not supply the certificate file itself.
+func NewVerificationService(certs repositories.CertificateRepository, signals repositories.VerificationSignalRepository, hashes repositories.ImageHashRepository, fetcher DocumentFetcher) VerificationService {
+	return &verificationService{certs: certs, signals: signals, hashes: hashes, fetcher: fetcher}
+}
+
+// NearDuplicateMatch is another student's certificate whose image is close to this one.
+type NearDuplicateMatch struct {
+	CertificateID  string `json:"certificate_id"`
+	RegisterNumber string `json:"register_number"`
+	Distance       int    `json:"distance"`
+}
+
+// ImageHashResult reports a certificate's perceptual hash and the near-duplicates it matched.
+type ImageHashResult struct {
+	CertificateID  string               `json:"certificate_id"`
+	Hash           string               `json:"hash"`
+	Source         string               `json:"source"`
+	NearDuplicates []NearDuplicateMatch `json:"near_duplicates"`
+}
+
+// NearDuplicateSideDTO is one certificate of a suspicious pair.
+type NearDuplicateSideDTO struct {
+	CertificateID  string    `json:"certificate_id"`
+	RegisterNumber string    `json:"register_number"`
+	StudentName    string    `json:"student_name"`
+	Section        string    `json:"section"`
+	Title          string    `json:"title"`
+	DriveLink      string    `json:"drive_link"`
+	UploadedBy     string    `json:"uploaded_by"`
+	UploadedAt     time.Time `json:"uploaded_at"`
+}
+
+// NearDuplicatePairDTO shows two certificates of different students side by side.
+type NearDuplicatePairDTO struct {
+	Distance   int                  `json:"distance"`
+	DetectedAt time.Time            `json:"detected_at"`
+	First      NearDuplicateSideDTO `json:"first"`
+	Second     NearDuplicateSideDTO `json:"second"`
+}
+
+// textFieldMatch is the evidence for one expected value found in the certificate text.
+type textFieldMatch struct {
+	Expected string  `json:"expected"`
+	Score    float64 `json:"score"`
+	Snippet  string  `json:"snippet"`
+}
+
+type textMatchDetails struct {
+	Source         string          `json:"source"`
+	ExtractedChars int             `json:"extracted_chars"`
+	StudentName    textFieldMatch  `json:"student_name"`
+	Issuer         *textFieldMatch `json:"issuer,omitempty"`
+	Note           string          `json:"note,omitempty"`
+}
+
+// VerifyText extracts the text of the certificate PDF (uploaded, or fetched from the drive
+// link when file is nil) and fuzzy-matches it against the student name and declared issuer.
+func (s *verificationService) VerifyText(ctx context.Context, certificateID, actor string, file []byte) (*models.CertificateVerificationSignal, error) {
+	cert, err := s.certs.GetByID(ctx, certificateID)
+	if err != nil {
+		return nil, err
+	}
+
+	source := "upload"
+	if file == nil {
+		source = "drive"
+		if file, err = s.fetchDocument(ctx, cert.DriveLink); err != nil {
+			return nil, err
+		}
+	}
+
+	text, err := pdf.ExtractText(file)
+	if err != nil {
+		return nil, documentError(err)
+	}
+
+	details := textMatchDetails{Source: source, ExtractedChars: len([]rune(text))}
+	var confidence float64
+	if fuzzy.Normalize(text) == "" {
+		details.StudentName = textFieldMatch{Expected: cert.StudentName}
+		details.Note = "no extractable text; the PDF is probably scanned"
+	} else {
+		name := fuzzy.BestMatch(text, cert.StudentName)
+		details.StudentName = textFieldMatch{Expected: cert.StudentName, Score: roundScore(name.Score), Snippet: name.Snippet}
+		confidence = name.Score
+
+		if cert.Issuer != "" {
+			issuer := fuzzy.BestMatch(text, cert.Issuer)
+			details.Issuer = &textFieldMatch{Expected: cert.Issuer, Score: roundScore(issuer.Score), Snippet: issuer.Snippet}
+			confidence = textMatchNameWeight*name.Score + (1-textMatchNameWeight)*issuer.Score
+		}
+	}
+
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
+	signal := &models.CertificateVerificationSignal{
+		CertificateID: cert.ID,
+		Kind:          models.VerificationSignalTextMatch,
+		Confidence:    roundScore(confidence),
+		Details:       raw,
+		CreatedBy:     actor,
+		UpdatedAt:     time.Now().UTC(),
+	}
+	if err := s.signals.Upsert(ctx, signal); err != nil {
+		return nil, err
+	}
+	return signal, nil
+}
+
+// HashImage computes the dHash of the certificate image (an uploaded file, or the drive file
+// when file is nil) and flags other students' certificates within nearDuplicateMaxDistance.
+// PDFs are fingerprinted by their largest embedded image.
+func (s *verificationService) HashImage(ctx context.Context, certificateID, actor string, file []byte) (ImageHashResult, error) {
+	cert, err := s.certs.GetByID(ctx, certificateID)
+	if err != nil {
+		return ImageHashResult{}, err
+	}
+	if file == nil {
+		if file, err = s.fetchDocument(ctx, cert.DriveLink); err != nil {
+			return ImageHashResult{}, err
+		}
+	}
+
+	img, source, err := decodeCertificateImage(file)
+	if err != nil {
+		return ImageHashResult{}, err
+	}
+	hash := imagehash.DHash(img)
+	split := imagehash.SplitBands(hash)
+	bands := make([]int16, len(split))
+	for i, b := range split {
+		bands[i] = int16(b)
+	}
+
+	candidates, err := s.hashes.FindCandidates(ctx, cert.ID, cert.RegisterNumber, bands)
+	if err != nil {
+		return ImageHashResult{}, err
+	}
+	now := time.Now().UTC()
+	result := ImageHashResult{
+		CertificateID:  cert.ID,
+		Hash:           fmt.Sprintf("%016x", hash),
+		Source:         source,
+		NearDuplicates: []NearDuplicateMatch{},
+	}
+	var pairs []models.NearDuplicatePair
+	for _, c := range candidates {
+		distance := imagehash.Distance(hash, uint64(c.Hash))
+		if distance > nearDuplicateMaxDistance {
+			continue
+		}
+		result.NearDuplicates = append(result.NearDuplicates, NearDuplicateMatch{
+			CertificateID:  c.CertificateID,
+			RegisterNumber: c.RegisterNumber,
+			Distance:       distance,
+		})
+		a, b := cert.ID, c.CertificateID
+		if b < a {
+			a, b = b, a
+		}
+		pairs = append(pairs, models.NearDuplicatePair{CertificateA: a, CertificateB: b, Distance: distance, DetectedAt: now})
+	}
+
+	record := &models.CertificateImageHash{
+		CertificateID: cert.ID,
+		Hash:          int64(hash),
+		Source:        source,
+		CreatedBy:     actor,
+		ComputedAt:    now,
+	}
+	if err := s.hashes.SaveHash(ctx, record, bands, pairs); err != nil {
+		return ImageHashResult{}, err
+	}
+	return result, nil
+}
+
+// ListNearDuplicates returns flagged pairs, closest first.
+func (s *verificationService) ListNearDuplicates(ctx context.Context, limit int) ([]NearDuplicatePairDTO, error) {
+	if limit <= 0 {
+		limit = 100
+	}
+	rows, err := s.hashes.ListNearDuplicates(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]NearDuplicatePairDTO, 0, len(rows))
+	for _, r := range rows {
+		result = append(result, NearDuplicatePairDTO{
+			Distance:   r.Distance,
+			DetectedAt: r.DetectedAt,
+			First:      nearDuplicateSide(r.First),
+			Second:     nearDuplicateSide(r.Second),
+		})
+	}
+	return result, nil
+}
+
+func nearDuplicateSide(c repositories.NearDuplicateCertificate) NearDuplicateSideDTO {
+	return NearDuplicateSideDTO{
+		CertificateID:  c.ID,
+		RegisterNumber: c.RegisterNumber,
+		StudentName:    c.StudentName,
+		Section:        c.Section,
+		Title:          c.Title,
+		DriveLink:      c.DriveLink,
+		UploadedBy:     c.UploadedBy,
+		UploadedAt:     c.UploadedAt,
+	}
+}
+
+// decodeCertificateImage decodes an image file, or the largest image embedded in a PDF.
+func decodeCertificateImage(file []byte) (image.Image, string, error) {
+	if pdf.IsPDF(file) {
+		img, ok, err := pdf.LargestImage(file)
+		if err != nil {
+			return nil, "", documentError(err)
+		}
+		if !ok {
+			return nil, "", ErrNoCertificateImage
+		}
+		return img, "pdf", nil
+	}
+	img, err := imagehash.Decode(file)
+	if err != nil {
+		if errors.Is(err, imagehash.ErrImageTooLarge) {
+			return nil, "", ErrCertificateImageTooLarge
+		}
+		return nil, "", ErrNoCertificateImage
+	}
+	return img, "image", nil
+}
+
+// documentError translates an internal/pdf parse failure into a service error.
+func documentError(err error) error {
+	if errors.Is(err, pdf.ErrNotPDF) || errors.Is(err, pdf.ErrEncrypted) {
+		return ErrUnsupportedDocument
+	}
+	return fmt.Errorf("%w: %v", ErrUnreadableDocument, err)
+}
+
+func (s *verificationService) fetchDocument(ctx context.Context, link string) ([]byte, error) {
+	data, err := s.fetcher.Download(ctx, link)
+	if err != nil {
+		if errors.Is(err, drive.ErrFileTooLarge) || errors.Is(err, drive.ErrInvalidLink) ||
+			errors.Is(err, drive.ErrNotAccessible) {
+			return nil, fmt.Errorf("%w: %v", ErrDocumentUnavailable, err)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrDocumentFetchFailed, err)
+	}
+	return data, nil
+}
+
+// roundScore keeps the three decimals stored in the confidence column.
+func roundScore(score float64) float64 {
+	return math.Round(score*1000) / 1000
+}
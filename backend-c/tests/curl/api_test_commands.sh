@@ -7,7 +7,7 @@ echo "Health check"
 curl -i "$BASE_URL/health"
 
 echo ""
-echo "Upload certificates"
+echo "Upload certificates (each item is checked against the student roster; see \"items\" in the response)"
 curl -i -X POST "$BASE_URL/certificates/upload" \
   -H "Authorization: Bearer $AUTH_TOKEN" \
   -H "Content-Type: application/json" \
@@ -28,6 +28,11 @@ echo "Pending review (limit 20)"
 curl -i "$BASE_URL/certificates/pending-review?limit=20" \
   -H "Authorization: Bearer $AUTH_TOKEN"
 
+echo ""
+echo "Pending review filtered by section and ML score band (newest first)"
+curl -i "$BASE_URL/certificates/pending-review?section=A&min_score=80&max_score=100&sort=newest&limit=20" \
+  -H "Authorization: Bearer $AUTH_TOKEN"
+
 echo ""
 echo "Submit faculty review"
 curl -i -X POST "$BASE_URL/certificates/review" \
@@ -39,3 +44,155 @@ curl -i -X POST "$BASE_URL/certificates/review" \
     "is_legit": true
   }'
 
+
+echo ""
+echo "Publish a new ML auto-decision policy version (HOD)"
+curl -i -X POST "$BASE_URL/hod/ml-policy" \
+  -H "Authorization: Bearer $AUTH_TOKEN" \
+  -H "Content-Type: application/json" \
+  -d '{
+    "auto_approve_threshold": 98,
+    "hod_review_threshold": 40,
+    "enabled": true
+  }'
+
+echo ""
+echo "Check the name and issuer on a certificate PDF (omit -F to fetch it from the drive link)"
+curl -i -X POST "$BASE_URL/certificates/<uuid>/text-verification" \
+  -H "Authorization: Bearer $AUTH_TOKEN" \
+  -F "file=@certificate.pdf"
+
+echo ""
+echo "Fingerprint a certificate image and flag near-duplicates across students"
+curl -i -X POST "$BASE_URL/certificates/<uuid>/image-hash" \
+  -H "Authorization: Bearer $AUTH_TOKEN" \
+  -F "file=@certificate.png"
+
+echo ""
+echo "Near-duplicate certificate pairs (HOD)"
+curl -i "$BASE_URL/hod/near-duplicates?limit=50" \
+  -H "Authorization: Bearer $AUTH_TOKEN"
+
+echo ""
+echo "Report statistics drift without writing (HOD)"
+curl -i -X POST "$BASE_URL/admin/stats/reconcile?dry_run=true" \
+  -H "Authorization: Bearer $AUTH_TOKEN"
+
+echo ""
+echo "Rebuild statistics tables from certificates (HOD)"
+curl -i -X POST "$BASE_URL/admin/stats/reconcile" \
+  -H "Authorization: Bearer $AUTH_TOKEN"
+
+echo ""
+echo "Weekly upload and decision trends per section"
+curl -i "$BASE_URL/dashboard/trends?bucket=week&from=2026-01-01&to=2026-03-31"
+
+echo ""
+echo "Faculty upload and review performance (HOD)"
+curl -i "$BASE_URL/hod/faculty-stats?from=2026-01-01&to=2026-03-31" \
+  -H "Authorization: Bearer $AUTH_TOKEN"
+
+echo ""
+echo "Download faculty performance as XLSX (HOD)"
+curl -o faculty_stats.xlsx "$BASE_URL/hod/export/faculty-stats?from=2026-01-01&to=2026-03-31" \
+  -H "Authorization: Bearer $AUTH_TOKEN"
+
+echo ""
+echo "Pipeline funnel for the department and each section"
+curl -i "$BASE_URL/dashboard/funnel"
+
+echo ""
+echo "Create an academic term (HOD)"
+curl -i -X POST "$BASE_URL/hod/academic-terms" \
+  -H "Authorization: Bearer $AUTH_TOKEN" \
+  -H "Content-Type: application/json" \
+  -d '{"name":"2025-26-EVEN","starts_on":"2026-01-01","ends_on":"2026-05-31"}'
+
+echo ""
+echo "Weight hackathon certificates on the points leaderboard (HOD)"
+curl -i -X PUT "$BASE_URL/hod/category-points" \
+  -H "Authorization: Bearer $AUTH_TOKEN" \
+  -H "Content-Type: application/json" \
+  -d '{"category":"HACKATHON","points":3}'
+
+echo ""
+echo "Top 10 students per section by points for a term"
+curl -i "$BASE_URL/dashboard/leaderboard?scope=section&metric=points&term=2025-26-EVEN&top=10" \
+  -H "Authorization: Bearer $AUTH_TOKEN"
+
+echo ""
+echo "Download the department leaderboard as XLSX"
+curl -o leaderboard.xlsx "$BASE_URL/dashboard/leaderboard/export?scope=department&metric=legit&top=50" \
+  -H "Authorization: Bearer $AUTH_TOKEN"
+
+echo ""
+echo "Dashboard overview revalidation (expect 304 with the ETag from a previous response)"
+ETAG=$(curl -s -D - -o /dev/null "$BASE_URL/dashboard/overview" | grep -i '^etag:' | cut -d' ' -f2 | tr -d '\r')
+curl -i "$BASE_URL/dashboard/overview" -H "If-None-Match: $ETAG"
+
+echo ""
+echo "Dashboard overview and sections sliced by term, category and department"
+curl -i "$BASE_URL/dashboard/overview?term=2025-26-EVEN&category=HACKATHON&department=CSE"
+curl -i "$BASE_URL/dashboard/sections?uploaded_from=2026-01-01&uploaded_to=2026-03-31&uploaded_by=faculty1@citchennai.net"
+
+echo ""
+echo "Student summary (HOD, assigned faculty or the student)"
+curl -i "$BASE_URL/students/RA2111003010001/summary" \
+  -H "Authorization: Bearer $AUTH_TOKEN"
+
+echo ""
+echo "Section roster with participation and students without submissions"
+curl -i "$BASE_URL/sections/A/roster" \
+  -H "Authorization: Bearer $AUTH_TOKEN"
+
+echo ""
+echo "Set a term quota of 3 LEGIT certificates for batch 2022"
+curl -i -X PUT "$BASE_URL/hod/quota-rules" \
+  -H "Authorization: Bearer $AUTH_TOKEN" \
+  -H "Content-Type: application/json" \
+  -d '{"term":"2025-26-EVEN","batch":"2022","min_legit":3}'
+
+echo ""
+echo "Students behind their quotas in section A, and the XLSX export"
+curl -i "$BASE_URL/hod/compliance?term=2025-26-EVEN&section=A&behind=true" \
+  -H "Authorization: Bearer $AUTH_TOKEN"
+curl -o compliance.xlsx "$BASE_URL/hod/export/compliance?term=2025-26-EVEN" \
+  -H "Authorization: Bearer $AUTH_TOKEN"
+
+echo ""
+echo "Student roster: list, create, update, move section and soft-delete"
+curl -i "$BASE_URL/students?section=A&present=true" \
+  -H "Authorization: Bearer $AUTH_TOKEN"
+curl -i -X POST "$BASE_URL/students" \
+  -H "Authorization: Bearer $AUTH_TOKEN" \
+  -H "Content-Type: application/json" \
+  -d '{"register_number":"22CSE101","name":"Arun","section":"A","batch":"2022","faculty_email":"faculty1.cse@citchennai.net"}'
+curl -i -X PATCH "$BASE_URL/students/22CSE101" \
+  -H "Authorization: Bearer $AUTH_TOKEN" \
+  -H "Content-Type: application/json" \
+  -d '{"semester":5,"email":"arun.22cse101@citchennai.net"}'
+curl -i -X POST "$BASE_URL/students/22CSE101/move-section" \
+  -H "Authorization: Bearer $AUTH_TOKEN" \
+  -H "Content-Type: application/json" \
+  -d '{"section":"B"}'
+curl -i -X DELETE "$BASE_URL/students/22CSE101" \
+  -H "Authorization: Bearer $AUTH_TOKEN"
+
+echo ""
+echo "Roster import: preview the diff, review it, then confirm (remove_missing soft-deletes students absent from the file)"
+curl -i -X POST "$BASE_URL/students/imports" \
+  -H "Authorization: Bearer $AUTH_TOKEN" \
+  -F "file=@roster.xlsx"
+curl -i "$BASE_URL/students/imports/<uuid>" \
+  -H "Authorization: Bearer $AUTH_TOKEN"
+curl -i -X POST "$BASE_URL/students/imports/<uuid>/confirm" \
+  -H "Authorization: Bearer $AUTH_TOKEN" \
+  -H "Content-Type: application/json" \
+  -d '{"remove_missing":false}'
+curl -i "$BASE_URL/students/imports" \
+  -H "Authorization: Bearer $AUTH_TOKEN"
+
+echo ""
+echo "Student section and semester history"
+curl -i "$BASE_URL/students/22CSE101/enrollments" \
+  -H "Authorization: Bearer $AUTH_TOKEN"
@@ -6,11 +6,14 @@ import (
 )
 
 // AppError represents a typed application error with an associated HTTP status code.
+// Items optionally carries per-item details rendered next to the error, such as why each
+// entry of a batch was rejected.
 type AppError struct {
 	Code    string
 	Message string
 	Status  int
 	Err     error
+	Items   interface{}
 }
 
 func (e *AppError) Error() string {
@@ -24,6 +27,12 @@ func (e *AppError) Unwrap() error {
 	return e.Err
 }
 
+// WithItems attaches per-item details to the error response.
+func (e *AppError) WithItems(items interface{}) *AppError {
+	e.Items = items
+	return e
+}
+
 // Factory helpers ------------------------------------------------------------
 
 func NewValidationError(message string, err error) *AppError {
@@ -42,6 +51,18 @@ func NewNotFoundError(message string, err error) *AppError {
 	return &AppError{Code: "NOT_FOUND", Message: message, Status: http.StatusNotFound, Err: err}
 }
 
+func NewConflictError(message string, err error) *AppError {
+	return &AppError{Code: "CONFLICT", Message: message, Status: http.StatusConflict, Err: err}
+}
+
+func NewUnprocessableError(message string, err error) *AppError {
+	return &AppError{Code: "UNPROCESSABLE_ENTITY", Message: message, Status: http.StatusUnprocessableEntity, Err: err}
+}
+
+func NewUpstreamError(message string, err error) *AppError {
+	return &AppError{Code: "UPSTREAM_ERROR", Message: message, Status: http.StatusBadGateway, Err: err}
+}
+
 func NewDatabaseError(message string, err error) *AppError {
 	return &AppError{Code: "DATABASE_ERROR", Message: message, Status: http.StatusInternalServerError, Err: err}
 }